@@ -4,6 +4,7 @@ import * as ecsp from "aws-cdk-lib/aws-ecs-patterns";
 import * as rds from "aws-cdk-lib/aws-rds";
 import * as ec2 from "aws-cdk-lib/aws-ec2";
 import * as elasticache from "aws-cdk-lib/aws-elasticache";
+import * as secretsmanager from "aws-cdk-lib/aws-secretsmanager";
 import { Construct } from "constructs";
 import { Config } from "../config/default";
 
@@ -83,6 +84,25 @@ export class InfraStack extends cdk.Stack {
       cacheSubnetGroupName: redisSubnetGroup.ref,
     });
 
+    // A JSON object of key IDs to keys. Rotated by adding a key to the secret and
+    // switching TICKET_SIGNING_KEY_ID to it.
+    const ticketSigningKeys = new secretsmanager.Secret(this, "TicketSigningKeys", {
+      generateSecretString: {
+        secretStringTemplate: JSON.stringify({}),
+        generateStringKey: "k1",
+        passwordLength: 64,
+        excludePunctuation: true,
+      },
+    });
+
+    // Sent as X-API-Key to create organizers and rotate their API keys.
+    const adminApiKey = new secretsmanager.Secret(this, "AdminApiKey", {
+      generateSecretString: {
+        passwordLength: 64,
+        excludePunctuation: true,
+      },
+    });
+
     const fargateService = new ecsp.ApplicationLoadBalancedFargateService(
       this,
       "GoWitCaseServer",
@@ -93,13 +113,20 @@ export class InfraStack extends cdk.Stack {
           environment: {
             DB_HOST: postgres.dbInstanceEndpointAddress,
             DB_USER: "postgres",
-            DB_PASSWORD: postgres
-              .secret!.secretValueFromJson("password")
-              .unsafeUnwrap(),
             DB_NAME: "gowit",
             DB_PORT: postgres.dbInstanceEndpointPort,
             REDIS_ADDR: redis.attrRedisEndpointAddress + ":6379",
             REDIS_PASS: "",
+            TICKET_SIGNING_KEY_ID: "k1",
+          },
+          // Read by ECS when the task starts, so they stay out of the template.
+          secrets: {
+            DB_PASSWORD: ecs.Secret.fromSecretsManager(
+              postgres.secret!,
+              "password"
+            ),
+            TICKET_SIGNING_KEYS: ecs.Secret.fromSecretsManager(ticketSigningKeys),
+            ADMIN_API_KEY: ecs.Secret.fromSecretsManager(adminApiKey),
           },
         },
         publicLoadBalancer: true,
@@ -1,16 +1,27 @@
 package main
 
 import (
+	"fmt"
 	"gowitcase/db"
+	customErrors "gowitcase/errors"
+	"gowitcase/graph"
 	"gowitcase/handlers"
+	"gowitcase/middleware"
+	"gowitcase/problem"
+	"gowitcase/rpc"
 	"gowitcase/services"
+	"gowitcase/ticketpb"
 	"log"
+	"net"
+	"net/http"
 	"os"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
+	"google.golang.org/grpc"
 )
 
 func main() {
@@ -28,10 +39,83 @@ func main() {
 	db.InitDB()
 	db.InitRedis()
 
+	signingKeys, err := services.ParseSigningKeys(os.Getenv("TICKET_SIGNING_KEYS"))
+	if err != nil {
+		log.Fatalf("Failed to load ticket signing keys: %v", err)
+	}
+	ticketSigner, err := services.NewTicketSigner(signingKeys, os.Getenv("TICKET_SIGNING_KEY_ID"))
+	if err != nil {
+		log.Fatalf("Failed to load ticket signing keys: %v", err)
+	}
+
 	ticketService := services.NewTicketService(&db.DB, &db.Redis)
+	purchaseService := services.NewPurchaseService(&db.DB, &db.Redis)
+	idempotencyService := services.NewIdempotencyService(&db.DB, &db.Redis)
+	holdService := services.NewHoldService(&db.DB, &db.Redis)
+	eventService := services.NewEventService(&db.DB, &db.Redis)
+	promoCodeService := services.NewPromoCodeService(&db.DB, &db.Redis)
+	waitlistService := services.NewWaitlistService(&db.DB, &db.Redis)
+	seatService := services.NewSeatService(&db.DB, &db.Redis)
+	ticketInstanceService := services.NewTicketInstanceService(&db.DB, &db.Redis, ticketSigner)
+	capacityPoolService := services.NewCapacityPoolService(&db.DB, &db.Redis)
+	orderService := services.NewOrderService(&db.DB, &db.Redis)
+	checkInService := services.NewCheckInService(&db.DB, &db.Redis, ticketSigner)
+	organizerService := services.NewOrganizerService(&db.DB, &db.Redis)
+	pricingRuleService := services.NewPricingRuleService(&db.DB, &db.Redis)
+	availabilityService := services.NewAvailabilityService(&db.DB, &db.Redis)
+
 	ticketHandler := handlers.NewTicketHandler(ticketService)
+	purchaseHandler := handlers.NewPurchaseHandler(purchaseService)
+	holdHandler := handlers.NewHoldHandler(holdService)
+	eventHandler := handlers.NewEventHandler(eventService)
+	promoCodeHandler := handlers.NewPromoCodeHandler(promoCodeService)
+	waitlistHandler := handlers.NewWaitlistHandler(waitlistService)
+	seatHandler := handlers.NewSeatHandler(seatService)
+	ticketInstanceHandler := handlers.NewTicketInstanceHandler(ticketInstanceService)
+	capacityPoolHandler := handlers.NewCapacityPoolHandler(capacityPoolService)
+	orderHandler := handlers.NewOrderHandler(orderService)
+	checkInHandler := handlers.NewCheckInHandler(checkInService)
+	organizerHandler := handlers.NewOrganizerHandler(organizerService)
+	pricingRuleHandler := handlers.NewPricingRuleHandler(pricingRuleService)
+	availabilityHandler := handlers.NewAvailabilityHandler(ticketService, availabilityService)
 
-	router := gin.Default()
+	schema, err := graph.NewSchema(ticketService)
+	if err != nil {
+		log.Fatalf("Failed to parse GraphQL schema: %v", err)
+	}
+	graphQLHandler := handlers.NewGraphQLHandler(schema, ticketService)
+
+	go holdService.StartHoldReaper(30 * time.Second)
+	go idempotencyService.StartKeyReaper(time.Hour)
+	go availabilityService.StartListener(5 * time.Second)
+
+	// The gRPC API serves the ticket service to internal services on its own port.
+	grpcServer := grpc.NewServer(grpc.UnaryInterceptor(rpc.OrganizerInterceptor(organizerService)))
+	ticketpb.RegisterTicketServiceServer(grpcServer, rpc.NewTicketServer(ticketService))
+
+	grpcPort := os.Getenv("GRPC_PORT")
+	if grpcPort == "" {
+		grpcPort = "9090"
+	}
+	listener, err := net.Listen("tcp", ":"+grpcPort)
+	if err != nil {
+		log.Fatalf("Failed to listen on gRPC port %s: %v", grpcPort, err)
+	}
+	go func() {
+		if err := grpcServer.Serve(listener); err != nil {
+			log.Fatalf("Failed to serve gRPC: %v", err)
+		}
+	}()
+
+	// Every response carries a request ID, and every error, even a panic or an
+	// unknown route, is described as problem details with it.
+	router := gin.New()
+	router.Use(gin.Logger(), middleware.RequestIDMiddleware(), gin.CustomRecovery(func(c *gin.Context, recovered interface{}) {
+		problem.Respond(c, fmt.Errorf("panic: %v", recovered))
+	}))
+	router.NoRoute(func(c *gin.Context) {
+		problem.Respond(c, customErrors.NewRestError("Route not found", http.StatusNotFound))
+	})
 
 	router.GET("/health", func(c *gin.Context) {
 		if db.DB.IsHealthy() && db.Redis.IsHealthy() {
@@ -41,13 +125,65 @@ func main() {
 		c.JSON(500, gin.H{"status": "down"})
 	})
 
+	// Every resource is scoped to the organizer of the API key, organizers are managed
+	// with the admin key.
+	organizerAuth := middleware.OrganizerMiddleware(organizerService)
+	adminAuth := middleware.AdminMiddleware(os.Getenv("ADMIN_API_KEY"))
+
 	v1 := router.Group("/api/v1")
 	{
-		v1.POST("/tickets", ticketHandler.CreateTicket)
-		v1.GET("/tickets/:id", ticketHandler.GetTicket)
-		v1.POST("/tickets/:id/purchases", ticketHandler.PurchaseTicket)
+		v1.POST("/organizers", adminAuth, organizerHandler.CreateOrganizer)
+		v1.POST("/organizers/:id/api-key", adminAuth, organizerHandler.RotateAPIKey)
+		v1.GET("/events", organizerAuth, eventHandler.ListEvents)
+		v1.POST("/events", organizerAuth, eventHandler.CreateEvent)
+		v1.GET("/events/:id", organizerAuth, eventHandler.GetEvent)
+		v1.PUT("/events/:id", organizerAuth, eventHandler.ReplaceEvent)
+		v1.PATCH("/events/:id", organizerAuth, eventHandler.PatchEvent)
+		v1.DELETE("/events/:id", organizerAuth, eventHandler.ArchiveEvent)
+		v1.GET("/tickets", organizerAuth, ticketHandler.ListTickets)
+		v1.POST("/tickets", organizerAuth, ticketHandler.CreateTicket)
+		v1.GET("/tickets/:id", organizerAuth, ticketHandler.GetTicket)
+		v1.PUT("/tickets/:id", organizerAuth, ticketHandler.ReplaceTicket)
+		v1.PATCH("/tickets/:id", organizerAuth, ticketHandler.PatchTicket)
+		v1.DELETE("/tickets/:id", organizerAuth, ticketHandler.ArchiveTicket)
+		v1.GET("/tickets/:id/seats", organizerAuth, seatHandler.GetSeatMap)
+		v1.PUT("/tickets/:id/seats", organizerAuth, seatHandler.PutSeatMap)
+		v1.GET("/tickets/:id/pricing-rules", organizerAuth, pricingRuleHandler.GetPricingRules)
+		v1.PUT("/tickets/:id/pricing-rules", organizerAuth, pricingRuleHandler.PutPricingRules)
+		v1.GET("/tickets/:id/quote", organizerAuth, pricingRuleHandler.GetQuote)
+		v1.GET("/tickets/:id/availability/stream", organizerAuth, availabilityHandler.StreamAvailability)
+		v1.POST("/tickets/:id/purchases", organizerAuth, middleware.IdempotencyMiddleware(idempotencyService), ticketHandler.PurchaseTicket)
+		v1.POST("/orders", organizerAuth, middleware.IdempotencyMiddleware(idempotencyService), orderHandler.Checkout)
+		v1.GET("/orders/:id", organizerAuth, orderHandler.GetOrder)
+		v1.GET("/tickets/:id/purchases", organizerAuth, purchaseHandler.ListTicketPurchases)
+		v1.GET("/purchases/:id", organizerAuth, purchaseHandler.GetPurchase)
+		v1.POST("/purchases/:id/refunds", organizerAuth, middleware.IdempotencyMiddleware(idempotencyService), purchaseHandler.RefundPurchase)
+		v1.GET("/purchases/:id/refunds", organizerAuth, purchaseHandler.ListRefunds)
+		v1.GET("/purchases/:id/ticket-instances", organizerAuth, ticketInstanceHandler.ListPurchaseInstances)
+		v1.GET("/ticket-instances/:id", organizerAuth, ticketInstanceHandler.GetTicketInstance)
+		v1.GET("/ticket-instances/:id/qr.png", organizerAuth, ticketInstanceHandler.GetTicketInstanceQRCode)
+		v1.POST("/checkins", organizerAuth, checkInHandler.CheckIn)
+		v1.GET("/tickets/:id/checkins", organizerAuth, checkInHandler.GetCheckInCount)
+		v1.POST("/tickets/:id/holds", organizerAuth, middleware.IdempotencyMiddleware(idempotencyService), holdHandler.CreateHold)
+		v1.GET("/holds/:id", organizerAuth, holdHandler.GetHold)
+		v1.POST("/holds/:id/confirm", organizerAuth, holdHandler.ConfirmHold)
+		v1.DELETE("/holds/:id", organizerAuth, holdHandler.ReleaseHold)
+		v1.POST("/tickets/:id/waitlist", organizerAuth, middleware.IdempotencyMiddleware(idempotencyService), waitlistHandler.JoinWaitlist)
+		v1.GET("/waitlist/:id", organizerAuth, waitlistHandler.GetWaitlistEntry)
+		v1.DELETE("/waitlist/:id", organizerAuth, waitlistHandler.LeaveWaitlist)
+		v1.POST("/capacity-pools", organizerAuth, capacityPoolHandler.CreateCapacityPool)
+		v1.GET("/capacity-pools/:id", organizerAuth, capacityPoolHandler.GetCapacityPool)
+		v1.PATCH("/capacity-pools/:id", organizerAuth, capacityPoolHandler.PatchCapacityPool)
+		v1.GET("/promo-codes", organizerAuth, promoCodeHandler.ListPromoCodes)
+		v1.POST("/promo-codes", organizerAuth, promoCodeHandler.CreatePromoCode)
+		v1.GET("/promo-codes/:id", organizerAuth, promoCodeHandler.GetPromoCode)
+		v1.PATCH("/promo-codes/:id", organizerAuth, promoCodeHandler.PatchPromoCode)
+		v1.DELETE("/promo-codes/:id", organizerAuth, promoCodeHandler.ArchivePromoCode)
 	}
 
+	// GraphQL
+	router.POST("/graphql", organizerAuth, graphQLHandler.Query)
+
 	// Swagger
 	router.GET("/swagger.json", func(c *gin.Context) {
 		c.File("docs/swagger.json")
@@ -0,0 +1,3 @@
+CREATE INDEX ticket_created_at_id_idx ON ticket (created_at, id);
+CREATE INDEX ticket_allocation_id_idx ON ticket (allocation, id);
+CREATE INDEX ticket_name_id_idx ON ticket (name, id);
\ No newline at end of file
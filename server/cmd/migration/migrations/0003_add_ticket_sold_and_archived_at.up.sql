@@ -0,0 +1,3 @@
+ALTER TABLE ticket
+    ADD COLUMN sold INT NOT NULL DEFAULT 0,
+    ADD COLUMN archived_at TIMESTAMP;
\ No newline at end of file
@@ -0,0 +1,9 @@
+CREATE TABLE purchase (
+    id SERIAL,
+    ticket_id INT NOT NULL REFERENCES ticket (id),
+    quantity INT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX purchase_ticket_id_id_idx ON purchase (ticket_id, id);
\ No newline at end of file
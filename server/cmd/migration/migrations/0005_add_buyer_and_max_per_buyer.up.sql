@@ -0,0 +1,5 @@
+ALTER TABLE ticket ADD COLUMN max_per_buyer INT NOT NULL DEFAULT 0;
+
+ALTER TABLE purchase ADD COLUMN buyer_id VARCHAR(255) NOT NULL DEFAULT '';
+
+CREATE INDEX purchase_ticket_id_buyer_id_idx ON purchase (ticket_id, buyer_id);
\ No newline at end of file
@@ -0,0 +1,14 @@
+-- Keys are stored with the organizer they belong to as prefix. The token tells the
+-- request holding the reservation apart from a retry that took over an expired one.
+CREATE TABLE idempotency_key (
+    key VARCHAR(300) NOT NULL,
+    fingerprint CHAR(64) NOT NULL,
+    token VARCHAR(64) NOT NULL,
+    status_code INT,
+    response_body TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (key)
+);
+
+CREATE INDEX idempotency_key_expires_at_idx ON idempotency_key (expires_at);
\ No newline at end of file
@@ -0,0 +1,17 @@
+ALTER TABLE ticket ADD COLUMN held INT NOT NULL DEFAULT 0;
+
+CREATE TABLE ticket_hold (
+    id SERIAL,
+    ticket_id INT NOT NULL REFERENCES ticket (id),
+    buyer_id VARCHAR(255) NOT NULL,
+    quantity INT NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'active',
+    expires_at TIMESTAMP NOT NULL,
+    purchase_id INT REFERENCES purchase (id),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX ticket_hold_ticket_id_buyer_id_idx ON ticket_hold (ticket_id, buyer_id);
+CREATE INDEX ticket_hold_active_expires_at_idx ON ticket_hold (expires_at) WHERE status = 'active';
\ No newline at end of file
@@ -0,0 +1,13 @@
+ALTER TABLE purchase ADD COLUMN refunded_quantity INT NOT NULL DEFAULT 0;
+
+CREATE TABLE purchase_refund (
+    id SERIAL,
+    purchase_id INT NOT NULL REFERENCES purchase (id),
+    quantity INT NOT NULL,
+    reason TEXT NOT NULL,
+    refunded_by VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX purchase_refund_purchase_id_idx ON purchase_refund (purchase_id);
\ No newline at end of file
@@ -0,0 +1,3 @@
+ALTER TABLE ticket
+    ADD COLUMN sale_starts_at TIMESTAMP,
+    ADD COLUMN sale_ends_at TIMESTAMP;
\ No newline at end of file
@@ -0,0 +1,16 @@
+ALTER TABLE ticket
+    ADD COLUMN price BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'EUR';
+
+ALTER TABLE purchase
+    ADD COLUMN unit_price BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN total BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'EUR';
+
+ALTER TABLE ticket_hold
+    ADD COLUMN unit_price BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'EUR';
+
+ALTER TABLE purchase_refund
+    ADD COLUMN amount BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'EUR';
\ No newline at end of file
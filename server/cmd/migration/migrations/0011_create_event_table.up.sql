@@ -0,0 +1,16 @@
+CREATE TABLE event (
+    id SERIAL,
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    venue VARCHAR(255) NOT NULL DEFAULT '',
+    starts_at TIMESTAMP,
+    ends_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    archived_at TIMESTAMP,
+    PRIMARY KEY (id)
+);
+
+ALTER TABLE ticket ADD COLUMN event_id INT REFERENCES event (id);
+
+CREATE INDEX ticket_event_id_idx ON ticket (event_id);
\ No newline at end of file
@@ -0,0 +1,22 @@
+CREATE TABLE promo_code (
+    id SERIAL,
+    code VARCHAR(64) NOT NULL,
+    type VARCHAR(16) NOT NULL,
+    discount_bps INT NOT NULL DEFAULT 0,
+    amount_off BIGINT NOT NULL DEFAULT 0,
+    currency CHAR(3) NOT NULL DEFAULT 'EUR',
+    ticket_id INT REFERENCES ticket (id),
+    max_uses INT NOT NULL DEFAULT 0,
+    used_count INT NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    archived_at TIMESTAMP,
+    PRIMARY KEY (id)
+);
+
+CREATE UNIQUE INDEX promo_code_code_idx ON promo_code (code);
+
+ALTER TABLE purchase
+    ADD COLUMN promo_code_id INT REFERENCES promo_code (id),
+    ADD COLUMN discount BIGINT NOT NULL DEFAULT 0;
\ No newline at end of file
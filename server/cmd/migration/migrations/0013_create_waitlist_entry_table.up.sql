@@ -0,0 +1,14 @@
+CREATE TABLE waitlist_entry (
+    id SERIAL,
+    ticket_id INT NOT NULL REFERENCES ticket (id),
+    buyer_id VARCHAR(255) NOT NULL,
+    quantity INT NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'waiting',
+    hold_id INT REFERENCES ticket_hold (id),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX waitlist_entry_ticket_id_waiting_idx ON waitlist_entry (ticket_id, id) WHERE status = 'waiting';
+CREATE UNIQUE INDEX waitlist_entry_ticket_id_buyer_id_waiting_idx ON waitlist_entry (ticket_id, buyer_id) WHERE status = 'waiting';
\ No newline at end of file
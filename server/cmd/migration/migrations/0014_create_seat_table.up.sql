@@ -0,0 +1,24 @@
+ALTER TABLE ticket
+    ADD COLUMN seated BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE seat (
+    id SERIAL,
+    ticket_id INT NOT NULL REFERENCES ticket (id),
+    section VARCHAR(255) NOT NULL,
+    row_label VARCHAR(255) NOT NULL,
+    number VARCHAR(255) NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'available',
+    hold_id INT REFERENCES ticket_hold (id),
+    purchase_id INT REFERENCES purchase (id),
+    PRIMARY KEY (id)
+);
+
+CREATE UNIQUE INDEX seat_ticket_id_section_row_label_number_idx ON seat (ticket_id, section, row_label, number);
+CREATE INDEX seat_hold_id_idx ON seat (hold_id) WHERE hold_id IS NOT NULL;
+CREATE INDEX seat_purchase_id_idx ON seat (purchase_id) WHERE purchase_id IS NOT NULL;
+
+ALTER TABLE ticket_hold
+    ADD COLUMN seat_ids INT[];
+
+ALTER TABLE purchase
+    ADD COLUMN seat_ids INT[];
\ No newline at end of file
@@ -0,0 +1,27 @@
+CREATE TABLE ticket_instance (
+    id SERIAL,
+    ticket_id INT NOT NULL REFERENCES ticket (id),
+    purchase_id INT NOT NULL REFERENCES purchase (id),
+    seat_id INT REFERENCES seat (id),
+    code VARCHAR(64) NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'valid',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    voided_at TIMESTAMP,
+    PRIMARY KEY (id)
+);
+
+CREATE UNIQUE INDEX ticket_instance_code_idx ON ticket_instance (code);
+CREATE INDEX ticket_instance_purchase_id_idx ON ticket_instance (purchase_id, id);
+
+-- Issue instances for the tickets purchased before instances existed, one per seat
+-- for seated tickets.
+INSERT INTO ticket_instance (ticket_id, purchase_id, seat_id, code)
+SELECT seat.ticket_id, seat.purchase_id, seat.id, md5(random()::text || clock_timestamp()::text || seat.id)
+FROM seat
+WHERE seat.status = 'sold';
+
+INSERT INTO ticket_instance (ticket_id, purchase_id, code)
+SELECT purchase.ticket_id, purchase.id, md5(random()::text || clock_timestamp()::text || purchase.id || '-' || n)
+FROM purchase
+JOIN ticket ON ticket.id = purchase.ticket_id AND NOT ticket.seated
+CROSS JOIN LATERAL generate_series(1, purchase.quantity - purchase.refunded_quantity) AS n;
\ No newline at end of file
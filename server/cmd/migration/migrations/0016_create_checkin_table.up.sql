@@ -0,0 +1,12 @@
+CREATE TABLE checkin (
+    id SERIAL,
+    ticket_instance_id INT NOT NULL REFERENCES ticket_instance (id),
+    ticket_id INT NOT NULL REFERENCES ticket (id),
+    gate VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id)
+);
+
+-- A ticket instance admits once, so a second scan is caught even if two gates race.
+CREATE UNIQUE INDEX checkin_ticket_instance_id_idx ON checkin (ticket_instance_id);
+CREATE INDEX checkin_ticket_id_idx ON checkin (ticket_id);
\ No newline at end of file
@@ -0,0 +1,10 @@
+CREATE TABLE purchase_order (
+    id SERIAL,
+    buyer_id VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id)
+);
+
+ALTER TABLE purchase ADD COLUMN order_id INT REFERENCES purchase_order (id);
+
+CREATE INDEX purchase_order_id_idx ON purchase (order_id);
\ No newline at end of file
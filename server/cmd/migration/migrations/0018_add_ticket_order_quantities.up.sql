@@ -0,0 +1,3 @@
+ALTER TABLE ticket ADD COLUMN min_per_order INT NOT NULL DEFAULT 1;
+ALTER TABLE ticket ADD COLUMN max_per_order INT NOT NULL DEFAULT 0;
+ALTER TABLE ticket ADD COLUMN quantity_step INT NOT NULL DEFAULT 1;
\ No newline at end of file
@@ -0,0 +1,14 @@
+CREATE TABLE capacity_pool (
+    id SERIAL,
+    name VARCHAR(255) NOT NULL,
+    capacity INT NOT NULL,
+    used INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    CHECK (used >= 0 AND used <= capacity)
+);
+
+ALTER TABLE ticket ADD COLUMN capacity_pool_id INT REFERENCES capacity_pool (id);
+
+CREATE INDEX ticket_capacity_pool_id_idx ON ticket (capacity_pool_id);
\ No newline at end of file
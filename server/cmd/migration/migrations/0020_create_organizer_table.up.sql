@@ -0,0 +1,19 @@
+CREATE TABLE organizer (
+    id SERIAL,
+    name VARCHAR(255) NOT NULL,
+    api_key_hash VARCHAR(64),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id)
+);
+
+CREATE UNIQUE INDEX organizer_api_key_hash_idx ON organizer (api_key_hash);
+
+-- Existing tickets go to a default organizer, which gets an API key by rotating it.
+INSERT INTO organizer (name) VALUES ('Default organizer');
+
+ALTER TABLE ticket ADD COLUMN organizer_id INT REFERENCES organizer (id);
+UPDATE ticket SET organizer_id = (SELECT MIN(id) FROM organizer);
+ALTER TABLE ticket ALTER COLUMN organizer_id SET NOT NULL;
+
+CREATE INDEX ticket_organizer_id_idx ON ticket (organizer_id, id);
\ No newline at end of file
@@ -0,0 +1,9 @@
+CREATE TABLE pricing_rule (
+    id SERIAL,
+    ticket_id INT NOT NULL REFERENCES ticket (id),
+    sold_bps INT NOT NULL CHECK (sold_bps > 0 AND sold_bps <= 10000),
+    change_bps INT NOT NULL CHECK (change_bps > -10000),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    UNIQUE (ticket_id, sold_bps)
+);
\ No newline at end of file
@@ -0,0 +1,40 @@
+-- Events, capacity pools, promo codes and orders belong to the organizer of their
+-- tickets, or to the default organizer when they have none.
+ALTER TABLE event ADD COLUMN organizer_id INT REFERENCES organizer (id);
+UPDATE event SET organizer_id = COALESCE(
+    (SELECT MIN(organizer_id) FROM ticket WHERE ticket.event_id = event.id),
+    (SELECT MIN(id) FROM organizer)
+);
+ALTER TABLE event ALTER COLUMN organizer_id SET NOT NULL;
+
+CREATE INDEX event_organizer_id_idx ON event (organizer_id, id);
+
+ALTER TABLE capacity_pool ADD COLUMN organizer_id INT REFERENCES organizer (id);
+UPDATE capacity_pool SET organizer_id = COALESCE(
+    (SELECT MIN(organizer_id) FROM ticket WHERE ticket.capacity_pool_id = capacity_pool.id),
+    (SELECT MIN(id) FROM organizer)
+);
+ALTER TABLE capacity_pool ALTER COLUMN organizer_id SET NOT NULL;
+
+CREATE INDEX capacity_pool_organizer_id_idx ON capacity_pool (organizer_id, id);
+
+ALTER TABLE promo_code ADD COLUMN organizer_id INT REFERENCES organizer (id);
+UPDATE promo_code SET organizer_id = COALESCE(
+    (SELECT organizer_id FROM ticket WHERE ticket.id = promo_code.ticket_id),
+    (SELECT MIN(id) FROM organizer)
+);
+ALTER TABLE promo_code ALTER COLUMN organizer_id SET NOT NULL;
+
+-- Codes only have to be unique per organizer.
+DROP INDEX promo_code_code_idx;
+CREATE UNIQUE INDEX promo_code_code_idx ON promo_code (organizer_id, code);
+
+ALTER TABLE purchase_order ADD COLUMN organizer_id INT REFERENCES organizer (id);
+UPDATE purchase_order SET organizer_id = COALESCE(
+    (SELECT MIN(ticket.organizer_id) FROM purchase JOIN ticket ON ticket.id = purchase.ticket_id
+        WHERE purchase.order_id = purchase_order.id),
+    (SELECT MIN(id) FROM organizer)
+);
+ALTER TABLE purchase_order ALTER COLUMN organizer_id SET NOT NULL;
+
+CREATE INDEX purchase_order_organizer_id_idx ON purchase_order (organizer_id, id);
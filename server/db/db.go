@@ -12,6 +12,7 @@ import (
 
 type DatabaseInterface interface {
 	QueryRow(query string, args ...interface{}) *sql.Row
+	Query(query string, args ...interface{}) (*sql.Rows, error)
 	Exec(query string, args ...interface{}) (sql.Result, error)
 	BeginTransaction() (*sql.Tx, error)
 	Ping() error
@@ -84,6 +85,10 @@ func (d *Database) QueryRow(query string, args ...interface{}) *sql.Row {
 	return d.client.QueryRow(query, args...)
 }
 
+func (d *Database) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return d.client.Query(query, args...)
+}
+
 func (d *Database) Exec(query string, args ...interface{}) (sql.Result, error) {
 	return d.client.Exec(query, args...)
 }
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"sync"
 	"time"
 
 	"github.com/go-redis/redis"
@@ -17,6 +18,14 @@ type RedisInterface interface {
 	Set(key string, value interface{}, ttl time.Duration) error
 	Get(key string) (string, error)
 	Del(key string) error
+	Publish(channel string, message string) error
+	Subscribe(channel string) (Subscription, error)
+}
+
+// Subscription receives the messages published to a channel until it is closed.
+type Subscription interface {
+	Messages() <-chan string
+	Close() error
 }
 
 var Redis RedisClient
@@ -95,3 +104,47 @@ func (r *RedisClient) Get(key string) (string, error) {
 func (r *RedisClient) Del(key string) error {
 	return r.client.Del(key).Err()
 }
+
+func (r *RedisClient) Publish(channel string, message string) error {
+	return r.client.Publish(channel, message).Err()
+}
+
+// Subscribe returns once Redis has confirmed the subscription, so no message
+// published afterwards is missed.
+func (r *RedisClient) Subscribe(channel string) (Subscription, error) {
+	pubSub := r.client.Subscribe(channel)
+	if _, err := pubSub.Receive(); err != nil {
+		pubSub.Close()
+		return nil, err
+	}
+
+	subscription := &redisSubscription{pubSub: pubSub, messages: make(chan string), done: make(chan struct{})}
+	go func() {
+		defer close(subscription.messages)
+		for message := range pubSub.Channel() {
+			select {
+			case subscription.messages <- message.Payload:
+			case <-subscription.done:
+				return
+			}
+		}
+	}()
+
+	return subscription, nil
+}
+
+type redisSubscription struct {
+	pubSub    *redis.PubSub
+	messages  chan string
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func (s *redisSubscription) Messages() <-chan string {
+	return s.messages
+}
+
+func (s *redisSubscription) Close() error {
+	s.closeOnce.Do(func() { close(s.done) })
+	return s.pubSub.Close()
+}
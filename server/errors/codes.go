@@ -0,0 +1,63 @@
+package errors
+
+// Generic codes, used when no more specific code applies.
+const (
+	CodeInvalidRequest   = "invalid_request"
+	CodeValidationFailed = "validation_failed"
+	CodeUnauthorized     = "unauthorized"
+	CodeForbidden        = "forbidden"
+	CodeNotFound         = "not_found"
+	CodeConflict         = "conflict"
+	CodeUnprocessable    = "unprocessable"
+	CodeInternal         = "internal_error"
+)
+
+// Codes of the rules a sale can fail on.
+const (
+	CodeSoldOut              = "sold_out"
+	CodeNotEnoughTickets     = "not_enough_tickets"
+	CodeCapacityReached      = "capacity_reached"
+	CodeSaleNotStarted       = "sale_not_started"
+	CodeSaleEnded            = "sale_ended"
+	CodeInvalidQuantity      = "invalid_quantity"
+	CodeBuyerLimitExceeded   = "buyer_limit_exceeded"
+	CodeTicketArchived       = "ticket_archived"
+	CodeEventArchived        = "event_archived"
+	CodeSeatUnavailable      = "seat_unavailable"
+	CodeSeatMapInUse         = "seat_map_in_use"
+	CodePromoCodeInvalid     = "promo_code_invalid"
+	CodePromoCodeExpired     = "promo_code_expired"
+	CodePromoCodeExhausted   = "promo_code_exhausted"
+	CodePromoCodeNotEligible = "promo_code_not_eligible"
+	CodeHoldExpired          = "hold_expired"
+	CodeHoldClosed           = "hold_closed"
+	CodeAlreadyCheckedIn     = "already_checked_in"
+	CodeTicketNotValid       = "ticket_not_valid"
+)
+
+// Codes of the Idempotency-Key checks.
+const (
+	CodeIdempotencyKeyReused     = "idempotency_key_reused"
+	CodeIdempotencyKeyInProgress = "idempotency_key_in_progress"
+)
+
+var statusCodes = map[int]string{
+	400: CodeInvalidRequest,
+	401: CodeUnauthorized,
+	403: CodeForbidden,
+	404: CodeNotFound,
+	409: CodeConflict,
+	422: CodeUnprocessable,
+	500: CodeInternal,
+}
+
+// StatusCode is the generic code of an HTTP status.
+func StatusCode(status int) string {
+	if code, ok := statusCodes[status]; ok {
+		return code
+	}
+	if status >= 500 {
+		return CodeInternal
+	}
+	return CodeInvalidRequest
+}
@@ -4,18 +4,56 @@ type RestErrorInterface interface {
 	Error() string
 }
 
+// RestError is an error reported to the client. Code identifies the kind of error
+// and stays stable, so clients can act on it while Message is free to change.
+// Details hold values that help handling it, such as the tickets still available,
+// and Fields the invalid fields of the request.
 type RestError struct {
 	Message string
 	Status  int
+	Code    string
+	Details map[string]interface{}
+	Fields  []FieldError
+}
+
+// FieldError is a failed check of one request field. Field is the JSON path of
+// the field, such as 'price.amount' or 'rules[0].sold_bps'.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
 }
 
 func (e RestError) Error() string {
 	return e.Message
 }
 
+// NewRestError creates an error with the generic code of its status.
 func NewRestError(message string, status int) RestError {
 	return RestError{
 		Message: message,
 		Status:  status,
+		Code:    StatusCode(status),
+	}
+}
+
+// NewFieldError creates the 400 error of an invalid request field.
+func NewFieldError(field string, message string) RestError {
+	return NewFieldErrors([]FieldError{{Field: field, Message: message}})
+}
+
+// WithCode returns the error with a more specific code than the one of its status.
+func (e RestError) WithCode(code string) RestError {
+	e.Code = code
+	return e
+}
+
+// WithDetail returns the error with the detail key set to value.
+func (e RestError) WithDetail(key string, value interface{}) RestError {
+	details := make(map[string]interface{}, len(e.Details)+1)
+	for k, v := range e.Details {
+		details[k] = v
 	}
+	details[key] = value
+	e.Details = details
+	return e
 }
@@ -0,0 +1,47 @@
+package errors
+
+import "fmt"
+
+// FieldErrors collects the failed checks of a request, so every invalid field is
+// reported at once instead of the first one only.
+type FieldErrors []FieldError
+
+// Add records that field failed a check.
+func (f *FieldErrors) Add(field string, message string) {
+	*f = append(*f, FieldError{Field: field, Message: message})
+}
+
+// Has reports whether field already failed a check. Checks that need a valid
+// field, such as dividing by it, are skipped when it failed.
+func (f FieldErrors) Has(field string) bool {
+	for _, fieldErr := range f {
+		if fieldErr.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+// Err returns the error of the collected checks, or nil when none failed.
+func (f FieldErrors) Err() error {
+	if len(f) == 0 {
+		return nil
+	}
+	return NewFieldErrors(f)
+}
+
+// NewFieldErrors creates the 400 error of the invalid fields of a request. A
+// single field keeps its own message, so it reads the same as NewFieldError.
+func NewFieldErrors(fields []FieldError) RestError {
+	message := fields[0].Message
+	if len(fields) > 1 {
+		message = fmt.Sprintf("Request has %d invalid fields", len(fields))
+	}
+
+	return RestError{
+		Message: message,
+		Status:  400,
+		Code:    CodeValidationFailed,
+		Fields:  fields,
+	}
+}
@@ -0,0 +1,46 @@
+package errors
+
+// LineError is the error of one line of a request made of lines, such as the
+// lines of an order. Line is the index of the line in the request, and Fields the
+// invalid fields of the line keyed by their path in the request.
+type LineError struct {
+	Line     int          `json:"line"`
+	TicketID int          `json:"ticket_id,omitempty"`
+	Code     string       `json:"code"`
+	Message  string       `json:"message"`
+	Fields   []FieldError `json:"errors,omitempty"`
+	Status   int          `json:"-"`
+}
+
+// LineErrors is a RestError that reports every failed line of a request, so the
+// client can fix them all at once.
+type LineErrors struct {
+	RestError
+	Lines []LineError
+}
+
+// NewLineErrors fails with the status and code shared by every line, or with 400
+// and the generic code when the lines failed for different reasons.
+func NewLineErrors(message string, lines []LineError) LineErrors {
+	status := 400
+	code := ""
+	for i, line := range lines {
+		if i == 0 {
+			status = line.Status
+			code = line.Code
+		} else if line.Status != status {
+			status = 400
+			code = ""
+			break
+		} else if line.Code != code {
+			code = ""
+		}
+	}
+
+	restErr := NewRestError(message, status)
+	if code != "" {
+		restErr.Code = code
+	}
+
+	return LineErrors{RestError: restErr, Lines: lines}
+}
@@ -0,0 +1,103 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	customErrors "gowitcase/errors"
+	"gowitcase/models"
+	"gowitcase/services"
+	"strconv"
+
+	"github.com/graph-gophers/dataloader"
+)
+
+type requestScopeKey struct{}
+
+// requestScope is the state of a single GraphQL request. Its loader batches the
+// tickets requested while resolving one level of the query into one GetTickets
+// call, and remembers them until the request ends.
+type requestScope struct {
+	organizerID int
+	tickets     *dataloader.Loader
+}
+
+// WithRequest returns the context to execute a GraphQL request of the organizer with.
+func WithRequest(ctx context.Context, ticketService *services.TicketService, organizerID int) context.Context {
+	scope := &requestScope{organizerID: organizerID}
+	scope.tickets = dataloader.NewBatchedLoader(
+		func(ctx context.Context, keys dataloader.Keys) []*dataloader.Result {
+			return batchTickets(ticketService, organizerID, keys)
+		},
+		dataloader.WithBatchCapacity(models.TicketListMaxLimit),
+	)
+	return context.WithValue(ctx, requestScopeKey{}, scope)
+}
+
+func requestScopeFrom(ctx context.Context) *requestScope {
+	return ctx.Value(requestScopeKey{}).(*requestScope)
+}
+
+func batchTickets(ticketService *services.TicketService, organizerID int, keys dataloader.Keys) []*dataloader.Result {
+	results := make([]*dataloader.Result, len(keys))
+
+	ticketIDs := make([]int, len(keys))
+	for i, key := range keys {
+		ticketIDs[i], _ = strconv.Atoi(key.String())
+	}
+
+	tickets, err := ticketService.GetTickets(organizerID, ticketIDs)
+	for i, ticketID := range ticketIDs {
+		switch ticket, ok := tickets[ticketID]; {
+		case err != nil:
+			results[i] = &dataloader.Result{Error: err}
+		case !ok:
+			results[i] = &dataloader.Result{Error: customErrors.NewRestError(fmt.Sprintf("Ticket %d not found", ticketID), 404)}
+		default:
+			results[i] = &dataloader.Result{Data: ticket}
+		}
+	}
+
+	return results
+}
+
+func ticketKey(ticketID int) dataloader.Key {
+	return dataloader.StringKey(strconv.Itoa(ticketID))
+}
+
+func loadTicket(ctx context.Context, ticketID int) (*models.Ticket, error) {
+	data, err := requestScopeFrom(ctx).tickets.Load(ctx, ticketKey(ticketID))()
+	if err != nil {
+		return nil, err
+	}
+	return data.(*models.Ticket), nil
+}
+
+// loadTickets loads the tickets in the order of ticketIDs, failing when any of them
+// can't be loaded.
+func loadTickets(ctx context.Context, ticketIDs []int) ([]*models.Ticket, error) {
+	keys := make(dataloader.Keys, len(ticketIDs))
+	for i, ticketID := range ticketIDs {
+		keys[i] = ticketKey(ticketID)
+	}
+
+	data, errs := requestScopeFrom(ctx).tickets.LoadMany(ctx, keys)()
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	tickets := make([]*models.Ticket, len(data))
+	for i, ticket := range data {
+		tickets[i] = ticket.(*models.Ticket)
+	}
+	return tickets, nil
+}
+
+func primeTicket(ctx context.Context, ticket *models.Ticket) {
+	requestScopeFrom(ctx).tickets.Prime(ctx, ticketKey(ticket.ID), ticket)
+}
+
+func clearTicket(ctx context.Context, ticketID int) {
+	requestScopeFrom(ctx).tickets.Clear(ctx, ticketKey(ticketID))
+}
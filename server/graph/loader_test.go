@@ -0,0 +1,135 @@
+package graph_test
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"gowitcase/graph"
+	"gowitcase/mocks"
+	"gowitcase/models"
+	"gowitcase/services"
+	"sort"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/graph-gophers/graphql-go"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+const testOrganizerID = 9
+
+func setupGraphTest(t *testing.T) (*graphql.Schema, *services.TicketService, sqlmock.Sqlmock) {
+	mockDB, mock, err := mocks.NewMockDatabase()
+	assert.NoError(t, err)
+
+	ticketService := services.NewTicketService(mockDB, mocks.NewMockRedis())
+	schema, err := graph.NewSchema(ticketService)
+	assert.NoError(t, err, "failed to parse the schema")
+
+	return schema, ticketService, mock
+}
+
+func newTicketRows(tickets ...*models.Ticket) *sqlmock.Rows {
+	rows := sqlmock.NewRows([]string{
+		"id", "organizer_id", "event_id", "capacity_pool_id", "name", "description", "allocation", "held", "sold", "max_per_buyer", "min_per_order", "max_per_order", "quantity_step", "seated", "price", "currency",
+		"sale_starts_at", "sale_ends_at", "created_at", "updated_at", "archived_at",
+	})
+	for _, ticket := range tickets {
+		rows.AddRow(
+			ticket.ID, ticket.OrganizerID, ticket.EventID, ticket.CapacityPoolID, ticket.Name, ticket.Description, ticket.Allocation, ticket.Held, ticket.Sold, ticket.MaxPerBuyer,
+			ticket.MinPerOrder, ticket.MaxPerOrder, ticket.QuantityStep, ticket.Seated, ticket.Price.Amount, ticket.Price.Currency, ticket.SaleStartsAt, ticket.SaleEndsAt, ticket.CreatedAt, ticket.UpdatedAt, ticket.ArchivedAt,
+		)
+	}
+	return rows
+}
+
+// ticketIDs matches the array of ticket IDs of a batch, in any order, since the
+// loader collects them in the order the fields happen to be resolved.
+type ticketIDs []int64
+
+func (ids ticketIDs) Match(value driver.Value) bool {
+	var got pq.Int64Array
+	if err := got.Scan(value); err != nil {
+		return false
+	}
+
+	sort.Slice(got, func(i, j int) bool { return got[i] < got[j] })
+	return assert.ObjectsAreEqual([]int64(ids), []int64(got))
+}
+
+func execQuery(t *testing.T, schema *graphql.Schema, ticketService *services.TicketService, query string, result interface{}) {
+	ctx := graph.WithRequest(context.Background(), ticketService, testOrganizerID)
+	response := schema.Exec(ctx, query, "", nil)
+	assert.Empty(t, response.Errors, "unexpected errors")
+	assert.NoError(t, json.Unmarshal(response.Data, result))
+}
+
+func TestTicketLoader_BatchesTickets(t *testing.T) {
+	schema, ticketService, mock := setupGraphTest(t)
+
+	// Every ticket of the query is read by a single query, including the one that
+	// isn't found and the one requested twice.
+	mock.ExpectQuery("SELECT (.+) FROM ticket WHERE id = ANY\\(\\$1\\) AND organizer_id = \\$2").
+		WithArgs(ticketIDs{1, 2, 3, 4}, testOrganizerID).
+		WillReturnRows(newTicketRows(
+			&models.Ticket{ID: 1, OrganizerID: testOrganizerID, Name: "GA", Allocation: 100},
+			&models.Ticket{ID: 2, OrganizerID: testOrganizerID, Name: "VIP", Allocation: 10},
+			&models.Ticket{ID: 3, OrganizerID: testOrganizerID, Name: "Backstage", Allocation: 2, Sold: 1},
+		))
+
+	var result struct {
+		GA        *struct{ Name string }
+		VIP       *struct{ Name string }
+		Missing   *struct{ Name string }
+		Available []struct {
+			TicketID  string
+			Available int
+		}
+	}
+	execQuery(t, schema, ticketService, `{
+		ga: ticket(id: "1") { name }
+		vip: ticket(id: "2") { name }
+		missing: ticket(id: "4") { name }
+		available: availability(ticketIds: ["3", "1"]) { ticketId available }
+	}`, &result)
+
+	assert.Equal(t, "GA", result.GA.Name)
+	assert.Equal(t, "VIP", result.VIP.Name)
+	assert.Nil(t, result.Missing, "expected null for a ticket that isn't found")
+	assert.Len(t, result.Available, 2, "expected the availability of both tickets")
+	assert.Equal(t, "3", result.Available[0].TicketID, "expected the order of ticketIds")
+	assert.Equal(t, 2, result.Available[0].Available)
+
+	assert.NoError(t, mock.ExpectationsWereMet(), "expected a single query")
+}
+
+func TestTicketLoader_SkipsCachedTickets(t *testing.T) {
+	schema, ticketService, mock := setupGraphTest(t)
+
+	mock.ExpectQuery("SELECT (.+) FROM ticket WHERE id = ").
+		WithArgs(1, testOrganizerID).
+		WillReturnRows(newTicketRows(&models.Ticket{ID: 1, OrganizerID: testOrganizerID, Name: "GA", Allocation: 100}))
+
+	_, err := ticketService.GetTicket(testOrganizerID, 1)
+	assert.NoError(t, err, "failed to get ticket")
+
+	// The cached ticket is left out of the batch.
+	mock.ExpectQuery("SELECT (.+) FROM ticket WHERE id = ANY\\(\\$1\\) AND organizer_id = \\$2").
+		WithArgs(ticketIDs{2}, testOrganizerID).
+		WillReturnRows(newTicketRows(&models.Ticket{ID: 2, OrganizerID: testOrganizerID, Name: "VIP", Allocation: 10}))
+
+	var result struct {
+		GA  struct{ Name string }
+		VIP struct{ Name string }
+	}
+	execQuery(t, schema, ticketService, `{
+		ga: ticket(id: "1") { name }
+		vip: ticket(id: "2") { name }
+	}`, &result)
+
+	assert.Equal(t, "GA", result.GA.Name)
+	assert.Equal(t, "VIP", result.VIP.Name)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
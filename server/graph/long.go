@@ -0,0 +1,42 @@
+package graph
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// Long is the 64-bit integer scalar, since the GraphQL Int is only 32 bits.
+type Long int64
+
+func (Long) ImplementsGraphQLType(name string) bool {
+	return name == "Long"
+}
+
+func (l *Long) UnmarshalGraphQL(input interface{}) error {
+	switch value := input.(type) {
+	case int32:
+		*l = Long(value)
+	case int64:
+		*l = Long(value)
+	case float64:
+		if value != math.Trunc(value) || value < math.MinInt64 || value >= math.MaxInt64 {
+			return fmt.Errorf("Long must be an integer within the valid range")
+		}
+		*l = Long(value)
+	case string:
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("Long must be an integer within the valid range")
+		}
+		*l = Long(parsed)
+	default:
+		return fmt.Errorf("wrong type for Long: %T", input)
+	}
+	return nil
+}
+
+func (l Long) MarshalJSON() ([]byte, error) {
+	return json.Marshal(int64(l))
+}
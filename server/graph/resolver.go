@@ -0,0 +1,282 @@
+package graph
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	customErrors "gowitcase/errors"
+	"gowitcase/models"
+	"gowitcase/services"
+	"log"
+	"strconv"
+
+	"github.com/graph-gophers/graphql-go"
+)
+
+//go:embed schema.graphql
+var schemaString string
+
+// NewSchema parses the GraphQL schema with resolvers calling ticketService. Every
+// request executed on it needs a context made by WithRequest.
+func NewSchema(ticketService *services.TicketService) (*graphql.Schema, error) {
+	return graphql.ParseSchema(schemaString, &Resolver{TicketService: ticketService}, graphql.MaxDepth(10))
+}
+
+// Resolver is the root resolver of the queries and mutations.
+type Resolver struct {
+	TicketService *services.TicketService
+}
+
+func (r *Resolver) Ticket(ctx context.Context, args struct{ ID graphql.ID }) (*ticketResolver, error) {
+	ticketID, err := parseID(args.ID, "ticket")
+	if err != nil {
+		return nil, err
+	}
+
+	ticket, err := loadTicket(ctx, ticketID)
+	if err != nil {
+		if restErr, ok := err.(customErrors.RestError); ok && restErr.Status == 404 {
+			return nil, nil
+		}
+		return nil, resolverError(err)
+	}
+
+	return &ticketResolver{ticket: ticket}, nil
+}
+
+type ticketsArgs struct {
+	Name          *string
+	EventID       *graphql.ID
+	MinAllocation *int32
+	Sort          *string
+	Order         *string
+	First         *int32
+	After         *string
+}
+
+func (r *Resolver) Tickets(ctx context.Context, args ticketsArgs) (*ticketConnectionResolver, error) {
+	params := models.TicketListParams{}
+	if args.Name != nil {
+		params.Name = *args.Name
+	}
+	if args.EventID != nil {
+		eventID, err := parseID(*args.EventID, "event")
+		if err != nil {
+			return nil, err
+		}
+		params.EventID = eventID
+	}
+	if args.MinAllocation != nil {
+		params.MinAllocation = int(*args.MinAllocation)
+	}
+	if args.Sort != nil {
+		params.Sort = *args.Sort
+	}
+	if args.Order != nil {
+		params.Order = *args.Order
+	}
+	if args.First != nil {
+		params.Limit = int(*args.First)
+	}
+	if args.After != nil {
+		params.Cursor = *args.After
+	}
+
+	scope := requestScopeFrom(ctx)
+	list, err := r.TicketService.ListTickets(scope.organizerID, params)
+	if err != nil {
+		return nil, resolverError(err)
+	}
+
+	// Nested fields of the listed tickets are resolved without loading them again.
+	for i := range list.Tickets {
+		primeTicket(ctx, &list.Tickets[i])
+	}
+
+	return &ticketConnectionResolver{list: list}, nil
+}
+
+func (r *Resolver) Availability(ctx context.Context, args struct{ TicketIDs []graphql.ID }) ([]*availabilityResolver, error) {
+	if len(args.TicketIDs) > models.TicketListMaxLimit {
+		return nil, resolverError(customErrors.NewRestError(
+			fmt.Sprintf("At most %d tickets can be checked at once", models.TicketListMaxLimit), 400,
+		))
+	}
+
+	ticketIDs := make([]int, len(args.TicketIDs))
+	for i, id := range args.TicketIDs {
+		ticketID, err := parseID(id, "ticket")
+		if err != nil {
+			return nil, err
+		}
+		ticketIDs[i] = ticketID
+	}
+
+	tickets, err := loadTickets(ctx, ticketIDs)
+	if err != nil {
+		return nil, resolverError(err)
+	}
+
+	availability := make([]*availabilityResolver, len(tickets))
+	for i, ticket := range tickets {
+		availability[i] = &availabilityResolver{ticket: ticket}
+	}
+	return availability, nil
+}
+
+type createTicketArgs struct {
+	Input struct {
+		Name           string
+		Description    *string
+		Allocation     int32
+		EventID        *graphql.ID
+		CapacityPoolID *graphql.ID
+		MaxPerBuyer    *int32
+		MinPerOrder    *int32
+		MaxPerOrder    *int32
+		QuantityStep   *int32
+		Price          *struct {
+			Amount   Long
+			Currency *string
+		}
+		SaleStartsAt *graphql.Time
+		SaleEndsAt   *graphql.Time
+	}
+}
+
+func (r *Resolver) CreateTicket(ctx context.Context, args createTicketArgs) (*ticketResolver, error) {
+	input := args.Input
+	ticket := &models.Ticket{
+		Name:         input.Name,
+		Allocation:   int(input.Allocation),
+		MaxPerBuyer:  int(derefInt32(input.MaxPerBuyer)),
+		MinPerOrder:  int(derefInt32(input.MinPerOrder)),
+		MaxPerOrder:  int(derefInt32(input.MaxPerOrder)),
+		QuantityStep: int(derefInt32(input.QuantityStep)),
+	}
+	if input.Description != nil {
+		ticket.Description = *input.Description
+	}
+	if input.EventID != nil {
+		eventID, err := parseID(*input.EventID, "event")
+		if err != nil {
+			return nil, err
+		}
+		ticket.EventID = &eventID
+	}
+	if input.CapacityPoolID != nil {
+		poolID, err := parseID(*input.CapacityPoolID, "capacity pool")
+		if err != nil {
+			return nil, err
+		}
+		ticket.CapacityPoolID = &poolID
+	}
+	if input.Price != nil {
+		ticket.Price.Amount = int64(input.Price.Amount)
+		if input.Price.Currency != nil {
+			ticket.Price.Currency = *input.Price.Currency
+		}
+	}
+	if input.SaleStartsAt != nil {
+		ticket.SaleStartsAt = &input.SaleStartsAt.Time
+	}
+	if input.SaleEndsAt != nil {
+		ticket.SaleEndsAt = &input.SaleEndsAt.Time
+	}
+
+	scope := requestScopeFrom(ctx)
+	err := r.TicketService.CreateTicket(scope.organizerID, ticket)
+	if err != nil {
+		return nil, resolverError(err)
+	}
+
+	return &ticketResolver{ticket: ticket}, nil
+}
+
+type purchaseTicketArgs struct {
+	TicketID graphql.ID
+	Input    struct {
+		BuyerID   string
+		Quantity  *int32
+		SeatIDs   *[]graphql.ID
+		PromoCode *string
+	}
+}
+
+func (r *Resolver) PurchaseTicket(ctx context.Context, args purchaseTicketArgs) (*purchaseResolver, error) {
+	ticketID, err := parseID(args.TicketID, "ticket")
+	if err != nil {
+		return nil, err
+	}
+
+	request := models.PurchaseRequest{
+		BuyerID:  args.Input.BuyerID,
+		Quantity: int(derefInt32(args.Input.Quantity)),
+	}
+	if args.Input.SeatIDs != nil {
+		for _, id := range *args.Input.SeatIDs {
+			seatID, err := parseID(id, "seat")
+			if err != nil {
+				return nil, err
+			}
+			request.SeatIDs = append(request.SeatIDs, seatID)
+		}
+	}
+	if args.Input.PromoCode != nil {
+		request.PromoCode = *args.Input.PromoCode
+	}
+
+	scope := requestScopeFrom(ctx)
+	purchase, err := r.TicketService.PurchaseTicket(scope.organizerID, ticketID, request)
+	if err != nil {
+		return nil, resolverError(err)
+	}
+
+	// The purchase changed the ticket, so its nested fields load it again.
+	clearTicket(ctx, ticketID)
+
+	return &purchaseResolver{purchase: purchase}, nil
+}
+
+// restError exposes the status, code and details of a RestError in the extensions
+// of its GraphQL error.
+type restError struct {
+	customErrors.RestError
+}
+
+func (e restError) Extensions() map[string]interface{} {
+	extensions := map[string]interface{}{"status": e.Status, "code": e.Code}
+	if len(e.Details) > 0 {
+		extensions["details"] = e.Details
+	}
+	if len(e.Fields) > 0 {
+		extensions["errors"] = e.Fields
+	}
+	return extensions
+}
+
+// resolverError turns a service error into the error returned to the client. Only
+// the message of a RestError is returned, other errors are logged.
+func resolverError(err error) error {
+	if restErr, ok := err.(customErrors.RestError); ok {
+		return restError{restErr}
+	}
+
+	log.Printf("Failed to resolve GraphQL field with err: %v", err)
+	return fmt.Errorf("Something went wrong, please try again.")
+}
+
+func parseID(id graphql.ID, kind string) (int, error) {
+	value, err := strconv.Atoi(string(id))
+	if err != nil {
+		return 0, restError{customErrors.NewRestError(fmt.Sprintf("Invalid %s ID", kind), 400)}
+	}
+	return value, nil
+}
+
+func derefInt32(value *int32) int32 {
+	if value == nil {
+		return 0
+	}
+	return *value
+}
@@ -0,0 +1,232 @@
+package graph
+
+import (
+	"context"
+	"gowitcase/models"
+	"strconv"
+	"time"
+
+	"github.com/graph-gophers/graphql-go"
+)
+
+type ticketResolver struct {
+	ticket *models.Ticket
+}
+
+func (r *ticketResolver) ID() graphql.ID {
+	return intID(r.ticket.ID)
+}
+
+func (r *ticketResolver) EventID() *graphql.ID {
+	return optionalID(r.ticket.EventID)
+}
+
+func (r *ticketResolver) CapacityPoolID() *graphql.ID {
+	return optionalID(r.ticket.CapacityPoolID)
+}
+
+func (r *ticketResolver) Name() string {
+	return r.ticket.Name
+}
+
+func (r *ticketResolver) Description() string {
+	return r.ticket.Description
+}
+
+func (r *ticketResolver) Allocation() int32 {
+	return int32(r.ticket.Allocation)
+}
+
+func (r *ticketResolver) TotalAllocation() int32 {
+	return int32(r.ticket.TotalAllocation)
+}
+
+func (r *ticketResolver) Held() int32 {
+	return int32(r.ticket.Held)
+}
+
+func (r *ticketResolver) Sold() int32 {
+	return int32(r.ticket.Sold)
+}
+
+func (r *ticketResolver) MaxPerBuyer() int32 {
+	return int32(r.ticket.MaxPerBuyer)
+}
+
+func (r *ticketResolver) MinPerOrder() int32 {
+	return int32(r.ticket.MinPerOrder)
+}
+
+func (r *ticketResolver) MaxPerOrder() int32 {
+	return int32(r.ticket.MaxPerOrder)
+}
+
+func (r *ticketResolver) QuantityStep() int32 {
+	return int32(r.ticket.QuantityStep)
+}
+
+func (r *ticketResolver) Seated() bool {
+	return r.ticket.Seated
+}
+
+func (r *ticketResolver) Price() *moneyResolver {
+	return &moneyResolver{money: r.ticket.Price}
+}
+
+func (r *ticketResolver) SaleStatus() string {
+	return r.ticket.SaleStatus
+}
+
+func (r *ticketResolver) SaleStartsAt() *graphql.Time {
+	return optionalTime(r.ticket.SaleStartsAt)
+}
+
+func (r *ticketResolver) SaleEndsAt() *graphql.Time {
+	return optionalTime(r.ticket.SaleEndsAt)
+}
+
+func (r *ticketResolver) CreatedAt() graphql.Time {
+	return graphql.Time{Time: r.ticket.CreatedAt}
+}
+
+func (r *ticketResolver) UpdatedAt() graphql.Time {
+	return graphql.Time{Time: r.ticket.UpdatedAt}
+}
+
+func (r *ticketResolver) ArchivedAt() *graphql.Time {
+	return optionalTime(r.ticket.ArchivedAt)
+}
+
+func (r *ticketResolver) Availability() *availabilityResolver {
+	return &availabilityResolver{ticket: r.ticket}
+}
+
+type ticketConnectionResolver struct {
+	list *models.TicketList
+}
+
+func (r *ticketConnectionResolver) Tickets() []*ticketResolver {
+	tickets := make([]*ticketResolver, len(r.list.Tickets))
+	for i := range r.list.Tickets {
+		tickets[i] = &ticketResolver{ticket: &r.list.Tickets[i]}
+	}
+	return tickets
+}
+
+func (r *ticketConnectionResolver) NextCursor() *string {
+	if r.list.NextCursor == "" {
+		return nil
+	}
+	return &r.list.NextCursor
+}
+
+type availabilityResolver struct {
+	ticket *models.Ticket
+}
+
+func (r *availabilityResolver) TicketID() graphql.ID {
+	return intID(r.ticket.ID)
+}
+
+func (r *availabilityResolver) Available() int32 {
+	return int32(r.ticket.Allocation)
+}
+
+func (r *availabilityResolver) Held() int32 {
+	return int32(r.ticket.Held)
+}
+
+func (r *availabilityResolver) Sold() int32 {
+	return int32(r.ticket.Sold)
+}
+
+func (r *availabilityResolver) SaleStatus() string {
+	return r.ticket.SaleStatus
+}
+
+func (r *availabilityResolver) OnSale() bool {
+	return r.ticket.SaleStatus == models.SaleStatusOnSale
+}
+
+type purchaseResolver struct {
+	purchase *models.Purchase
+}
+
+func (r *purchaseResolver) ID() graphql.ID {
+	return intID(r.purchase.ID)
+}
+
+func (r *purchaseResolver) TicketID() graphql.ID {
+	return intID(r.purchase.TicketID)
+}
+
+func (r *purchaseResolver) Ticket(ctx context.Context) (*ticketResolver, error) {
+	ticket, err := loadTicket(ctx, r.purchase.TicketID)
+	if err != nil {
+		return nil, resolverError(err)
+	}
+	return &ticketResolver{ticket: ticket}, nil
+}
+
+func (r *purchaseResolver) BuyerID() string {
+	return r.purchase.BuyerID
+}
+
+func (r *purchaseResolver) Quantity() int32 {
+	return int32(r.purchase.Quantity)
+}
+
+func (r *purchaseResolver) UnitPrice() *moneyResolver {
+	return &moneyResolver{money: r.purchase.UnitPrice}
+}
+
+func (r *purchaseResolver) Discount() *moneyResolver {
+	return &moneyResolver{money: r.purchase.Discount}
+}
+
+func (r *purchaseResolver) Total() *moneyResolver {
+	return &moneyResolver{money: r.purchase.Total}
+}
+
+func (r *purchaseResolver) SeatIDs() []graphql.ID {
+	seatIDs := make([]graphql.ID, len(r.purchase.SeatIDs))
+	for i, seatID := range r.purchase.SeatIDs {
+		seatIDs[i] = intID(seatID)
+	}
+	return seatIDs
+}
+
+func (r *purchaseResolver) CreatedAt() graphql.Time {
+	return graphql.Time{Time: r.purchase.CreatedAt}
+}
+
+type moneyResolver struct {
+	money models.Money
+}
+
+func (r *moneyResolver) Amount() Long {
+	return Long(r.money.Amount)
+}
+
+func (r *moneyResolver) Currency() string {
+	return r.money.Currency
+}
+
+func intID(id int) graphql.ID {
+	return graphql.ID(strconv.Itoa(id))
+}
+
+func optionalID(id *int) *graphql.ID {
+	if id == nil {
+		return nil
+	}
+	value := intID(*id)
+	return &value
+}
+
+func optionalTime(t *time.Time) *graphql.Time {
+	if t == nil {
+		return nil
+	}
+	return &graphql.Time{Time: *t}
+}
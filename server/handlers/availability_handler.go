@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	customErrors "gowitcase/errors"
+	"gowitcase/models"
+	"gowitcase/problem"
+	"gowitcase/services"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// availabilityKeepAlive is how often an idle stream sends a comment, so proxies
+// don't close it.
+const availabilityKeepAlive = 15 * time.Second
+
+type AvailabilityHandler struct {
+	TicketService       *services.TicketService
+	AvailabilityService *services.AvailabilityService
+}
+
+func NewAvailabilityHandler(ticketService *services.TicketService, availabilityService *services.AvailabilityService) *AvailabilityHandler {
+	return &AvailabilityHandler{TicketService: ticketService, AvailabilityService: availabilityService}
+}
+
+// StreamAvailability sends the availability of the ticket as a Server-Sent Event,
+// then again every time it changes, until the client disconnects.
+func (h *AvailabilityHandler) StreamAvailability(ctx *gin.Context) {
+	ticketID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		problem.Respond(ctx, customErrors.NewRestError("Invalid ticket ID", http.StatusBadRequest))
+		return
+	}
+
+	organizerID := ctx.GetInt(models.OrganizerIDKey)
+
+	// Watching starts before the first read, so a change made in between isn't missed.
+	changes, stop := h.AvailabilityService.Watch(organizerID, ticketID)
+	defer stop()
+
+	ticket, err := h.TicketService.GetTicket(organizerID, ticketID)
+	if err != nil {
+		problem.Respond(ctx, err)
+		return
+	}
+
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("Connection", "keep-alive")
+	ctx.Header("X-Accel-Buffering", "no")
+
+	availability := ticket.Availability()
+	ctx.SSEvent("availability", availability)
+	ctx.Writer.Flush()
+
+	keepAlive := time.NewTicker(availabilityKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-ctx.Request.Context().Done():
+			return
+
+		case <-keepAlive.C:
+			_, err := ctx.Writer.WriteString(": keep-alive\n\n")
+			if err != nil {
+				return
+			}
+			ctx.Writer.Flush()
+
+		case <-changes:
+			ticket, err := h.TicketService.GetTicket(organizerID, ticketID)
+			if err != nil {
+				log.Printf("Failed to get ticket with err: %v, ticketID: %d", err, ticketID)
+				if _, ok := err.(customErrors.RestError); ok {
+					return
+				}
+				continue
+			}
+
+			if ticket.Availability() == availability {
+				continue
+			}
+			availability = ticket.Availability()
+
+			ctx.SSEvent("availability", availability)
+			ctx.Writer.Flush()
+		}
+	}
+}
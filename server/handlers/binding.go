@@ -0,0 +1,260 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	customErrors "gowitcase/errors"
+	"gowitcase/problem"
+	"io"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+func init() {
+	// Binding failures are reported by the JSON names of the fields.
+	if validate, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		validate.RegisterTagNameFunc(jsonFieldName)
+	}
+}
+
+// bindJSON decodes the JSON body of the request into obj, which has to point to a
+// struct, and checks its binding tags. When that fails, every invalid field is
+// reported at once and false is returned.
+func bindJSON(ctx *gin.Context, obj interface{}) bool {
+	body, err := io.ReadAll(ctx.Request.Body)
+	if err != nil {
+		problem.Respond(ctx, customErrors.NewRestError("Failed to read request body", http.StatusBadRequest))
+		return false
+	}
+
+	err = decodeJSON(body, obj)
+	if err == nil {
+		err = bindingError(binding.Validator.ValidateStruct(obj))
+	}
+	if err != nil {
+		problem.Respond(ctx, err)
+		return false
+	}
+
+	return true
+}
+
+// decodeJSON unmarshals body into obj. encoding/json stops reporting after the
+// first invalid field and loses the indexes of arrays on the way, so on failure the
+// body is walked field by field to report every invalid one by its full path, such
+// as 'lines[1].quantity'.
+func decodeJSON(body []byte, obj interface{}) error {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return customErrors.NewRestError("Request body is required", http.StatusBadRequest)
+	}
+
+	if syntaxErr, ok := json.Unmarshal(body, new(json.RawMessage)).(*json.SyntaxError); ok {
+		return customErrors.NewRestError(
+			fmt.Sprintf("Request body is not valid JSON at offset %d", syntaxErr.Offset), http.StatusBadRequest,
+		)
+	}
+
+	if json.Unmarshal(body, obj) == nil {
+		return nil
+	}
+
+	var raw map[string]json.RawMessage
+	if json.Unmarshal(body, &raw) != nil {
+		return customErrors.NewRestError("Request body must be a JSON object", http.StatusBadRequest)
+	}
+
+	var fields customErrors.FieldErrors
+	checkJSONValue("", body, reflect.TypeOf(obj).Elem(), &fields)
+
+	if len(fields) == 0 {
+		return customErrors.NewRestError("Request body is invalid", http.StatusBadRequest)
+	}
+	return fields.Err()
+}
+
+var jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// checkJSONValue reports why value, found at path, doesn't decode into a t. Objects
+// and arrays are walked down to the fields that fail, so every one of them is found.
+// Keys t has no field for are ignored, like encoding/json does.
+func checkJSONValue(path string, value json.RawMessage, t reflect.Type, fields *customErrors.FieldErrors) {
+	if string(bytes.TrimSpace(value)) == "null" {
+		return
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	// Types that decode themselves, such as time.Time, are checked as a whole.
+	if reflect.PtrTo(t).Implements(jsonUnmarshalerType) {
+		checkJSONLeaf(path, value, t, fields)
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		var object map[string]json.RawMessage
+		if json.Unmarshal(value, &object) != nil {
+			checkJSONLeaf(path, value, t, fields)
+			return
+		}
+
+		keys := make([]string, 0, len(object))
+		for key := range object {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			field, ok := jsonStructField(t, key)
+			if !ok {
+				continue
+			}
+			checkJSONValue(joinFieldPath(path, key), object[key], field.Type, fields)
+		}
+	case reflect.Slice, reflect.Array:
+		var items []json.RawMessage
+		if json.Unmarshal(value, &items) != nil {
+			checkJSONLeaf(path, value, t, fields)
+			return
+		}
+
+		for i, item := range items {
+			checkJSONValue(fmt.Sprintf("%s[%d]", path, i), item, t.Elem(), fields)
+		}
+	default:
+		checkJSONLeaf(path, value, t, fields)
+	}
+}
+
+// checkJSONLeaf reports the error of decoding value into a t, if any.
+func checkJSONLeaf(path string, value json.RawMessage, t reflect.Type, fields *customErrors.FieldErrors) {
+	err := json.Unmarshal(value, reflect.New(t).Interface())
+	if err != nil {
+		fields.Add(path, fieldErrorMessage(path, t, err))
+	}
+}
+
+// jsonStructField finds the field of struct t that the JSON key decodes into,
+// matching names without regard to case like encoding/json.
+func jsonStructField(t reflect.Type, key string) (reflect.StructField, bool) {
+	var folded *reflect.StructField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			if embedded, ok := jsonStructField(field.Type, key); ok {
+				return embedded, true
+			}
+			continue
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+
+		name := jsonFieldName(field)
+		if name == key {
+			return field, true
+		}
+		if folded == nil && name != "" && strings.EqualFold(name, key) {
+			folded = &field
+		}
+	}
+
+	if folded != nil {
+		return *folded, true
+	}
+	return reflect.StructField{}, false
+}
+
+func joinFieldPath(path string, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// fieldErrorMessage describes the error of decoding the field at path into a t.
+func fieldErrorMessage(path string, t reflect.Type, err error) string {
+	switch err := err.(type) {
+	case *json.UnmarshalTypeError:
+		if err.Type != nil {
+			t = err.Type
+		}
+		return fmt.Sprintf("Field '%s' must be %s", path, jsonTypeName(t))
+	case *time.ParseError:
+		return fmt.Sprintf("Field '%s' must be a time in RFC 3339 format", path)
+	}
+
+	return fmt.Sprintf("Field '%s' has an invalid value", path)
+}
+
+// jsonTypeName names the JSON type a Go value of type t is decoded from.
+func jsonTypeName(t reflect.Type) string {
+	if t == reflect.TypeOf(time.Time{}) {
+		return "a time in RFC 3339 format"
+	}
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "an integer within the valid range"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	case reflect.Struct, reflect.Map:
+		return "an object"
+	case reflect.Ptr:
+		return jsonTypeName(t.Elem())
+	}
+
+	return "a valid value"
+}
+
+// bindingError turns the failed binding tags of a request into field errors.
+func bindingError(err error) error {
+	validationErrors, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return err
+	}
+
+	var fields customErrors.FieldErrors
+	for _, fieldErr := range validationErrors {
+		// The namespace starts with the name of the struct itself.
+		path := fieldErr.Namespace()
+		if i := strings.Index(path, "."); i >= 0 {
+			path = path[i+1:]
+		}
+
+		if fieldErr.Tag() == "required" {
+			fields.Add(path, fmt.Sprintf("Field '%s' is required", path))
+		} else {
+			fields.Add(path, fmt.Sprintf("Field '%s' failed the '%s' check", path, fieldErr.Tag()))
+		}
+	}
+
+	return fields.Err()
+}
+
+func jsonFieldName(field reflect.StructField) string {
+	name := strings.Split(field.Tag.Get("json"), ",")[0]
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
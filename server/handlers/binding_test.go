@@ -0,0 +1,94 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"gowitcase/handlers"
+	"gowitcase/problem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// postBody sends body to handler, which fails to bind it before using its service.
+func postBody(t *testing.T, handler gin.HandlerFunc, body string) problem.Problem {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.POST("/tickets/:id", handler)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/tickets/1", strings.NewReader(body)))
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	var p problem.Problem
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &p), "expected a problem")
+	return p
+}
+
+func fieldMessages(p problem.Problem) map[string]string {
+	messages := map[string]string{}
+	for _, field := range p.Errors {
+		messages[field.Field] = field.Message
+	}
+	return messages
+}
+
+func TestBindJSON_TypeMismatches(t *testing.T) {
+	p := postBody(t, handlers.NewTicketHandler(nil).CreateTicket,
+		`{"name":5,"allocation":"many","price":{"amount":"x","currency":"EUR"},"sale_starts_at":"tomorrow"}`)
+
+	assert.Equal(t, map[string]string{
+		"name":           "Field 'name' must be a string",
+		"allocation":     "Field 'allocation' must be an integer within the valid range",
+		"price.amount":   "Field 'price.amount' must be an integer within the valid range",
+		"sale_starts_at": "Field 'sale_starts_at' must be a time in RFC 3339 format",
+	}, fieldMessages(p), "expected every invalid field")
+}
+
+func TestBindJSON_IgnoresUnknownFields(t *testing.T) {
+	// Clients may send properties the request doesn't have, only the known fields
+	// are checked.
+	p := postBody(t, handlers.NewTicketHandler(nil).CreateTicket,
+		`{"name":"GA","alocation":"many","allocation":"many","price":{"amount":1000,"currency":"EUR","vat":"20%"}}`)
+
+	assert.Equal(t, map[string]string{
+		"allocation": "Field 'allocation' must be an integer within the valid range",
+	}, fieldMessages(p), "expected only the known invalid field")
+}
+
+func TestBindJSON_ArrayPaths(t *testing.T) {
+	p := postBody(t, handlers.NewOrderHandler(nil).Checkout,
+		`{"buyer_id":"buyer","lines":[{"ticket_id":1,"quantity":1},{"ticket_id":2,"quantity":"two"},{"ticket_id":3,"seat_ids":[4,"5"]}]}`)
+
+	assert.Equal(t, map[string]string{
+		"lines[1].quantity":    "Field 'lines[1].quantity' must be an integer within the valid range",
+		"lines[2].seat_ids[1]": "Field 'lines[2].seat_ids[1]' must be an integer within the valid range",
+	}, fieldMessages(p), "expected the index of every invalid line")
+}
+
+func TestBindJSON_NestedArrayPaths(t *testing.T) {
+	p := postBody(t, handlers.NewPricingRuleHandler(nil).PutPricingRules,
+		`{"rules":[{"sold_bps":5000,"change_bps":1000},{"sold_bps":8000.5,"change_bps":2000,"note":"peak"}]}`)
+
+	assert.Equal(t, map[string]string{
+		"rules[1].sold_bps": "Field 'rules[1].sold_bps' must be an integer within the valid range",
+	}, fieldMessages(p))
+}
+
+func TestBindJSON_InvalidBody(t *testing.T) {
+	tests := map[string]string{
+		"":         "Request body is required",
+		`{"name":`: "Request body is not valid JSON at offset 8",
+		`[1,2]`:    "Request body must be a JSON object",
+	}
+
+	for body, detail := range tests {
+		p := postBody(t, handlers.NewTicketHandler(nil).CreateTicket, body)
+		assert.Equal(t, detail, p.Detail)
+		assert.Empty(t, p.Errors, "expected no field errors")
+	}
+}
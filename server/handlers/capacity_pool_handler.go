@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	customErrors "gowitcase/errors"
+	"gowitcase/models"
+	"gowitcase/problem"
+	"gowitcase/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CapacityPoolHandler struct {
+	CapacityPoolService *services.CapacityPoolService
+}
+
+func NewCapacityPoolHandler(capacityPoolService *services.CapacityPoolService) *CapacityPoolHandler {
+	return &CapacityPoolHandler{CapacityPoolService: capacityPoolService}
+}
+
+func (h *CapacityPoolHandler) CreateCapacityPool(ctx *gin.Context) {
+	pool := &models.CapacityPool{}
+	if !bindJSON(ctx, pool) {
+		return
+	}
+
+	err := h.CapacityPoolService.CreateCapacityPool(ctx.GetInt(models.OrganizerIDKey), pool)
+	if err != nil {
+		problem.Respond(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, pool)
+}
+
+func (h *CapacityPoolHandler) GetCapacityPool(ctx *gin.Context) {
+	poolID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		problem.Respond(ctx, customErrors.NewRestError("Invalid capacity pool ID", http.StatusBadRequest))
+		return
+	}
+
+	pool, err := h.CapacityPoolService.GetCapacityPool(ctx.GetInt(models.OrganizerIDKey), poolID)
+	if err != nil {
+		problem.Respond(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, pool)
+}
+
+func (h *CapacityPoolHandler) PatchCapacityPool(ctx *gin.Context) {
+	poolID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		problem.Respond(ctx, customErrors.NewRestError("Invalid capacity pool ID", http.StatusBadRequest))
+		return
+	}
+
+	update := models.CapacityPoolUpdate{}
+	if !bindJSON(ctx, &update) {
+		return
+	}
+
+	pool, err := h.CapacityPoolService.UpdateCapacityPool(ctx.GetInt(models.OrganizerIDKey), poolID, update)
+	if err != nil {
+		problem.Respond(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, pool)
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	customErrors "gowitcase/errors"
+	"gowitcase/models"
+	"gowitcase/problem"
+	"gowitcase/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CheckInHandler struct {
+	CheckInService *services.CheckInService
+}
+
+func NewCheckInHandler(checkInService *services.CheckInService) *CheckInHandler {
+	return &CheckInHandler{CheckInService: checkInService}
+}
+
+func (h *CheckInHandler) CheckIn(ctx *gin.Context) {
+	checkInRequest := &models.CheckInRequest{}
+	if !bindJSON(ctx, checkInRequest) {
+		return
+	}
+
+	checkIn, err := h.CheckInService.CheckIn(ctx.GetInt(models.OrganizerIDKey), *checkInRequest)
+	if err != nil {
+		problem.Respond(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, checkIn)
+}
+
+func (h *CheckInHandler) GetCheckInCount(ctx *gin.Context) {
+	ticketID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		problem.Respond(ctx, customErrors.NewRestError("Invalid ticket ID", http.StatusBadRequest))
+		return
+	}
+
+	count, err := h.CheckInService.GetCheckInCount(ctx.GetInt(models.OrganizerIDKey), ticketID)
+	if err != nil {
+		problem.Respond(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, count)
+}
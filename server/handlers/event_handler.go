@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	customErrors "gowitcase/errors"
+	"gowitcase/models"
+	"gowitcase/problem"
+	"gowitcase/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type EventHandler struct {
+	EventService *services.EventService
+}
+
+func NewEventHandler(eventService *services.EventService) *EventHandler {
+	return &EventHandler{EventService: eventService}
+}
+
+func (h *EventHandler) CreateEvent(ctx *gin.Context) {
+	event := &models.Event{}
+	if !bindJSON(ctx, event) {
+		return
+	}
+
+	err := h.EventService.CreateEvent(ctx.GetInt(models.OrganizerIDKey), event)
+	if err != nil {
+		problem.Respond(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, event)
+}
+
+func (h *EventHandler) GetEvent(ctx *gin.Context) {
+	eventID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		problem.Respond(ctx, customErrors.NewRestError("Invalid event ID", http.StatusBadRequest))
+		return
+	}
+
+	event, err := h.EventService.GetEvent(ctx.GetInt(models.OrganizerIDKey), eventID)
+	if err != nil {
+		problem.Respond(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, event)
+}
+
+func (h *EventHandler) ReplaceEvent(ctx *gin.Context) {
+	eventID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		problem.Respond(ctx, customErrors.NewRestError("Invalid event ID", http.StatusBadRequest))
+		return
+	}
+
+	event := &models.Event{}
+	if !bindJSON(ctx, event) {
+		return
+	}
+
+	h.updateEvent(ctx, eventID, models.EventUpdate{
+		Name:        &event.Name,
+		Description: &event.Description,
+		Venue:       &event.Venue,
+		StartsAt:    models.OptionalTime{Set: true, Value: event.StartsAt},
+		EndsAt:      models.OptionalTime{Set: true, Value: event.EndsAt},
+	})
+}
+
+func (h *EventHandler) PatchEvent(ctx *gin.Context) {
+	eventID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		problem.Respond(ctx, customErrors.NewRestError("Invalid event ID", http.StatusBadRequest))
+		return
+	}
+
+	update := models.EventUpdate{}
+	if !bindJSON(ctx, &update) {
+		return
+	}
+
+	h.updateEvent(ctx, eventID, update)
+}
+
+func (h *EventHandler) updateEvent(ctx *gin.Context, eventID int, update models.EventUpdate) {
+	event, err := h.EventService.UpdateEvent(ctx.GetInt(models.OrganizerIDKey), eventID, update)
+	if err != nil {
+		problem.Respond(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, event)
+}
+
+func (h *EventHandler) ArchiveEvent(ctx *gin.Context) {
+	eventID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		problem.Respond(ctx, customErrors.NewRestError("Invalid event ID", http.StatusBadRequest))
+		return
+	}
+
+	err = h.EventService.ArchiveEvent(ctx.GetInt(models.OrganizerIDKey), eventID)
+	if err != nil {
+		problem.Respond(ctx, err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func (h *EventHandler) ListEvents(ctx *gin.Context) {
+	limit, err := queryInt(ctx, "limit")
+	if err != nil {
+		problem.Respond(ctx, customErrors.NewRestError("Invalid limit", http.StatusBadRequest))
+		return
+	}
+
+	list, err := h.EventService.ListEvents(ctx.GetInt(models.OrganizerIDKey), ctx.Query("cursor"), limit)
+	if err != nil {
+		problem.Respond(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, list)
+}
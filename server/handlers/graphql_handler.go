@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"gowitcase/graph"
+	"gowitcase/models"
+	"gowitcase/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/graph-gophers/graphql-go"
+)
+
+type GraphQLHandler struct {
+	Schema        *graphql.Schema
+	TicketService *services.TicketService
+}
+
+func NewGraphQLHandler(schema *graphql.Schema, ticketService *services.TicketService) *GraphQLHandler {
+	return &GraphQLHandler{Schema: schema, TicketService: ticketService}
+}
+
+type graphQLRequest struct {
+	Query         string                 `json:"query" binding:"required"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// Query executes a GraphQL request for the authenticated organizer. Errors are
+// returned in the response body, as GraphQL clients expect.
+func (h *GraphQLHandler) Query(ctx *gin.Context) {
+	request := graphQLRequest{}
+	if !bindJSON(ctx, &request) {
+		return
+	}
+
+	requestCtx := graph.WithRequest(ctx.Request.Context(), h.TicketService, ctx.GetInt(models.OrganizerIDKey))
+	response := h.Schema.Exec(requestCtx, request.Query, request.OperationName, request.Variables)
+
+	ctx.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	customErrors "gowitcase/errors"
+	"gowitcase/models"
+	"gowitcase/problem"
+	"gowitcase/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type HoldHandler struct {
+	HoldService *services.HoldService
+}
+
+func NewHoldHandler(holdService *services.HoldService) *HoldHandler {
+	return &HoldHandler{HoldService: holdService}
+}
+
+func (h *HoldHandler) CreateHold(ctx *gin.Context) {
+	ticketID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		problem.Respond(ctx, customErrors.NewRestError("Invalid ticket ID", http.StatusBadRequest))
+		return
+	}
+
+	holdRequest := &models.HoldRequest{}
+	if !bindJSON(ctx, holdRequest) {
+		return
+	}
+
+	hold, err := h.HoldService.CreateHold(ctx.GetInt(models.OrganizerIDKey), ticketID, *holdRequest)
+	if err != nil {
+		problem.Respond(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, hold)
+}
+
+func (h *HoldHandler) GetHold(ctx *gin.Context) {
+	holdID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		problem.Respond(ctx, customErrors.NewRestError("Invalid hold ID", http.StatusBadRequest))
+		return
+	}
+
+	hold, err := h.HoldService.GetHold(ctx.GetInt(models.OrganizerIDKey), holdID)
+	if err != nil {
+		problem.Respond(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, hold)
+}
+
+func (h *HoldHandler) ConfirmHold(ctx *gin.Context) {
+	holdID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		problem.Respond(ctx, customErrors.NewRestError("Invalid hold ID", http.StatusBadRequest))
+		return
+	}
+
+	purchase, err := h.HoldService.ConfirmHold(ctx.GetInt(models.OrganizerIDKey), holdID)
+	if err != nil {
+		problem.Respond(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, purchase)
+}
+
+func (h *HoldHandler) ReleaseHold(ctx *gin.Context) {
+	holdID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		problem.Respond(ctx, customErrors.NewRestError("Invalid hold ID", http.StatusBadRequest))
+		return
+	}
+
+	err = h.HoldService.ReleaseHold(ctx.GetInt(models.OrganizerIDKey), holdID)
+	if err != nil {
+		problem.Respond(ctx, err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
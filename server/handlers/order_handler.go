@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	customErrors "gowitcase/errors"
+	"gowitcase/models"
+	"gowitcase/problem"
+	"gowitcase/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type OrderHandler struct {
+	OrderService *services.OrderService
+}
+
+func NewOrderHandler(orderService *services.OrderService) *OrderHandler {
+	return &OrderHandler{OrderService: orderService}
+}
+
+func (h *OrderHandler) Checkout(ctx *gin.Context) {
+	orderRequest := &models.OrderRequest{}
+	if !bindJSON(ctx, orderRequest) {
+		return
+	}
+
+	order, err := h.OrderService.Checkout(ctx.GetInt(models.OrganizerIDKey), *orderRequest)
+	if err != nil {
+		problem.Respond(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, order)
+}
+
+func (h *OrderHandler) GetOrder(ctx *gin.Context) {
+	orderID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		problem.Respond(ctx, customErrors.NewRestError("Invalid order ID", http.StatusBadRequest))
+		return
+	}
+
+	order, err := h.OrderService.GetOrder(ctx.GetInt(models.OrganizerIDKey), orderID)
+	if err != nil {
+		problem.Respond(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, order)
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	customErrors "gowitcase/errors"
+	"gowitcase/models"
+	"gowitcase/problem"
+	"gowitcase/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type OrganizerHandler struct {
+	OrganizerService *services.OrganizerService
+}
+
+func NewOrganizerHandler(organizerService *services.OrganizerService) *OrganizerHandler {
+	return &OrganizerHandler{OrganizerService: organizerService}
+}
+
+func (h *OrganizerHandler) CreateOrganizer(ctx *gin.Context) {
+	organizer := &models.Organizer{}
+	if !bindJSON(ctx, organizer) {
+		return
+	}
+
+	err := h.OrganizerService.CreateOrganizer(organizer)
+	if err != nil {
+		problem.Respond(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, organizer)
+}
+
+func (h *OrganizerHandler) RotateAPIKey(ctx *gin.Context) {
+	organizerID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		problem.Respond(ctx, customErrors.NewRestError("Invalid organizer ID", http.StatusBadRequest))
+		return
+	}
+
+	organizer, err := h.OrganizerService.RotateAPIKey(organizerID)
+	if err != nil {
+		problem.Respond(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, organizer)
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	customErrors "gowitcase/errors"
+	"gowitcase/models"
+	"gowitcase/problem"
+	"gowitcase/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PricingRuleHandler struct {
+	PricingRuleService *services.PricingRuleService
+}
+
+func NewPricingRuleHandler(pricingRuleService *services.PricingRuleService) *PricingRuleHandler {
+	return &PricingRuleHandler{PricingRuleService: pricingRuleService}
+}
+
+func (h *PricingRuleHandler) GetPricingRules(ctx *gin.Context) {
+	ticketID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		problem.Respond(ctx, customErrors.NewRestError("Invalid ticket ID", http.StatusBadRequest))
+		return
+	}
+
+	rules, err := h.PricingRuleService.GetPricingRules(ctx.GetInt(models.OrganizerIDKey), ticketID)
+	if err != nil {
+		problem.Respond(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, rules)
+}
+
+func (h *PricingRuleHandler) PutPricingRules(ctx *gin.Context) {
+	ticketID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		problem.Respond(ctx, customErrors.NewRestError("Invalid ticket ID", http.StatusBadRequest))
+		return
+	}
+
+	rules := models.PricingRules{}
+	if !bindJSON(ctx, &rules) {
+		return
+	}
+
+	updated, err := h.PricingRuleService.SetPricingRules(ctx.GetInt(models.OrganizerIDKey), ticketID, rules)
+	if err != nil {
+		problem.Respond(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, updated)
+}
+
+func (h *PricingRuleHandler) GetQuote(ctx *gin.Context) {
+	ticketID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		problem.Respond(ctx, customErrors.NewRestError("Invalid ticket ID", http.StatusBadRequest))
+		return
+	}
+
+	quantity, err := queryInt(ctx, "quantity")
+	if err != nil {
+		problem.Respond(ctx, customErrors.NewRestError("Invalid quantity", http.StatusBadRequest))
+		return
+	}
+
+	quote, err := h.PricingRuleService.Quote(ctx.GetInt(models.OrganizerIDKey), ticketID, quantity)
+	if err != nil {
+		problem.Respond(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, quote)
+}
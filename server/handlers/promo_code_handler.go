@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	customErrors "gowitcase/errors"
+	"gowitcase/models"
+	"gowitcase/problem"
+	"gowitcase/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PromoCodeHandler struct {
+	PromoCodeService *services.PromoCodeService
+}
+
+func NewPromoCodeHandler(promoCodeService *services.PromoCodeService) *PromoCodeHandler {
+	return &PromoCodeHandler{PromoCodeService: promoCodeService}
+}
+
+func (h *PromoCodeHandler) CreatePromoCode(ctx *gin.Context) {
+	promoCode := &models.PromoCode{}
+	if !bindJSON(ctx, promoCode) {
+		return
+	}
+
+	err := h.PromoCodeService.CreatePromoCode(ctx.GetInt(models.OrganizerIDKey), promoCode)
+	if err != nil {
+		problem.Respond(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, promoCode)
+}
+
+func (h *PromoCodeHandler) GetPromoCode(ctx *gin.Context) {
+	promoCodeID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		problem.Respond(ctx, customErrors.NewRestError("Invalid promo code ID", http.StatusBadRequest))
+		return
+	}
+
+	promoCode, err := h.PromoCodeService.GetPromoCode(ctx.GetInt(models.OrganizerIDKey), promoCodeID)
+	if err != nil {
+		problem.Respond(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, promoCode)
+}
+
+func (h *PromoCodeHandler) ListPromoCodes(ctx *gin.Context) {
+	limit, err := queryInt(ctx, "limit")
+	if err != nil {
+		problem.Respond(ctx, customErrors.NewRestError("Invalid limit", http.StatusBadRequest))
+		return
+	}
+
+	list, err := h.PromoCodeService.ListPromoCodes(ctx.GetInt(models.OrganizerIDKey), ctx.Query("cursor"), limit)
+	if err != nil {
+		problem.Respond(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, list)
+}
+
+func (h *PromoCodeHandler) PatchPromoCode(ctx *gin.Context) {
+	promoCodeID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		problem.Respond(ctx, customErrors.NewRestError("Invalid promo code ID", http.StatusBadRequest))
+		return
+	}
+
+	update := models.PromoCodeUpdate{}
+	if !bindJSON(ctx, &update) {
+		return
+	}
+
+	promoCode, err := h.PromoCodeService.UpdatePromoCode(ctx.GetInt(models.OrganizerIDKey), promoCodeID, update)
+	if err != nil {
+		problem.Respond(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, promoCode)
+}
+
+func (h *PromoCodeHandler) ArchivePromoCode(ctx *gin.Context) {
+	promoCodeID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		problem.Respond(ctx, customErrors.NewRestError("Invalid promo code ID", http.StatusBadRequest))
+		return
+	}
+
+	err = h.PromoCodeService.ArchivePromoCode(ctx.GetInt(models.OrganizerIDKey), promoCodeID)
+	if err != nil {
+		problem.Respond(ctx, err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
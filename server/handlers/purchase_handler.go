@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	customErrors "gowitcase/errors"
+	"gowitcase/models"
+	"gowitcase/problem"
+	"gowitcase/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PurchaseHandler struct {
+	PurchaseService *services.PurchaseService
+}
+
+func NewPurchaseHandler(purchaseService *services.PurchaseService) *PurchaseHandler {
+	return &PurchaseHandler{PurchaseService: purchaseService}
+}
+
+func (h *PurchaseHandler) GetPurchase(ctx *gin.Context) {
+	purchaseID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		problem.Respond(ctx, customErrors.NewRestError("Invalid purchase ID", http.StatusBadRequest))
+		return
+	}
+
+	purchase, err := h.PurchaseService.GetPurchase(ctx.GetInt(models.OrganizerIDKey), purchaseID)
+	if err != nil {
+		problem.Respond(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, purchase)
+}
+
+func (h *PurchaseHandler) RefundPurchase(ctx *gin.Context) {
+	purchaseID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		problem.Respond(ctx, customErrors.NewRestError("Invalid purchase ID", http.StatusBadRequest))
+		return
+	}
+
+	refundRequest := &models.RefundRequest{}
+	if !bindJSON(ctx, refundRequest) {
+		return
+	}
+
+	refund, err := h.PurchaseService.RefundPurchase(ctx.GetInt(models.OrganizerIDKey), purchaseID, *refundRequest)
+	if err != nil {
+		problem.Respond(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, refund)
+}
+
+func (h *PurchaseHandler) ListRefunds(ctx *gin.Context) {
+	purchaseID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		problem.Respond(ctx, customErrors.NewRestError("Invalid purchase ID", http.StatusBadRequest))
+		return
+	}
+
+	refunds, err := h.PurchaseService.ListRefunds(ctx.GetInt(models.OrganizerIDKey), purchaseID)
+	if err != nil {
+		problem.Respond(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, refunds)
+}
+
+func (h *PurchaseHandler) ListTicketPurchases(ctx *gin.Context) {
+	ticketID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		problem.Respond(ctx, customErrors.NewRestError("Invalid ticket ID", http.StatusBadRequest))
+		return
+	}
+
+	limit, err := queryInt(ctx, "limit")
+	if err != nil {
+		problem.Respond(ctx, customErrors.NewRestError("Invalid limit", http.StatusBadRequest))
+		return
+	}
+
+	list, err := h.PurchaseService.ListTicketPurchases(ctx.GetInt(models.OrganizerIDKey), ticketID, ctx.Query("cursor"), limit)
+	if err != nil {
+		problem.Respond(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, list)
+}
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	customErrors "gowitcase/errors"
+	"gowitcase/models"
+	"gowitcase/problem"
+	"gowitcase/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SeatHandler struct {
+	SeatService *services.SeatService
+}
+
+func NewSeatHandler(seatService *services.SeatService) *SeatHandler {
+	return &SeatHandler{SeatService: seatService}
+}
+
+func (h *SeatHandler) GetSeatMap(ctx *gin.Context) {
+	ticketID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		problem.Respond(ctx, customErrors.NewRestError("Invalid ticket ID", http.StatusBadRequest))
+		return
+	}
+
+	seatMap, err := h.SeatService.GetSeatMap(ctx.GetInt(models.OrganizerIDKey), ticketID)
+	if err != nil {
+		problem.Respond(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, seatMap)
+}
+
+func (h *SeatHandler) PutSeatMap(ctx *gin.Context) {
+	ticketID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		problem.Respond(ctx, customErrors.NewRestError("Invalid ticket ID", http.StatusBadRequest))
+		return
+	}
+
+	seatMap := models.SeatMap{}
+	if !bindJSON(ctx, &seatMap) {
+		return
+	}
+
+	updated, err := h.SeatService.SetSeatMap(ctx.GetInt(models.OrganizerIDKey), ticketID, seatMap)
+	if err != nil {
+		problem.Respond(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, updated)
+}
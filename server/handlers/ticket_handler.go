@@ -3,10 +3,11 @@ package handlers
 import (
 	customErrors "gowitcase/errors"
 	"gowitcase/models"
+	"gowitcase/problem"
 	"gowitcase/services"
-	"log"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -22,20 +23,13 @@ func NewTicketHandler(ticketService *services.TicketService) *TicketHandler {
 func (h *TicketHandler) CreateTicket(ctx *gin.Context) {
 
 	ticket := &models.Ticket{}
-	if err := ctx.ShouldBindJSON(ticket); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+	if !bindJSON(ctx, ticket) {
 		return
 	}
 
-	err := h.TicketService.CreateTicket(ticket)
+	err := h.TicketService.CreateTicket(ctx.GetInt(models.OrganizerIDKey), ticket)
 	if err != nil {
-		if restErr, ok := err.(customErrors.RestError); ok {
-			ctx.JSON(restErr.Status, gin.H{"error": restErr.Message})
-			return
-		}
-
-		log.Printf("Failed to create ticket with err: %v, Ticket: %+v", err, ticket)
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create ticket"})
+		problem.Respond(ctx, err)
 		return
 	}
 
@@ -46,49 +40,173 @@ func (h *TicketHandler) GetTicket(ctx *gin.Context) {
 	id := ctx.Param("id")
 	ticketID, err := strconv.Atoi(id)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ticket ID"})
+		problem.Respond(ctx, customErrors.NewRestError("Invalid ticket ID", http.StatusBadRequest))
 		return
 	}
 
-	ticket, err := h.TicketService.GetTicket(ticketID)
+	ticket, err := h.TicketService.GetTicket(ctx.GetInt(models.OrganizerIDKey), ticketID)
 	if err != nil {
-		if restErr, ok := err.(customErrors.RestError); ok {
-			ctx.JSON(restErr.Status, gin.H{"error": restErr.Message})
-			return
-		}
-
-		log.Printf("Failed to get ticket with err: %v, ticketID: %d", err, ticketID)
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong, please try again."})
+		problem.Respond(ctx, err)
 		return
 	}
 
 	ctx.JSON(200, ticket)
 }
 
+func (h *TicketHandler) ReplaceTicket(ctx *gin.Context) {
+	ticketID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		problem.Respond(ctx, customErrors.NewRestError("Invalid ticket ID", http.StatusBadRequest))
+		return
+	}
+
+	ticket := &models.Ticket{}
+	if !bindJSON(ctx, ticket) {
+		return
+	}
+
+	update := models.TicketUpdate{
+		Name:           &ticket.Name,
+		Description:    &ticket.Description,
+		MaxPerBuyer:    &ticket.MaxPerBuyer,
+		CapacityPoolID: models.OptionalInt{Set: true, Value: ticket.CapacityPoolID},
+		MinPerOrder:    &ticket.MinPerOrder,
+		MaxPerOrder:    &ticket.MaxPerOrder,
+		QuantityStep:   &ticket.QuantityStep,
+		Price:          &ticket.Price,
+		SaleStartsAt:   models.OptionalTime{Set: true, Value: ticket.SaleStartsAt},
+		SaleEndsAt:     models.OptionalTime{Set: true, Value: ticket.SaleEndsAt},
+	}
+	// A replacement may give either allocation, a sold out ticket read back has
+	// an allocation of 0 and only its total_allocation tells its size.
+	if ticket.TotalAllocation != 0 {
+		update.TotalAllocation = &ticket.TotalAllocation
+	}
+	if ticket.Allocation != 0 || ticket.TotalAllocation == 0 {
+		update.Allocation = &ticket.Allocation
+	}
+
+	h.updateTicket(ctx, ticketID, update)
+}
+
+func (h *TicketHandler) PatchTicket(ctx *gin.Context) {
+	ticketID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		problem.Respond(ctx, customErrors.NewRestError("Invalid ticket ID", http.StatusBadRequest))
+		return
+	}
+
+	update := models.TicketUpdate{}
+	if !bindJSON(ctx, &update) {
+		return
+	}
+
+	h.updateTicket(ctx, ticketID, update)
+}
+
+func (h *TicketHandler) updateTicket(ctx *gin.Context, ticketID int, update models.TicketUpdate) {
+	ticket, err := h.TicketService.UpdateTicket(ctx.GetInt(models.OrganizerIDKey), ticketID, update)
+	if err != nil {
+		problem.Respond(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, ticket)
+}
+
+func (h *TicketHandler) ArchiveTicket(ctx *gin.Context) {
+	ticketID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		problem.Respond(ctx, customErrors.NewRestError("Invalid ticket ID", http.StatusBadRequest))
+		return
+	}
+
+	err = h.TicketService.ArchiveTicket(ctx.GetInt(models.OrganizerIDKey), ticketID)
+	if err != nil {
+		problem.Respond(ctx, err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func (h *TicketHandler) ListTickets(ctx *gin.Context) {
+	params := models.TicketListParams{
+		Name:   ctx.Query("name"),
+		Sort:   ctx.Query("sort"),
+		Order:  ctx.Query("order"),
+		Cursor: ctx.Query("cursor"),
+	}
+
+	var err error
+	if params.Limit, err = queryInt(ctx, "limit"); err != nil {
+		problem.Respond(ctx, customErrors.NewRestError("Invalid limit", http.StatusBadRequest))
+		return
+	}
+	if params.EventID, err = queryInt(ctx, "event_id"); err != nil {
+		problem.Respond(ctx, customErrors.NewRestError("Invalid event_id", http.StatusBadRequest))
+		return
+	}
+	if params.MinAllocation, err = queryInt(ctx, "min_allocation"); err != nil {
+		problem.Respond(ctx, customErrors.NewRestError("Invalid min_allocation", http.StatusBadRequest))
+		return
+	}
+	if params.CreatedFrom, err = queryTime(ctx, "created_from"); err != nil {
+		problem.Respond(ctx, customErrors.NewRestError("Invalid created_from, expected RFC 3339 timestamp", http.StatusBadRequest))
+		return
+	}
+	if params.CreatedTo, err = queryTime(ctx, "created_to"); err != nil {
+		problem.Respond(ctx, customErrors.NewRestError("Invalid created_to, expected RFC 3339 timestamp", http.StatusBadRequest))
+		return
+	}
+
+	list, err := h.TicketService.ListTickets(ctx.GetInt(models.OrganizerIDKey), params)
+	if err != nil {
+		problem.Respond(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, list)
+}
+
 func (h *TicketHandler) PurchaseTicket(ctx *gin.Context) {
 	ticketID, err := strconv.Atoi(ctx.Param("id"))
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ticket ID"})
+		problem.Respond(ctx, customErrors.NewRestError("Invalid ticket ID", http.StatusBadRequest))
 		return
 	}
 
 	purchaseRequest := &models.PurchaseRequest{}
-	if err := ctx.ShouldBindJSON(purchaseRequest); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+	if !bindJSON(ctx, purchaseRequest) {
 		return
 	}
 
-	err = h.TicketService.PurchaseTicket(ticketID, purchaseRequest.Quantity)
+	purchase, err := h.TicketService.PurchaseTicket(ctx.GetInt(models.OrganizerIDKey), ticketID, *purchaseRequest)
 	if err != nil {
-		if restErr, ok := err.(customErrors.RestError); ok {
-			ctx.JSON(restErr.Status, gin.H{"error": restErr.Message})
-			return
-		}
-
-		log.Printf("Failed to purchase ticket with err: %v, purchase: %+v", err, purchaseRequest)
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to purchase ticket"})
+		problem.Respond(ctx, err)
 		return
 	}
 
-	ctx.Status(http.StatusOK)
+	ctx.JSON(http.StatusCreated, purchase)
+}
+
+func queryInt(ctx *gin.Context, key string) (int, error) {
+	value := ctx.Query(key)
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(value)
+}
+
+func queryTime(ctx *gin.Context, key string) (*time.Time, error) {
+	value := ctx.Query(key)
+	if value == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
 }
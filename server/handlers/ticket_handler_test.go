@@ -0,0 +1,51 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"fmt"
+	customErrors "gowitcase/errors"
+	"gowitcase/handlers"
+	"gowitcase/middleware"
+	"gowitcase/mocks"
+	"gowitcase/problem"
+	"gowitcase/services"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetTicket_UnexpectedError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockDB, mock, err := mocks.NewMockDatabase()
+	assert.NoError(t, err)
+
+	ticketHandler := handlers.NewTicketHandler(services.NewTicketService(mockDB, mocks.NewMockRedis()))
+
+	router := gin.New()
+	router.Use(middleware.RequestIDMiddleware())
+	router.GET("/tickets/:id", ticketHandler.GetTicket)
+
+	mock.ExpectQuery("SELECT (.+) FROM ticket WHERE id = ").
+		WillReturnError(fmt.Errorf("pq: connection refused"))
+
+	req := httptest.NewRequest(http.MethodGet, "/tickets/1", nil)
+	req.Header.Set(middleware.RequestIDHeader, "req-1")
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	assert.Equal(t, problem.ContentType, recorder.Header().Get("Content-Type"))
+
+	var p problem.Problem
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &p), "expected a problem")
+	assert.Equal(t, customErrors.CodeInternal, p.Code)
+	assert.Equal(t, "Something went wrong, please try again.", p.Detail, "expected the cause to stay out of the response")
+	assert.Equal(t, "req-1", p.RequestID, "expected the ID of the request")
+	assert.Equal(t, "/tickets/1", p.Instance)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	customErrors "gowitcase/errors"
+	"gowitcase/models"
+	"gowitcase/problem"
+	"gowitcase/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TicketInstanceHandler struct {
+	TicketInstanceService *services.TicketInstanceService
+}
+
+func NewTicketInstanceHandler(ticketInstanceService *services.TicketInstanceService) *TicketInstanceHandler {
+	return &TicketInstanceHandler{TicketInstanceService: ticketInstanceService}
+}
+
+func (h *TicketInstanceHandler) ListPurchaseInstances(ctx *gin.Context) {
+	purchaseID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		problem.Respond(ctx, customErrors.NewRestError("Invalid purchase ID", http.StatusBadRequest))
+		return
+	}
+
+	list, err := h.TicketInstanceService.ListPurchaseInstances(ctx.GetInt(models.OrganizerIDKey), purchaseID)
+	if err != nil {
+		problem.Respond(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, list)
+}
+
+func (h *TicketInstanceHandler) GetTicketInstance(ctx *gin.Context) {
+	instanceID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		problem.Respond(ctx, customErrors.NewRestError("Invalid ticket instance ID", http.StatusBadRequest))
+		return
+	}
+
+	instance, err := h.TicketInstanceService.GetInstance(ctx.GetInt(models.OrganizerIDKey), instanceID)
+	if err != nil {
+		problem.Respond(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, instance)
+}
+
+func (h *TicketInstanceHandler) GetTicketInstanceQRCode(ctx *gin.Context) {
+	instanceID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		problem.Respond(ctx, customErrors.NewRestError("Invalid ticket instance ID", http.StatusBadRequest))
+		return
+	}
+
+	size, err := queryInt(ctx, "size")
+	if err != nil {
+		problem.Respond(ctx, customErrors.NewRestError("Invalid size", http.StatusBadRequest))
+		return
+	}
+
+	png, err := h.TicketInstanceService.QRCode(ctx.GetInt(models.OrganizerIDKey), instanceID, size)
+	if err != nil {
+		problem.Respond(ctx, err)
+		return
+	}
+
+	ctx.Data(http.StatusOK, "image/png", png)
+}
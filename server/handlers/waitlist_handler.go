@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	customErrors "gowitcase/errors"
+	"gowitcase/models"
+	"gowitcase/problem"
+	"gowitcase/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WaitlistHandler struct {
+	WaitlistService *services.WaitlistService
+}
+
+func NewWaitlistHandler(waitlistService *services.WaitlistService) *WaitlistHandler {
+	return &WaitlistHandler{WaitlistService: waitlistService}
+}
+
+func (h *WaitlistHandler) JoinWaitlist(ctx *gin.Context) {
+	ticketID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		problem.Respond(ctx, customErrors.NewRestError("Invalid ticket ID", http.StatusBadRequest))
+		return
+	}
+
+	waitlistRequest := &models.WaitlistRequest{}
+	if !bindJSON(ctx, waitlistRequest) {
+		return
+	}
+
+	entry, err := h.WaitlistService.JoinWaitlist(ctx.GetInt(models.OrganizerIDKey), ticketID, *waitlistRequest)
+	if err != nil {
+		problem.Respond(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, entry)
+}
+
+func (h *WaitlistHandler) GetWaitlistEntry(ctx *gin.Context) {
+	entryID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		problem.Respond(ctx, customErrors.NewRestError("Invalid waitlist entry ID", http.StatusBadRequest))
+		return
+	}
+
+	entry, err := h.WaitlistService.GetWaitlistEntry(ctx.GetInt(models.OrganizerIDKey), entryID)
+	if err != nil {
+		problem.Respond(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, entry)
+}
+
+func (h *WaitlistHandler) LeaveWaitlist(ctx *gin.Context) {
+	entryID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		problem.Respond(ctx, customErrors.NewRestError("Invalid waitlist entry ID", http.StatusBadRequest))
+		return
+	}
+
+	err = h.WaitlistService.LeaveWaitlist(ctx.GetInt(models.OrganizerIDKey), entryID)
+	if err != nil {
+		problem.Respond(ctx, err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	customErrors "gowitcase/errors"
+	"gowitcase/problem"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminMiddleware lets through the requests carrying the admin API key. Every
+// request is rejected when no admin key is configured.
+func AdminMiddleware(adminKey string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(APIKeyHeader)
+		if adminKey == "" || subtle.ConstantTimeCompare([]byte(key), []byte(adminKey)) != 1 {
+			problem.Respond(c, customErrors.NewRestError("Invalid API key", http.StatusUnauthorized))
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,145 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	customErrors "gowitcase/errors"
+	"gowitcase/models"
+	"gowitcase/problem"
+	"gowitcase/services"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+type responseRecorder struct {
+	gin.ResponseWriter
+	body *bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(data string) (int, error) {
+	w.body.WriteString(data)
+	return w.ResponseWriter.WriteString(data)
+}
+
+// IdempotencyMiddleware replays the stored response when a request is retried with
+// the same Idempotency-Key, and rejects a key reused for a different request.
+// Requests without the header are passed through. It runs after
+// OrganizerMiddleware, keys are per organizer.
+func IdempotencyMiddleware(idempotencyService *services.IdempotencyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+
+		if len(key) > 255 {
+			problem.Respond(c, customErrors.NewRestError("Idempotency-Key must be less than 255 characters", http.StatusBadRequest))
+			return
+		}
+
+		// Keys are per organizer, so one organizer can't replay another's response.
+		key = fmt.Sprintf("%d:%s", c.GetInt(models.OrganizerIDKey), key)
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			problem.Respond(c, customErrors.NewRestError("Invalid request", http.StatusBadRequest))
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		fingerprint := requestFingerprint(c.Request.Method, c.Request.URL.Path, body)
+
+		record, token, err := idempotencyService.Begin(key, fingerprint)
+		if err != nil {
+			log.Printf("Failed to begin idempotent request with err: %v, key: %s", err, key)
+			problem.Respond(c, customErrors.NewRestError("Something went wrong, please try again.", http.StatusInternalServerError))
+			return
+		}
+
+		if record != nil {
+			if record.Fingerprint != fingerprint {
+				problem.Respond(c, customErrors.NewRestError("Idempotency-Key was already used for a different request", http.StatusUnprocessableEntity).
+					WithCode(customErrors.CodeIdempotencyKeyReused))
+				return
+			}
+
+			if !record.IsCompleted() {
+				problem.Respond(c, customErrors.NewRestError("A request with this Idempotency-Key is still in progress", http.StatusConflict).
+					WithCode(customErrors.CodeIdempotencyKeyInProgress))
+				return
+			}
+
+			// A replayed error keeps the request ID of the request that failed.
+			contentType := "application/json; charset=utf-8"
+			if record.StatusCode >= http.StatusBadRequest {
+				contentType = problem.ContentType
+			}
+
+			c.Header("Idempotent-Replayed", "true")
+			c.Data(record.StatusCode, contentType, []byte(record.ResponseBody))
+			c.Abort()
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer, body: &bytes.Buffer{}}
+		c.Writer = recorder
+
+		// The key is released whenever no response is stored for it, even when the
+		// handler panics and the recovery middleware answers instead.
+		completed := false
+		defer func() {
+			if completed {
+				return
+			}
+			if err := idempotencyService.Release(key, token); err != nil {
+				log.Printf("Failed to release idempotency key with err: %v, key: %s", err, key)
+			}
+		}()
+
+		c.Next()
+
+		// Server errors aren't replayed, the client should be able to retry them.
+		if c.Writer.Status() >= http.StatusInternalServerError {
+			return
+		}
+
+		if err := idempotencyService.Complete(key, token, c.Writer.Status(), recorder.body.String()); err != nil {
+			log.Printf("Failed to complete idempotency key with err: %v, key: %s", err, key)
+			return
+		}
+		completed = true
+	}
+}
+
+// requestFingerprint identifies a request by its method, path and body. JSON bodies
+// are normalized first, so formatting and key order don't change the fingerprint.
+func requestFingerprint(method string, path string, body []byte) string {
+	var payload interface{}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&payload); err == nil {
+		if normalized, err := json.Marshal(payload); err == nil {
+			body = normalized
+		}
+	}
+
+	hash := sha256.New()
+	hash.Write([]byte(method + " " + path + "\n"))
+	hash.Write(body)
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
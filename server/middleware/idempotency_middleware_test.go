@@ -0,0 +1,114 @@
+package middleware_test
+
+import (
+	"fmt"
+	"gowitcase/middleware"
+	"gowitcase/mocks"
+	"gowitcase/models"
+	"gowitcase/problem"
+	"gowitcase/services"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupIdempotencyRouter(t *testing.T, handler gin.HandlerFunc) (*gin.Engine, sqlmock.Sqlmock) {
+	gin.SetMode(gin.TestMode)
+
+	mockDB, mock, err := mocks.NewMockDatabase()
+	assert.NoError(t, err)
+
+	idempotencyService := services.NewIdempotencyService(mockDB, mocks.NewMockRedis())
+
+	router := gin.New()
+	router.Use(gin.CustomRecovery(func(c *gin.Context, recovered interface{}) {
+		problem.Respond(c, fmt.Errorf("panic: %v", recovered))
+	}))
+	// Stands in for OrganizerMiddleware, the organizer is taken from a header.
+	organizerAuth := func(c *gin.Context) {
+		var organizerID int
+		fmt.Sscan(c.GetHeader("X-Organizer"), &organizerID)
+		c.Set(models.OrganizerIDKey, organizerID)
+	}
+	router.POST("/purchases", organizerAuth, middleware.IdempotencyMiddleware(idempotencyService), handler)
+
+	return router, mock
+}
+
+func postPurchase(router *gin.Engine, organizerID int, key string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/purchases", strings.NewReader(`{"quantity":1}`))
+	req.Header.Set("X-Organizer", fmt.Sprint(organizerID))
+	req.Header.Set(middleware.IdempotencyKeyHeader, key)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestIdempotencyMiddleware_ReleasesKeyOnPanic(t *testing.T) {
+	router, mock := setupIdempotencyRouter(t, func(c *gin.Context) {
+		panic("boom")
+	})
+
+	mock.ExpectQuery("INSERT INTO idempotency_key").
+		WithArgs("9:key", sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"key"}).AddRow("9:key"))
+
+	mock.ExpectExec("DELETE FROM idempotency_key WHERE key = (.+) AND token = (.+) AND status_code IS NULL").
+		WithArgs("9:key", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	recorder := postPurchase(router, 9, "key")
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code, "expected the recovered panic")
+
+	assert.NoError(t, mock.ExpectationsWereMet(), "expected the key to be released")
+}
+
+func TestIdempotencyMiddleware_ReleasesKeyOnServerError(t *testing.T) {
+	router, mock := setupIdempotencyRouter(t, func(c *gin.Context) {
+		c.Status(http.StatusServiceUnavailable)
+	})
+
+	mock.ExpectQuery("INSERT INTO idempotency_key").
+		WithArgs("9:key", sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"key"}).AddRow("9:key"))
+
+	mock.ExpectExec("DELETE FROM idempotency_key").
+		WithArgs("9:key", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	recorder := postPurchase(router, 9, "key")
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+
+	assert.NoError(t, mock.ExpectationsWereMet(), "expected the key to be released")
+}
+
+func TestIdempotencyMiddleware_KeysPerOrganizer(t *testing.T) {
+	router, mock := setupIdempotencyRouter(t, func(c *gin.Context) {
+		c.JSON(http.StatusCreated, gin.H{"organizer_id": c.GetInt(models.OrganizerIDKey)})
+	})
+
+	// The same key sent by two organizers reserves two keys, the second organizer
+	// doesn't get the response of the first.
+	for _, organizerID := range []int{1, 2} {
+		key := fmt.Sprintf("%d:key", organizerID)
+		mock.ExpectQuery("INSERT INTO idempotency_key").
+			WithArgs(key, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"key"}).AddRow(key))
+		mock.ExpectQuery("UPDATE idempotency_key SET status_code").
+			WithArgs(http.StatusCreated, fmt.Sprintf(`{"organizer_id":%d}`, organizerID), sqlmock.AnyArg(), key, sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"fingerprint", "expires_at"}).AddRow("fingerprint", time.Now().Add(time.Hour)))
+
+		recorder := postPurchase(router, organizerID, "key")
+		assert.Equal(t, http.StatusCreated, recorder.Code)
+		assert.Empty(t, recorder.Header().Get("Idempotent-Replayed"), "expected a new response")
+	}
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
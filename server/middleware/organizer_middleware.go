@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"gowitcase/models"
+	"gowitcase/problem"
+	"gowitcase/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+const APIKeyHeader = "X-API-Key"
+
+// OrganizerMiddleware authenticates the organizer by the API key of the request and
+// stores its ID in the context under models.OrganizerIDKey.
+func OrganizerMiddleware(organizerService *services.OrganizerService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		organizerID, err := organizerService.Authenticate(c.GetHeader(APIKeyHeader))
+		if err != nil {
+			problem.Respond(c, err)
+			return
+		}
+
+		c.Set(models.OrganizerIDKey, organizerID)
+		c.Next()
+	}
+}
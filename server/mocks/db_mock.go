@@ -23,6 +23,10 @@ func (m *MockDatabase) QueryRow(query string, args ...interface{}) *sql.Row {
 	return m.client.QueryRow(query, args...)
 }
 
+func (m *MockDatabase) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return m.client.Query(query, args...)
+}
+
 func (m *MockDatabase) Exec(query string, args ...interface{}) (sql.Result, error) {
 	return m.client.Exec(query, args...)
 }
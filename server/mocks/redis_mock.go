@@ -2,18 +2,23 @@ package mocks
 
 import (
 	"errors"
+	"gowitcase/db"
+	"sync"
 	"time"
 )
 
 type MockRedis struct {
-	data      map[string]string
-	isHealthy bool
+	data          map[string]string
+	isHealthy     bool
+	mu            sync.Mutex
+	subscriptions map[string][]*mockSubscription
 }
 
 func NewMockRedis() *MockRedis {
 	return &MockRedis{
-		data:      make(map[string]string),
-		isHealthy: true,
+		data:          make(map[string]string),
+		isHealthy:     true,
+		subscriptions: make(map[string][]*mockSubscription),
 	}
 }
 
@@ -58,3 +63,50 @@ func (m *MockRedis) Del(key string) error {
 	delete(m.data, key)
 	return nil
 }
+
+func (m *MockRedis) Publish(channel string, message string) error {
+	if !m.isHealthy {
+		return errors.New("redis server is not healthy")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, subscription := range m.subscriptions[channel] {
+		subscription.messages <- message
+	}
+	return nil
+}
+
+// Subscribe buffers the messages published to the channel, so tests can publish
+// before reading them.
+func (m *MockRedis) Subscribe(channel string) (db.Subscription, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	subscription := &mockSubscription{redis: m, channel: channel, messages: make(chan string, 100)}
+	m.subscriptions[channel] = append(m.subscriptions[channel], subscription)
+	return subscription, nil
+}
+
+type mockSubscription struct {
+	redis    *MockRedis
+	channel  string
+	messages chan string
+}
+
+func (s *mockSubscription) Messages() <-chan string {
+	return s.messages
+}
+
+func (s *mockSubscription) Close() error {
+	s.redis.mu.Lock()
+	defer s.redis.mu.Unlock()
+	subscriptions := s.redis.subscriptions[s.channel]
+	for i, subscription := range subscriptions {
+		if subscription == s {
+			s.redis.subscriptions[s.channel] = append(subscriptions[:i], subscriptions[i+1:]...)
+			close(s.messages)
+			break
+		}
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package models
+
+// AvailabilityChannel is the Redis channel every inventory change of a ticket is
+// published to, so each API instance can update the streams it serves.
+const AvailabilityChannel = "tickets:availability"
+
+// Availability is the part of a ticket that changes as it sells.
+type Availability struct {
+	TicketID   int    `json:"ticket_id"`
+	Available  int    `json:"available"`
+	Held       int    `json:"held"`
+	Sold       int    `json:"sold"`
+	SaleStatus string `json:"sale_status"`
+}
+
+// AvailabilityChange is the message published when the inventory of a ticket changed.
+type AvailabilityChange struct {
+	OrganizerID int `json:"organizer_id"`
+	TicketID    int `json:"ticket_id"`
+}
+
+// Availability is the availability of the ticket, as of its SaleStatus.
+func (t *Ticket) Availability() Availability {
+	return Availability{
+		TicketID:   t.ID,
+		Available:  t.Allocation,
+		Held:       t.Held,
+		Sold:       t.Sold,
+		SaleStatus: t.SaleStatus,
+	}
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// CapacityPool is a physical capacity shared by several tickets, such as the
+// standing and seated tickets of one venue. Used counts the held and sold tickets
+// of all its tickets, and never goes over Capacity, whatever their allocations.
+type CapacityPool struct {
+	ID          int       `json:"id"`
+	OrganizerID int       `json:"organizer_id"`
+	Name        string    `json:"name"`
+	Capacity    int       `json:"capacity"`
+	Used        int       `json:"used"`
+	Available   int       `json:"available"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// CapacityPoolUpdate is a partial capacity pool update, nil fields are left unchanged.
+type CapacityPoolUpdate struct {
+	Name     *string `json:"name"`
+	Capacity *int    `json:"capacity"`
+}
@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+const CheckInCountCachePrefix = "checkins:count:"
+
+// CheckInGateMaxLength is the longest gate name a check-in records.
+const CheckInGateMaxLength = 64
+
+// CheckIn records a ticket instance being admitted at a gate.
+type CheckIn struct {
+	ID               int       `json:"id"`
+	TicketInstanceID int       `json:"ticket_instance_id"`
+	TicketID         int       `json:"ticket_id"`
+	PurchaseID       int       `json:"purchase_id"`
+	SeatID           *int      `json:"seat_id,omitempty"`
+	Gate             string    `json:"gate"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// CheckInRequest carries the token scanned from a ticket instance's QR code.
+type CheckInRequest struct {
+	Token string `json:"token"`
+	Gate  string `json:"gate"`
+}
+
+type CheckInCount struct {
+	TicketID  int `json:"ticket_id"`
+	CheckedIn int `json:"checked_in"`
+}
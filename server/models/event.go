@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+const EventCachePrefix = "event:"
+
+const (
+	EventListDefaultLimit = 20
+	EventListMaxLimit     = 100
+)
+
+// Event groups the ticket types sold for it, e.g. GA, VIP and student tickets.
+// TicketTypes is only filled in when a single event is fetched.
+type Event struct {
+	ID          int        `json:"id"`
+	OrganizerID int        `json:"organizer_id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Venue       string     `json:"venue"`
+	StartsAt    *time.Time `json:"starts_at,omitempty"`
+	EndsAt      *time.Time `json:"ends_at,omitempty"`
+	TicketTypes []Ticket   `json:"ticket_types,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	ArchivedAt  *time.Time `json:"archived_at,omitempty"`
+}
+
+// EventUpdate is a partial event update, nil fields are left unchanged.
+type EventUpdate struct {
+	Name        *string      `json:"name"`
+	Description *string      `json:"description"`
+	Venue       *string      `json:"venue"`
+	StartsAt    OptionalTime `json:"starts_at"`
+	EndsAt      OptionalTime `json:"ends_at"`
+}
+
+type EventList struct {
+	Events     []Event `json:"events"`
+	NextCursor string  `json:"next_cursor,omitempty"`
+}
@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+const HoldDuration = 10 * time.Minute
+
+const (
+	HoldStatusActive    = "active"
+	HoldStatusConfirmed = "confirmed"
+	HoldStatusReleased  = "released"
+	HoldStatusExpired   = "expired"
+)
+
+// Hold reserves tickets for a buyer until ExpiresAt. Held tickets are taken out of
+// the ticket's allocation and go back to it unless the hold is confirmed in time.
+type Hold struct {
+	ID         int       `json:"id"`
+	TicketID   int       `json:"ticket_id"`
+	BuyerID    string    `json:"buyer_id"`
+	Quantity   int       `json:"quantity"`
+	UnitPrice  Money     `json:"unit_price"`
+	Status     string    `json:"status"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	PurchaseID *int      `json:"purchase_id,omitempty"`
+	SeatIDs    []int     `json:"seat_ids,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// HoldRequest holds Quantity tickets, or the SeatIDs of a seated ticket.
+type HoldRequest struct {
+	BuyerID  string `json:"buyer_id"`
+	Quantity int    `json:"quantity"`
+	SeatIDs  []int  `json:"seat_ids,omitempty"`
+}
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+const IdempotencyCachePrefix = "idempotency:"
+
+// IdempotencyReservationTTL is how long a key stays reserved by a request that
+// never stored a response, such as one cut short by a crash. A retry after it
+// runs the request again.
+const IdempotencyReservationTTL = 5 * time.Minute
+
+// IdempotencyResponseTTL is how long the response stored for a key is replayed,
+// the key can be used for a new request after it.
+const IdempotencyResponseTTL = 24 * time.Hour
+
+// IdempotencyRecord is the stored outcome of a request sent with an Idempotency-Key.
+// StatusCode is 0 while the first request with the key is still being processed.
+type IdempotencyRecord struct {
+	Key          string    `json:"key"`
+	Fingerprint  string    `json:"fingerprint"`
+	StatusCode   int       `json:"status_code"`
+	ResponseBody string    `json:"response_body"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+func (r *IdempotencyRecord) IsCompleted() bool {
+	return r.StatusCode != 0
+}
@@ -0,0 +1,128 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+)
+
+const DefaultCurrency = "EUR"
+
+var (
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+	ErrAmountOverflow   = errors.New("amount is too large")
+	ErrUnknownCurrency  = errors.New("unknown currency")
+)
+
+// currencyExponents lists the supported ISO 4217 currencies with the number of
+// minor units in one major unit, as a power of ten.
+var currencyExponents = map[string]int{
+	"AUD": 2, "BRL": 2, "CAD": 2, "CHF": 2, "CNY": 2, "CZK": 2, "DKK": 2, "EUR": 2,
+	"GBP": 2, "HUF": 2, "INR": 2, "JPY": 0, "KRW": 0, "MXN": 2, "NOK": 2, "NZD": 2,
+	"PLN": 2, "SEK": 2, "TRY": 2, "USD": 2, "ZAR": 2,
+}
+
+// Money is an amount in the minor units of its currency, e.g. cents for EUR.
+// All arithmetic on prices goes through it, so rounding and currency checks
+// happen in one place.
+type Money struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+func NewMoney(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+func IsKnownCurrency(currency string) bool {
+	_, ok := currencyExponents[currency]
+	return ok
+}
+
+func (m Money) Validate() error {
+	if !IsKnownCurrency(m.Currency) {
+		return ErrUnknownCurrency
+	}
+	if m.Amount < 0 {
+		return fmt.Errorf("amount must not be negative")
+	}
+	return nil
+}
+
+func (m Money) Add(other Money) (Money, error) {
+	if m.Currency != other.Currency {
+		return Money{}, ErrCurrencyMismatch
+	}
+	if (other.Amount > 0 && m.Amount > math.MaxInt64-other.Amount) ||
+		(other.Amount < 0 && m.Amount < math.MinInt64-other.Amount) {
+		return Money{}, ErrAmountOverflow
+	}
+	return Money{Amount: m.Amount + other.Amount, Currency: m.Currency}, nil
+}
+
+func (m Money) Sub(other Money) (Money, error) {
+	if other.Amount == math.MinInt64 {
+		return Money{}, ErrAmountOverflow
+	}
+	return m.Add(Money{Amount: -other.Amount, Currency: other.Currency})
+}
+
+func (m Money) Mul(quantity int64) (Money, error) {
+	product := new(big.Int).Mul(big.NewInt(m.Amount), big.NewInt(quantity))
+	if !product.IsInt64() {
+		return Money{}, ErrAmountOverflow
+	}
+	return Money{Amount: product.Int64(), Currency: m.Currency}, nil
+}
+
+// Percent returns basisPoints hundredths of a percent of m, rounded half away
+// from zero to the nearest minor unit. 2500 basis points are 25%.
+func (m Money) Percent(basisPoints int64) (Money, error) {
+	product := new(big.Int).Mul(big.NewInt(m.Amount), big.NewInt(basisPoints))
+
+	quotient, remainder := new(big.Int).QuoRem(product, big.NewInt(10000), new(big.Int))
+	if new(big.Int).Abs(remainder).Cmp(big.NewInt(5000)) >= 0 {
+		if product.Sign() < 0 {
+			quotient.Sub(quotient, big.NewInt(1))
+		} else {
+			quotient.Add(quotient, big.NewInt(1))
+		}
+	}
+
+	if !quotient.IsInt64() {
+		return Money{}, ErrAmountOverflow
+	}
+	return Money{Amount: quotient.Int64(), Currency: m.Currency}, nil
+}
+
+// Share returns part/whole of m, rounded towards zero. Taking the difference of
+// two consecutive shares splits m into parts that always add up to m exactly.
+func (m Money) Share(part, whole int64) (Money, error) {
+	if whole <= 0 || part < 0 || part > whole {
+		return Money{}, fmt.Errorf("invalid share %d/%d", part, whole)
+	}
+
+	product := new(big.Int).Mul(big.NewInt(m.Amount), big.NewInt(part))
+	quotient := new(big.Int).Quo(product, big.NewInt(whole))
+	return Money{Amount: quotient.Int64(), Currency: m.Currency}, nil
+}
+
+func (m Money) String() string {
+	exponent := currencyExponents[m.Currency]
+	if exponent == 0 {
+		return fmt.Sprintf("%d %s", m.Amount, m.Currency)
+	}
+
+	sign := ""
+	amount := new(big.Int).SetInt64(m.Amount)
+	if amount.Sign() < 0 {
+		sign = "-"
+		amount.Neg(amount)
+	}
+
+	unit := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exponent)), nil)
+	major, minor := new(big.Int).QuoRem(amount, unit, new(big.Int))
+
+	return fmt.Sprintf("%s%s.%0*d %s", sign, major.String(), exponent, minor.Int64(), m.Currency)
+}
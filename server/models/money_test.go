@@ -0,0 +1,72 @@
+package models_test
+
+import (
+	"gowitcase/models"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMoney_Add(t *testing.T) {
+	total, err := models.NewMoney(1050, "EUR").Add(models.NewMoney(250, "EUR"))
+	assert.NoError(t, err, "failed to add money")
+	assert.Equal(t, models.NewMoney(1300, "EUR"), total, "expected sum to match")
+
+	_, err = models.NewMoney(1050, "EUR").Add(models.NewMoney(250, "USD"))
+	assert.ErrorIs(t, err, models.ErrCurrencyMismatch, "expected error when currencies differ")
+
+	_, err = models.NewMoney(math.MaxInt64, "EUR").Add(models.NewMoney(1, "EUR"))
+	assert.ErrorIs(t, err, models.ErrAmountOverflow, "expected error when sum overflows")
+}
+
+func TestMoney_Mul(t *testing.T) {
+	total, err := models.NewMoney(1999, "EUR").Mul(3)
+	assert.NoError(t, err, "failed to multiply money")
+	assert.Equal(t, models.NewMoney(5997, "EUR"), total, "expected product to match")
+
+	_, err = models.NewMoney(math.MaxInt64/2, "EUR").Mul(3)
+	assert.ErrorIs(t, err, models.ErrAmountOverflow, "expected error when product overflows")
+}
+
+func TestMoney_Percent(t *testing.T) {
+	tests := []struct {
+		amount      int64
+		basisPoints int64
+		expected    int64
+	}{
+		{1000, 1000, 100},
+		{999, 1000, 100},
+		{994, 1000, 99},
+		{995, 1000, 100},
+		{-995, 1000, -100},
+		{1, 5000, 1},
+	}
+
+	for _, test := range tests {
+		result, err := models.NewMoney(test.amount, "EUR").Percent(test.basisPoints)
+		assert.NoError(t, err, "failed to compute percentage")
+		assert.Equal(t, test.expected, result.Amount, "expected rounded percentage of %d", test.amount)
+	}
+}
+
+func TestMoney_String(t *testing.T) {
+	assert.Equal(t, "12.05 EUR", models.NewMoney(1205, "EUR").String())
+	assert.Equal(t, "-0.50 USD", models.NewMoney(-50, "USD").String())
+	assert.Equal(t, "1500 JPY", models.NewMoney(1500, "JPY").String())
+}
+
+func TestMoney_Share(t *testing.T) {
+	total := models.NewMoney(1000, "EUR")
+
+	first, err := total.Share(1, 3)
+	assert.NoError(t, err, "failed to share money")
+	assert.Equal(t, int64(333), first.Amount, "expected share to be rounded down")
+
+	rest, err := total.Share(3, 3)
+	assert.NoError(t, err, "failed to share money")
+	assert.Equal(t, total, rest, "expected full share to match")
+
+	_, err = total.Share(4, 3)
+	assert.Error(t, err, "expected error when part exceeds whole")
+}
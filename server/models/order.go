@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// OrderMaxLines is the most tickets a single order can buy.
+const OrderMaxLines = 20
+
+// Order is a checkout of several tickets at once, with one purchase per line.
+// Either every line is purchased or none is.
+type Order struct {
+	ID          int        `json:"id"`
+	OrganizerID int        `json:"organizer_id"`
+	BuyerID     string     `json:"buyer_id"`
+	Purchases   []Purchase `json:"purchases"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+type OrderRequest struct {
+	BuyerID string             `json:"buyer_id"`
+	Lines   []OrderLineRequest `json:"lines"`
+}
+
+// OrderLineRequest buys Quantity of a ticket, or its SeatIDs, like a PurchaseRequest.
+type OrderLineRequest struct {
+	TicketID  int    `json:"ticket_id"`
+	Quantity  int    `json:"quantity"`
+	SeatIDs   []int  `json:"seat_ids,omitempty"`
+	PromoCode string `json:"promo_code,omitempty"`
+}
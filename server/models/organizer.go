@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// OrganizerIDKey is the request context key holding the ID of the organizer whose
+// API key authenticated the request.
+const OrganizerIDKey = "organizer_id"
+
+const OrganizerKeyCachePrefix = "organizer:key:"
+
+// Organizer is a promoter selling tickets on the deployment. Its tickets are only
+// visible to requests made with its API key. APIKey is only returned when a key
+// is issued, the organizer stores a hash of it.
+type Organizer struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	APIKey    string    `json:"api_key,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// PricingRulesMax caps the number of pricing rules of a single ticket type.
+const PricingRulesMax = 20
+
+// PricingRule changes the price of a ticket by ChangeBps once SoldBps of its total
+// allocation is sold. Both are in basis points, so a rule of 5000 and 1000 adds 10%
+// to the price after half the tickets are sold.
+// Only the rule with the highest threshold reached applies, rules don't add up.
+type PricingRule struct {
+	ID        int       `json:"id"`
+	SoldBps   int64     `json:"sold_bps"`
+	ChangeBps int64     `json:"change_bps"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// PricingRules are all the pricing rules of a ticket, ordered by threshold.
+type PricingRules struct {
+	TicketID int           `json:"ticket_id"`
+	Rules    []PricingRule `json:"rules"`
+}
+
+// PriceQuote previews what buying Quantity tickets would cost right now. Rule is
+// the pricing rule in effect, if any.
+type PriceQuote struct {
+	TicketID  int          `json:"ticket_id"`
+	Quantity  int          `json:"quantity"`
+	BasePrice Money        `json:"base_price"`
+	UnitPrice Money        `json:"unit_price"`
+	Total     Money        `json:"total"`
+	Rule      *PricingRule `json:"rule,omitempty"`
+}
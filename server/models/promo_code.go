@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+const (
+	PromoCodeTypePercentage = "percentage"
+	PromoCodeTypeFixed      = "fixed"
+)
+
+const (
+	PromoCodeListDefaultLimit = 20
+	PromoCodeListMaxLimit     = 100
+)
+
+// PromoCode discounts a purchase, either by DiscountBps basis points of the order
+// or by the fixed AmountOff. A code scoped to TicketID only applies to that ticket,
+// and MaxUses of 0 means the code can be redeemed any number of times.
+type PromoCode struct {
+	ID          int        `json:"id"`
+	OrganizerID int        `json:"organizer_id"`
+	Code        string     `json:"code"`
+	Type        string     `json:"type"`
+	DiscountBps int64      `json:"discount_bps,omitempty"`
+	AmountOff   *Money     `json:"amount_off,omitempty"`
+	TicketID    *int       `json:"ticket_id,omitempty"`
+	MaxUses     int        `json:"max_uses"`
+	UsedCount   int        `json:"used_count"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	ArchivedAt  *time.Time `json:"archived_at,omitempty"`
+}
+
+// PromoCodeUpdate is a partial promo code update, nil fields are left unchanged.
+// The code and its discount can't change once it may have been redeemed.
+type PromoCodeUpdate struct {
+	MaxUses   *int         `json:"max_uses"`
+	ExpiresAt OptionalTime `json:"expires_at"`
+}
+
+type PromoCodeList struct {
+	PromoCodes []PromoCode `json:"promo_codes"`
+	NextCursor string      `json:"next_cursor,omitempty"`
+}
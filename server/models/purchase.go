@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+const (
+	PurchaseListDefaultLimit = 20
+	PurchaseListMaxLimit     = 100
+)
+
+type Purchase struct {
+	ID               int       `json:"id"`
+	TicketID         int       `json:"ticket_id"`
+	BuyerID          string    `json:"buyer_id"`
+	Quantity         int       `json:"quantity"`
+	RefundedQuantity int       `json:"refunded_quantity"`
+	UnitPrice        Money     `json:"unit_price"`
+	Discount         Money     `json:"discount"`
+	Total            Money     `json:"total"`
+	PromoCodeID      *int      `json:"promo_code_id,omitempty"`
+	SeatIDs          []int     `json:"seat_ids,omitempty"`
+	OrderID          *int      `json:"order_id,omitempty"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// PurchaseRequest buys Quantity tickets. Seated tickets are bought by SeatIDs
+// instead, and Quantity can be left out.
+type PurchaseRequest struct {
+	BuyerID   string `json:"buyer_id"`
+	Quantity  int    `json:"quantity"`
+	SeatIDs   []int  `json:"seat_ids,omitempty"`
+	PromoCode string `json:"promo_code,omitempty"`
+}
+
+type PurchaseList struct {
+	Purchases  []Purchase `json:"purchases"`
+	NextCursor string     `json:"next_cursor,omitempty"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+type Refund struct {
+	ID         int       `json:"id"`
+	PurchaseID int       `json:"purchase_id"`
+	Quantity   int       `json:"quantity"`
+	Amount     Money     `json:"amount"`
+	Reason     string    `json:"reason"`
+	RefundedBy string    `json:"refunded_by"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// RefundRequest refunds Quantity tickets of a purchase, or cancels everything
+// not refunded yet when Quantity is 0. Part of a seated purchase is refunded by
+// SeatIDs, which go back on sale.
+type RefundRequest struct {
+	Quantity   int    `json:"quantity"`
+	SeatIDs    []int  `json:"seat_ids,omitempty"`
+	Reason     string `json:"reason"`
+	RefundedBy string `json:"refunded_by"`
+}
@@ -0,0 +1,5 @@
+package models
+
+// RequestIDKey is the request context key holding the ID of the request, which is
+// returned in the X-Request-ID header and in every error.
+const RequestIDKey = "request_id"
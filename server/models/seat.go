@@ -0,0 +1,37 @@
+package models
+
+const (
+	SeatStatusAvailable = "available"
+	SeatStatusHeld      = "held"
+	SeatStatusSold      = "sold"
+)
+
+// SeatMapMaxSeats caps the number of seats of a single ticket type.
+const SeatMapMaxSeats = 100000
+
+// SeatMap lays out the seats of a seated ticket in sections and rows. Once a ticket
+// has a seat map, its allocation is the number of available seats and buyers pick
+// the seats they purchase or hold. Available is only set in responses.
+type SeatMap struct {
+	TicketID  int           `json:"ticket_id"`
+	Available int           `json:"available"`
+	Sections  []SeatSection `json:"sections"`
+}
+
+type SeatSection struct {
+	Name string    `json:"name"`
+	Rows []SeatRow `json:"rows"`
+}
+
+type SeatRow struct {
+	Name  string `json:"name"`
+	Seats []Seat `json:"seats"`
+}
+
+// Seat is a single seat of a row. ID and Status are set by the server, a seat map
+// is created from the seat numbers only.
+type Seat struct {
+	ID     int    `json:"id"`
+	Number string `json:"number"`
+	Status string `json:"status"`
+}
@@ -1,14 +1,156 @@
 package models
 
+import (
+	"encoding/json"
+	"time"
+)
+
 const TicketCachePrefix = "ticket:"
 
+const (
+	SaleStatusUpcoming = "upcoming"
+	SaleStatusOnSale   = "on_sale"
+	SaleStatusEnded    = "ended"
+	SaleStatusSoldOut  = "sold_out"
+)
+
+const (
+	TicketListCachePrefix   = "tickets:list:"
+	TicketListVersionPrefix = "tickets:list:version:"
+	TicketListDefaultLimit  = 20
+	TicketListMaxLimit      = 100
+)
+
+// Ticket is a ticket type on sale. Every purchase or hold of it buys between
+// MinPerOrder and MaxPerOrder tickets, 0 for no maximum, in multiples of QuantityStep.
+// Tickets in a capacity pool can only be sold while the pool has capacity left.
+// A ticket belongs to the organizer that created it and is only visible to it.
+// Allocation is what is left to sell, TotalAllocation also counts the held and sold
+// tickets.
 type Ticket struct {
-	ID          int    `json:"id"`
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	Allocation  int    `json:"allocation"`
+	ID              int        `json:"id"`
+	OrganizerID     int        `json:"organizer_id"`
+	EventID         *int       `json:"event_id,omitempty"`
+	CapacityPoolID  *int       `json:"capacity_pool_id,omitempty"`
+	Name            string     `json:"name"`
+	Description     string     `json:"description"`
+	Allocation      int        `json:"allocation"`
+	TotalAllocation int        `json:"total_allocation"`
+	Held            int        `json:"held"`
+	Sold            int        `json:"sold"`
+	MaxPerBuyer     int        `json:"max_per_buyer"`
+	MinPerOrder     int        `json:"min_per_order"`
+	MaxPerOrder     int        `json:"max_per_order"`
+	QuantityStep    int        `json:"quantity_step"`
+	Seated          bool       `json:"seated"`
+	Price           Money      `json:"price"`
+	SaleStartsAt    *time.Time `json:"sale_starts_at,omitempty"`
+	SaleEndsAt      *time.Time `json:"sale_ends_at,omitempty"`
+	SaleStatus      string     `json:"sale_status"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+	ArchivedAt      *time.Time `json:"archived_at,omitempty"`
+}
+
+// CurrentSaleStatus computes the sale status at now. It isn't stored, since it
+// changes with time and not only with the row.
+func (t *Ticket) CurrentSaleStatus(now time.Time) string {
+	if t.ArchivedAt != nil || (t.SaleEndsAt != nil && !now.Before(*t.SaleEndsAt)) {
+		return SaleStatusEnded
+	}
+
+	if t.SaleStartsAt != nil && now.Before(*t.SaleStartsAt) {
+		return SaleStatusUpcoming
+	}
+
+	if t.Allocation == 0 {
+		return SaleStatusSoldOut
+	}
+
+	return SaleStatusOnSale
+}
+
+// TicketUpdate is a partial ticket update, nil fields are left unchanged.
+// Allocation and TotalAllocation mean the same as on a read ticket, so a ticket
+// written back as it was read is unchanged. The allocation of a seated ticket
+// follows its seat map and can't be updated.
+type TicketUpdate struct {
+	Name            *string      `json:"name"`
+	Description     *string      `json:"description"`
+	Allocation      *int         `json:"allocation"`
+	TotalAllocation *int         `json:"total_allocation"`
+	MaxPerBuyer     *int         `json:"max_per_buyer"`
+	CapacityPoolID  OptionalInt  `json:"capacity_pool_id"`
+	MinPerOrder     *int         `json:"min_per_order"`
+	MaxPerOrder     *int         `json:"max_per_order"`
+	QuantityStep    *int         `json:"quantity_step"`
+	Price           *Money       `json:"price"`
+	SaleStartsAt    OptionalTime `json:"sale_starts_at"`
+	SaleEndsAt      OptionalTime `json:"sale_ends_at"`
+}
+
+// OptionalTime tells an explicit null, which clears the time, apart from a
+// missing field in partial updates.
+type OptionalTime struct {
+	Set   bool
+	Value *time.Time
+}
+
+func (o *OptionalTime) UnmarshalJSON(data []byte) error {
+	o.Set = true
+	o.Value = nil
+
+	if string(data) == "null" {
+		return nil
+	}
+
+	var value time.Time
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	o.Value = &value
+
+	return nil
+}
+
+// OptionalInt tells an explicit null, which clears the value, apart from a missing
+// field in partial updates.
+type OptionalInt struct {
+	Set   bool
+	Value *int
+}
+
+func (o *OptionalInt) UnmarshalJSON(data []byte) error {
+	o.Set = true
+	o.Value = nil
+
+	if string(data) == "null" {
+		return nil
+	}
+
+	var value int
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	o.Value = &value
+
+	return nil
+}
+
+// TicketListParams holds the filters, sorting and pagination options of a ticket listing.
+type TicketListParams struct {
+	Name          string     `json:"name,omitempty"`
+	EventID       int        `json:"event_id,omitempty"`
+	MinAllocation int        `json:"min_allocation,omitempty"`
+	CreatedFrom   *time.Time `json:"created_from,omitempty"`
+	CreatedTo     *time.Time `json:"created_to,omitempty"`
+	Sort          string     `json:"sort,omitempty"`
+	Order         string     `json:"order,omitempty"`
+	Cursor        string     `json:"cursor,omitempty"`
+	Limit         int        `json:"limit,omitempty"`
 }
 
-type PurchaseRequest struct {
-	Quantity int `json:"quantity"`
+type TicketList struct {
+	Tickets    []Ticket `json:"tickets"`
+	NextCursor string   `json:"next_cursor,omitempty"`
 }
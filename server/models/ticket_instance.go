@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+const (
+	TicketInstanceStatusValid = "valid"
+	TicketInstanceStatusVoid  = "void"
+)
+
+const (
+	TicketQRCodeDefaultSize = 256
+	TicketQRCodeMinSize     = 64
+	TicketQRCodeMaxSize     = 1024
+)
+
+// TicketInstance is a single purchased ticket, the unit that is scanned at the door.
+// Token is the signed form of its code, meant to be encoded in a QR code. Refunded
+// tickets are void and have no token.
+type TicketInstance struct {
+	ID         int        `json:"id"`
+	TicketID   int        `json:"ticket_id"`
+	PurchaseID int        `json:"purchase_id"`
+	SeatID     *int       `json:"seat_id,omitempty"`
+	Status     string     `json:"status"`
+	Token      string     `json:"token,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	VoidedAt   *time.Time `json:"voided_at,omitempty"`
+}
+
+type TicketInstanceList struct {
+	TicketInstances []TicketInstance `json:"ticket_instances"`
+}
@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// WaitlistOfferDuration is how long a waitlisted buyer has to confirm an offer.
+const WaitlistOfferDuration = 15 * time.Minute
+
+// Stored entry statuses. Once offered, the status of an entry follows its hold,
+// see WaitlistEntry.Status.
+const (
+	WaitlistStatusWaiting = "waiting"
+	WaitlistStatusOffered = "offered"
+	WaitlistStatusLeft    = "left"
+)
+
+// Statuses of offered entries, derived from their hold.
+const (
+	WaitlistStatusFulfilled = "fulfilled"
+	WaitlistStatusDeclined  = "declined"
+	WaitlistStatusExpired   = "expired"
+)
+
+// WaitlistEntry queues a buyer for a sold out ticket. When tickets free up, the
+// first waiting entry gets an offer, a hold on the tickets that the buyer confirms
+// like any other hold. Position is 1 for the next entry to get an offer and is only
+// set while the entry is waiting.
+type WaitlistEntry struct {
+	ID             int        `json:"id"`
+	TicketID       int        `json:"ticket_id"`
+	BuyerID        string     `json:"buyer_id"`
+	Quantity       int        `json:"quantity"`
+	Status         string     `json:"status"`
+	Position       int        `json:"position,omitempty"`
+	HoldID         *int       `json:"hold_id,omitempty"`
+	OfferExpiresAt *time.Time `json:"offer_expires_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+type WaitlistRequest struct {
+	BuyerID  string `json:"buyer_id"`
+	Quantity int    `json:"quantity"`
+}
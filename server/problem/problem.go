@@ -0,0 +1,83 @@
+package problem
+
+import (
+	customErrors "gowitcase/errors"
+	"gowitcase/models"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ContentType is the media type of RFC 7807 problem details.
+const ContentType = "application/problem+json"
+
+// Problem is the body of every error response. Type is always about:blank, so
+// Title is the text of the status and Code tells the errors apart.
+type Problem struct {
+	Type      string                    `json:"type"`
+	Title     string                    `json:"title"`
+	Status    int                       `json:"status"`
+	Detail    string                    `json:"detail"`
+	Instance  string                    `json:"instance,omitempty"`
+	Code      string                    `json:"code"`
+	RequestID string                    `json:"request_id,omitempty"`
+	Details   map[string]interface{}    `json:"details,omitempty"`
+	Errors    []customErrors.FieldError `json:"errors,omitempty"`
+	Lines     []customErrors.LineError  `json:"lines,omitempty"`
+}
+
+// New describes err as a problem. Errors other than RestErrors are internal, and
+// their message isn't shown to the client.
+func New(err error, instance string, requestID string) Problem {
+	var restErr customErrors.RestError
+	var lines []customErrors.LineError
+
+	switch e := err.(type) {
+	case customErrors.RestError:
+		restErr = e
+	case customErrors.LineErrors:
+		restErr = e.RestError
+		lines = e.Lines
+	default:
+		restErr = customErrors.NewRestError("Something went wrong, please try again.", http.StatusInternalServerError)
+	}
+
+	code := restErr.Code
+	if code == "" {
+		code = customErrors.StatusCode(restErr.Status)
+	}
+
+	return Problem{
+		Type:      "about:blank",
+		Title:     http.StatusText(restErr.Status),
+		Status:    restErr.Status,
+		Detail:    restErr.Message,
+		Instance:  instance,
+		Code:      code,
+		RequestID: requestID,
+		Details:   restErr.Details,
+		Errors:    restErr.Fields,
+		Lines:     lines,
+	}
+}
+
+// Respond writes err as the problem of the request and aborts the request. It is
+// the one place handlers report errors from: unexpected errors are logged with the
+// request and its ID, so a client reporting the ID of a failed request can be
+// traced, and answered with a generic 500.
+func Respond(ctx *gin.Context, err error) {
+	requestID := ctx.GetString(models.RequestIDKey)
+
+	switch err.(type) {
+	case customErrors.RestError, customErrors.LineErrors:
+	default:
+		log.Printf("Unexpected error with err: %v, request: %s %s, request_id: %s",
+			err, ctx.Request.Method, ctx.Request.URL.Path, requestID)
+	}
+
+	problem := New(err, ctx.Request.URL.Path, requestID)
+
+	ctx.Header("Content-Type", ContentType)
+	ctx.AbortWithStatusJSON(problem.Status, problem)
+}
@@ -0,0 +1,125 @@
+package problem_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	customErrors "gowitcase/errors"
+	"gowitcase/middleware"
+	"gowitcase/problem"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// respond answers a request for path, sent with requestID, with err.
+func respond(t *testing.T, path string, requestID string, err error) (*httptest.ResponseRecorder, problem.Problem) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(middleware.RequestIDMiddleware())
+	router.GET("/tickets/:id", func(ctx *gin.Context) {
+		problem.Respond(ctx, err)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if requestID != "" {
+		req.Header.Set(middleware.RequestIDHeader, requestID)
+	}
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	var p problem.Problem
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &p), "expected a problem")
+	return recorder, p
+}
+
+// captureLog collects what is logged until the test ends.
+func captureLog(t *testing.T) *bytes.Buffer {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+	return &buf
+}
+
+func TestRespond_RestError(t *testing.T) {
+	logged := captureLog(t)
+
+	err := customErrors.NewRestError("Only 2 tickets left", 400).
+		WithCode(customErrors.CodeNotEnoughTickets).
+		WithDetail("available", 2)
+
+	recorder, p := respond(t, "/tickets/1", "req-1", err)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Equal(t, problem.ContentType, recorder.Header().Get("Content-Type"))
+	assert.Equal(t, problem.Problem{
+		Type:      "about:blank",
+		Title:     "Bad Request",
+		Status:    400,
+		Detail:    "Only 2 tickets left",
+		Instance:  "/tickets/1",
+		Code:      customErrors.CodeNotEnoughTickets,
+		RequestID: "req-1",
+		Details:   map[string]interface{}{"available": float64(2)},
+	}, p)
+	assert.Empty(t, logged.String(), "expected an expected error not to be logged")
+}
+
+func TestRespond_FieldErrors(t *testing.T) {
+	var fields customErrors.FieldErrors
+	fields.Add("name", "Field 'name' is required")
+	fields.Add("lines[1].quantity", "Field 'lines[1].quantity' must be an integer within the valid range")
+
+	recorder, p := respond(t, "/tickets/1", "", fields.Err())
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Equal(t, customErrors.CodeValidationFailed, p.Code)
+	assert.Equal(t, []customErrors.FieldError(fields), p.Errors, "expected every invalid field")
+}
+
+func TestRespond_LineErrors(t *testing.T) {
+	err := customErrors.NewLineErrors("Order failed", []customErrors.LineError{
+		{Line: 0, TicketID: 1, Code: customErrors.CodeSoldOut, Message: "Ticket 1 is sold out", Status: 400},
+		{Line: 2, TicketID: 3, Code: customErrors.CodeSoldOut, Message: "Ticket 3 is sold out", Status: 400},
+	})
+
+	recorder, p := respond(t, "/tickets/1", "", err)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Equal(t, customErrors.CodeSoldOut, p.Code, "expected the code shared by the lines")
+	assert.Len(t, p.Lines, 2, "expected every failed line")
+	assert.Equal(t, 2, p.Lines[1].Line)
+}
+
+func TestRespond_UnexpectedError(t *testing.T) {
+	logged := captureLog(t)
+
+	recorder, p := respond(t, "/tickets/1", "req-2", fmt.Errorf("pq: connection refused"))
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	assert.Equal(t, customErrors.CodeInternal, p.Code)
+	assert.Equal(t, "Something went wrong, please try again.", p.Detail, "expected the cause to stay out of the response")
+	assert.Equal(t, "req-2", p.RequestID)
+
+	assert.Contains(t, logged.String(), "pq: connection refused", "expected the cause to be logged")
+	assert.Contains(t, logged.String(), "GET /tickets/1")
+	assert.Contains(t, logged.String(), "request_id: req-2", "expected the log to be found by the request ID")
+}
+
+func TestRespond_RequestID(t *testing.T) {
+	// The ID sent by the client is echoed in the header and the problem.
+	recorder, p := respond(t, "/tickets/1", "client-id", customErrors.NewRestError("Ticket 1 not found", 404))
+	assert.Equal(t, "client-id", recorder.Header().Get(middleware.RequestIDHeader))
+	assert.Equal(t, "client-id", p.RequestID)
+
+	// A missing or malformed ID is replaced, and the problem carries the new one.
+	for _, requestID := range []string{"", "not a valid id"} {
+		recorder, p = respond(t, "/tickets/1", requestID, customErrors.NewRestError("Ticket 1 not found", 404))
+		assert.NotEmpty(t, p.RequestID, "expected a new request ID")
+		assert.NotEqual(t, requestID, p.RequestID)
+		assert.Equal(t, recorder.Header().Get(middleware.RequestIDHeader), p.RequestID, "expected the ID of the header")
+	}
+}
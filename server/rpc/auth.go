@@ -0,0 +1,40 @@
+package rpc
+
+import (
+	"context"
+	"gowitcase/services"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// APIKeyMetadata is the metadata key carrying the organizer's API key, the
+// counterpart of the X-API-Key header of the REST API.
+const APIKeyMetadata = "x-api-key"
+
+type organizerIDKey struct{}
+
+// OrganizerInterceptor authenticates every call with the organizer's API key and
+// makes the call as that organizer.
+func OrganizerInterceptor(organizerService *services.OrganizerService) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		apiKey := ""
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if values := md.Get(APIKeyMetadata); len(values) > 0 {
+				apiKey = values[0]
+			}
+		}
+
+		organizerID, err := organizerService.Authenticate(apiKey)
+		if err != nil {
+			return nil, statusError(err, "authenticate organizer")
+		}
+
+		return handler(context.WithValue(ctx, organizerIDKey{}, organizerID), req)
+	}
+}
+
+func organizerID(ctx context.Context) int {
+	id, _ := ctx.Value(organizerIDKey{}).(int)
+	return id
+}
@@ -0,0 +1,104 @@
+package rpc
+
+import (
+	"fmt"
+	customErrors "gowitcase/errors"
+	"log"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+// ErrorDomain is the domain of the ErrorInfo attached to the statuses of RestErrors.
+const ErrorDomain = "gowitcase"
+
+// restErrorCodes maps the codes of RestErrors to gRPC codes. A request the current
+// state of a resource rejects, such as buying sold out tickets, is a failed
+// precondition in gRPC terms, even where REST reports it as a 400. Codes missing
+// here fall back to the generic code of their status.
+var restErrorCodes = map[string]codes.Code{
+	customErrors.CodeInvalidRequest:   codes.InvalidArgument,
+	customErrors.CodeValidationFailed: codes.InvalidArgument,
+	customErrors.CodeUnauthorized:     codes.Unauthenticated,
+	customErrors.CodeForbidden:        codes.PermissionDenied,
+	customErrors.CodeNotFound:         codes.NotFound,
+	customErrors.CodeConflict:         codes.FailedPrecondition,
+	customErrors.CodeUnprocessable:    codes.FailedPrecondition,
+	customErrors.CodeInternal:         codes.Internal,
+
+	customErrors.CodeSoldOut:              codes.FailedPrecondition,
+	customErrors.CodeNotEnoughTickets:     codes.FailedPrecondition,
+	customErrors.CodeCapacityReached:      codes.FailedPrecondition,
+	customErrors.CodeSaleNotStarted:       codes.FailedPrecondition,
+	customErrors.CodeSaleEnded:            codes.FailedPrecondition,
+	customErrors.CodeInvalidQuantity:      codes.InvalidArgument,
+	customErrors.CodeBuyerLimitExceeded:   codes.FailedPrecondition,
+	customErrors.CodeTicketArchived:       codes.FailedPrecondition,
+	customErrors.CodeEventArchived:        codes.FailedPrecondition,
+	customErrors.CodeSeatUnavailable:      codes.FailedPrecondition,
+	customErrors.CodeSeatMapInUse:         codes.FailedPrecondition,
+	customErrors.CodePromoCodeInvalid:     codes.InvalidArgument,
+	customErrors.CodePromoCodeExpired:     codes.FailedPrecondition,
+	customErrors.CodePromoCodeExhausted:   codes.FailedPrecondition,
+	customErrors.CodePromoCodeNotEligible: codes.FailedPrecondition,
+	customErrors.CodeHoldExpired:          codes.FailedPrecondition,
+	customErrors.CodeHoldClosed:           codes.FailedPrecondition,
+	customErrors.CodeAlreadyCheckedIn:     codes.FailedPrecondition,
+	customErrors.CodeTicketNotValid:       codes.FailedPrecondition,
+
+	customErrors.CodeIdempotencyKeyReused:     codes.FailedPrecondition,
+	customErrors.CodeIdempotencyKeyInProgress: codes.Aborted,
+}
+
+// restErrorCode is the gRPC code of a RestError.
+func restErrorCode(restErr customErrors.RestError) codes.Code {
+	if code, ok := restErrorCodes[restErr.Code]; ok {
+		return code
+	}
+	return restErrorCodes[customErrors.StatusCode(restErr.Status)]
+}
+
+// statusError turns a service error into the status returned to the client. The
+// message of a RestError is returned with its code and details in an ErrorInfo,
+// and its field errors in a BadRequest. Other errors are logged.
+func statusError(err error, method string) error {
+	if restErr, ok := err.(customErrors.RestError); ok {
+		return restErrorStatus(restErrorCode(restErr), restErr).Err()
+	}
+
+	log.Printf("Failed to %s with err: %v", method, err)
+	return status.Error(codes.Internal, "Something went wrong, please try again.")
+}
+
+// restErrorStatus is the status of a RestError with its code and field errors attached.
+func restErrorStatus(code codes.Code, restErr customErrors.RestError) *status.Status {
+	st := status.New(code, restErr.Message)
+
+	info := &errdetails.ErrorInfo{Reason: restErr.Code, Domain: ErrorDomain}
+	if len(restErr.Details) > 0 {
+		info.Metadata = make(map[string]string, len(restErr.Details))
+		for key, value := range restErr.Details {
+			info.Metadata[key] = fmt.Sprint(value)
+		}
+	}
+	details := []protoadapt.MessageV1{info}
+
+	if len(restErr.Fields) > 0 {
+		badRequest := &errdetails.BadRequest{}
+		for _, field := range restErr.Fields {
+			badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field: field.Field, Description: field.Message,
+			})
+		}
+		details = append(details, badRequest)
+	}
+
+	withDetails, err := st.WithDetails(details...)
+	if err != nil {
+		log.Printf("Failed to attach error details with err: %v", err)
+		return st
+	}
+	return withDetails
+}
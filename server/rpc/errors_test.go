@@ -0,0 +1,82 @@
+package rpc
+
+import (
+	"fmt"
+	customErrors "gowitcase/errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestStatusError_Codes(t *testing.T) {
+	tests := []struct {
+		err  customErrors.RestError
+		code codes.Code
+	}{
+		{customErrors.NewRestError("Invalid ticket ID", 400), codes.InvalidArgument},
+		{customErrors.NewFieldError("name", "Field 'name' is required"), codes.InvalidArgument},
+		{customErrors.NewRestError("Invalid API key", 401), codes.Unauthenticated},
+		{customErrors.NewRestError("Ticket 1 not found", 404), codes.NotFound},
+		{customErrors.NewRestError("Seat map is in use", 409), codes.FailedPrecondition},
+		{customErrors.NewRestError("Idempotency-Key was already used", 422), codes.FailedPrecondition},
+		{customErrors.NewRestError("Something went wrong", 500), codes.Internal},
+		{customErrors.NewRestError("Service unavailable", 503), codes.Internal},
+		{customErrors.NewRestError("Ticket 1 is sold out", 400).WithCode(customErrors.CodeSoldOut), codes.FailedPrecondition},
+		{customErrors.NewRestError("Only 2 tickets left", 400).WithCode(customErrors.CodeNotEnoughTickets), codes.FailedPrecondition},
+		{customErrors.NewRestError("Sale hasn't started", 400).WithCode(customErrors.CodeSaleNotStarted), codes.FailedPrecondition},
+		{customErrors.NewRestError("Sale has ended", 400).WithCode(customErrors.CodeSaleEnded), codes.FailedPrecondition},
+		{customErrors.NewRestError("Venue capacity is reached", 400).WithCode(customErrors.CodeCapacityReached), codes.FailedPrecondition},
+		{customErrors.NewRestError("Buyer limit exceeded", 400).WithCode(customErrors.CodeBuyerLimitExceeded), codes.FailedPrecondition},
+		{customErrors.NewRestError("Ticket 1 is archived", 400).WithCode(customErrors.CodeTicketArchived), codes.FailedPrecondition},
+		{customErrors.NewRestError("Quantity must be positive", 400).WithCode(customErrors.CodeInvalidQuantity), codes.InvalidArgument},
+		{customErrors.NewRestError("Unknown rule", 400).WithCode("some_new_code"), codes.InvalidArgument},
+	}
+
+	for _, test := range tests {
+		t.Run(test.err.Code, func(t *testing.T) {
+			st, ok := status.FromError(statusError(test.err, "test"))
+			assert.True(t, ok, "expected a gRPC status")
+			assert.Equal(t, test.code, st.Code(), fmt.Sprintf("expected %s to map to %s", test.err.Code, test.code))
+			assert.Equal(t, test.err.Message, st.Message(), "expected the message of the RestError")
+		})
+	}
+}
+
+func TestStatusError_Details(t *testing.T) {
+	err := customErrors.NewRestError("Only 2 tickets left", 400).
+		WithCode(customErrors.CodeNotEnoughTickets).
+		WithDetail("available", 2)
+
+	st, _ := status.FromError(statusError(err, "test"))
+	assert.Len(t, st.Details(), 1, "expected an ErrorInfo")
+
+	info, ok := st.Details()[0].(*errdetails.ErrorInfo)
+	assert.True(t, ok, "expected an ErrorInfo")
+	assert.Equal(t, customErrors.CodeNotEnoughTickets, info.GetReason())
+	assert.Equal(t, ErrorDomain, info.GetDomain())
+	assert.Equal(t, "2", info.GetMetadata()["available"])
+}
+
+func TestStatusError_FieldViolations(t *testing.T) {
+	var fields customErrors.FieldErrors
+	fields.Add("name", "Field 'name' is required")
+	fields.Add("allocation", "Field 'allocation' must be greater than 0")
+
+	st, _ := status.FromError(statusError(fields.Err(), "test"))
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+	assert.Len(t, st.Details(), 2, "expected an ErrorInfo and a BadRequest")
+
+	badRequest, ok := st.Details()[1].(*errdetails.BadRequest)
+	assert.True(t, ok, "expected a BadRequest")
+	assert.Len(t, badRequest.GetFieldViolations(), 2, "expected every invalid field")
+	assert.Equal(t, "allocation", badRequest.GetFieldViolations()[1].GetField())
+}
+
+func TestStatusError_Internal(t *testing.T) {
+	st, _ := status.FromError(statusError(fmt.Errorf("connection refused"), "test"))
+	assert.Equal(t, codes.Internal, st.Code())
+	assert.Equal(t, "Something went wrong, please try again.", st.Message(), "expected the cause to stay out of the status")
+}
@@ -0,0 +1,189 @@
+package rpc
+
+import (
+	"context"
+	customErrors "gowitcase/errors"
+	"gowitcase/models"
+	"gowitcase/services"
+	"gowitcase/ticketpb"
+	"math"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// TicketServer serves the gRPC TicketService with the same TicketService as the
+// REST API, so both apply the same rules.
+type TicketServer struct {
+	ticketpb.UnimplementedTicketServiceServer
+	TicketService *services.TicketService
+}
+
+func NewTicketServer(ticketService *services.TicketService) *TicketServer {
+	return &TicketServer{TicketService: ticketService}
+}
+
+func (s *TicketServer) CreateTicket(ctx context.Context, req *ticketpb.CreateTicketRequest) (*ticketpb.Ticket, error) {
+	ticket := &models.Ticket{
+		Name:         req.GetName(),
+		Description:  req.GetDescription(),
+		Allocation:   int(req.GetAllocation()),
+		MaxPerBuyer:  int(req.GetMaxPerBuyer()),
+		MinPerOrder:  int(req.GetMinPerOrder()),
+		MaxPerOrder:  int(req.GetMaxPerOrder()),
+		QuantityStep: int(req.GetQuantityStep()),
+		SaleStartsAt: fromTimestamp(req.GetSaleStartsAt()),
+		SaleEndsAt:   fromTimestamp(req.GetSaleEndsAt()),
+	}
+
+	var err error
+	ticket.EventID, err = fromOptionalID(req.EventId, "event")
+	if err != nil {
+		return nil, statusError(err, "create ticket")
+	}
+	ticket.CapacityPoolID, err = fromOptionalID(req.CapacityPoolId, "capacity pool")
+	if err != nil {
+		return nil, statusError(err, "create ticket")
+	}
+	if price := req.GetPrice(); price != nil {
+		ticket.Price = models.NewMoney(price.GetAmount(), price.GetCurrency())
+	}
+
+	err = s.TicketService.CreateTicket(organizerID(ctx), ticket)
+	if err != nil {
+		return nil, statusError(err, "create ticket")
+	}
+
+	return toTicket(ticket), nil
+}
+
+func (s *TicketServer) GetTicket(ctx context.Context, req *ticketpb.GetTicketRequest) (*ticketpb.Ticket, error) {
+	ticketID, err := fromID(req.GetId(), "ticket")
+	if err != nil {
+		return nil, statusError(err, "get ticket")
+	}
+
+	ticket, err := s.TicketService.GetTicket(organizerID(ctx), ticketID)
+	if err != nil {
+		return nil, statusError(err, "get ticket")
+	}
+
+	return toTicket(ticket), nil
+}
+
+func (s *TicketServer) PurchaseTicket(ctx context.Context, req *ticketpb.PurchaseTicketRequest) (*ticketpb.Purchase, error) {
+	ticketID, err := fromID(req.GetTicketId(), "ticket")
+	if err != nil {
+		return nil, statusError(err, "purchase ticket")
+	}
+
+	request := models.PurchaseRequest{
+		BuyerID:   req.GetBuyerId(),
+		Quantity:  int(req.GetQuantity()),
+		PromoCode: req.GetPromoCode(),
+	}
+	for _, id := range req.GetSeatIds() {
+		seatID, err := fromID(id, "seat")
+		if err != nil {
+			return nil, statusError(err, "purchase ticket")
+		}
+		request.SeatIDs = append(request.SeatIDs, seatID)
+	}
+
+	purchase, err := s.TicketService.PurchaseTicket(organizerID(ctx), ticketID, request)
+	if err != nil {
+		return nil, statusError(err, "purchase ticket")
+	}
+
+	return toPurchase(purchase), nil
+}
+
+func toTicket(ticket *models.Ticket) *ticketpb.Ticket {
+	return &ticketpb.Ticket{
+		Id:             int64(ticket.ID),
+		EventId:        toOptionalID(ticket.EventID),
+		CapacityPoolId: toOptionalID(ticket.CapacityPoolID),
+		Name:           ticket.Name,
+		Description:    ticket.Description,
+		Allocation:     int32(ticket.Allocation),
+		Held:           int32(ticket.Held),
+		Sold:           int32(ticket.Sold),
+		MaxPerBuyer:    int32(ticket.MaxPerBuyer),
+		MinPerOrder:    int32(ticket.MinPerOrder),
+		MaxPerOrder:    int32(ticket.MaxPerOrder),
+		QuantityStep:   int32(ticket.QuantityStep),
+		Seated:         ticket.Seated,
+		Price:          toMoney(ticket.Price),
+		SaleStatus:     ticket.SaleStatus,
+		SaleStartsAt:   toTimestamp(ticket.SaleStartsAt),
+		SaleEndsAt:     toTimestamp(ticket.SaleEndsAt),
+		CreatedAt:      timestamppb.New(ticket.CreatedAt),
+		UpdatedAt:      timestamppb.New(ticket.UpdatedAt),
+		ArchivedAt:     toTimestamp(ticket.ArchivedAt),
+	}
+}
+
+func toPurchase(purchase *models.Purchase) *ticketpb.Purchase {
+	seatIDs := make([]int64, len(purchase.SeatIDs))
+	for i, seatID := range purchase.SeatIDs {
+		seatIDs[i] = int64(seatID)
+	}
+
+	return &ticketpb.Purchase{
+		Id:          int64(purchase.ID),
+		TicketId:    int64(purchase.TicketID),
+		BuyerId:     purchase.BuyerID,
+		Quantity:    int32(purchase.Quantity),
+		UnitPrice:   toMoney(purchase.UnitPrice),
+		Discount:    toMoney(purchase.Discount),
+		Total:       toMoney(purchase.Total),
+		PromoCodeId: toOptionalID(purchase.PromoCodeID),
+		SeatIds:     seatIDs,
+		CreatedAt:   timestamppb.New(purchase.CreatedAt),
+	}
+}
+
+func toMoney(money models.Money) *ticketpb.Money {
+	return &ticketpb.Money{Amount: money.Amount, Currency: money.Currency}
+}
+
+func toOptionalID(id *int) *int64 {
+	if id == nil {
+		return nil
+	}
+	value := int64(*id)
+	return &value
+}
+
+func toTimestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
+
+func fromTimestamp(ts *timestamppb.Timestamp) *time.Time {
+	if ts == nil {
+		return nil
+	}
+	t := ts.AsTime()
+	return &t
+}
+
+func fromID(id int64, kind string) (int, error) {
+	if id <= 0 || id > math.MaxInt32 {
+		return 0, customErrors.NewRestError("Invalid "+kind+" ID", 400)
+	}
+	return int(id), nil
+}
+
+func fromOptionalID(id *int64, kind string) (*int, error) {
+	if id == nil {
+		return nil, nil
+	}
+	value, err := fromID(*id, kind)
+	if err != nil {
+		return nil, err
+	}
+	return &value, nil
+}
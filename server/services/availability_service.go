@@ -0,0 +1,97 @@
+package services
+
+import (
+	"encoding/json"
+	"gowitcase/db"
+	"gowitcase/models"
+	"log"
+	"sync"
+	"time"
+)
+
+// AvailabilityService tells the availability streams served by this instance when
+// their ticket changed. It holds a single Redis subscription for all of them, and
+// changes made by any instance reach it through that subscription.
+type AvailabilityService struct {
+	DB    db.DatabaseInterface
+	Cache db.RedisInterface
+
+	mu       sync.Mutex
+	watchers map[models.AvailabilityChange]map[chan struct{}]bool
+}
+
+func NewAvailabilityService(db db.DatabaseInterface, cache db.RedisInterface) *AvailabilityService {
+	return &AvailabilityService{DB: db, Cache: cache, watchers: make(map[models.AvailabilityChange]map[chan struct{}]bool)}
+}
+
+// Watch returns a channel receiving a value after the ticket changed, and the
+// function to stop watching it. Changes made while the previous one wasn't
+// received yet are merged into it.
+func (s *AvailabilityService) Watch(organizerID int, ticketID int) (<-chan struct{}, func()) {
+	key := models.AvailabilityChange{OrganizerID: organizerID, TicketID: ticketID}
+	changes := make(chan struct{}, 1)
+
+	s.mu.Lock()
+	if s.watchers[key] == nil {
+		s.watchers[key] = make(map[chan struct{}]bool)
+	}
+	s.watchers[key][changes] = true
+	s.mu.Unlock()
+
+	stop := func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		delete(s.watchers[key], changes)
+		if len(s.watchers[key]) == 0 {
+			delete(s.watchers, key)
+		}
+	}
+
+	return changes, stop
+}
+
+// StartListener subscribes to the availability changes and notifies the watchers
+// until the process ends, subscribing again after retryInterval when Redis fails.
+func (s *AvailabilityService) StartListener(retryInterval time.Duration) {
+	for {
+		subscription, err := s.Cache.Subscribe(models.AvailabilityChannel)
+		if err != nil {
+			log.Printf("Failed to subscribe to availability changes: %v", err)
+			time.Sleep(retryInterval)
+			continue
+		}
+
+		s.Listen(subscription)
+
+		log.Printf("Availability subscription ended, subscribing again")
+		time.Sleep(retryInterval)
+	}
+}
+
+// Listen notifies the watchers of the changes received by subscription until it ends.
+func (s *AvailabilityService) Listen(subscription db.Subscription) {
+	defer subscription.Close()
+
+	for message := range subscription.Messages() {
+		change := models.AvailabilityChange{}
+		err := json.Unmarshal([]byte(message), &change)
+		if err != nil {
+			log.Printf("Failed to decode availability change %q: %v", message, err)
+			continue
+		}
+
+		s.notify(change)
+	}
+}
+
+func (s *AvailabilityService) notify(change models.AvailabilityChange) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for changes := range s.watchers[change] {
+		select {
+		case changes <- struct{}{}:
+		default:
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package services_test
+
+import (
+	"encoding/json"
+	"gowitcase/mocks"
+	"gowitcase/models"
+	"gowitcase/services"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupAvailabilityTest(t *testing.T) (*services.AvailabilityService, *mocks.MockRedis) {
+	mockDB, _, err := mocks.NewMockDatabase()
+	assert.NoError(t, err)
+
+	cache := mocks.NewMockRedis()
+
+	return services.NewAvailabilityService(mockDB, cache), cache
+}
+
+func TestPurchaseTicket_PublishesAvailabilityChange(t *testing.T) {
+	ticketService, mock := setupTest(t)
+
+	subscription, err := ticketService.Cache.Subscribe(models.AvailabilityChannel)
+	assert.NoError(t, err)
+	defer subscription.Close()
+
+	mock.ExpectBegin()
+
+	mock.ExpectQuery("SELECT (.+) FROM ticket WHERE id = (.+) FOR UPDATE").
+		WithArgs(1, testOrganizerID).
+		WillReturnRows(newTicketRows(&models.Ticket{ID: 1, Name: "test", Allocation: 100}))
+
+	expectWaitlistDemand(mock, 1, 0)
+
+	mock.ExpectExec("UPDATE ticket SET allocation").
+		WithArgs(98, 0, 2, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	mock.ExpectQuery("INSERT INTO purchase").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(7, time.Now()))
+
+	expectTicketInstances(mock, 7)
+
+	mock.ExpectCommit()
+
+	_, err = ticketService.PurchaseTicket(testOrganizerID, 1, models.PurchaseRequest{BuyerID: "buyer", Quantity: 2})
+	assert.NoError(t, err, "failed to purchase ticket")
+
+	select {
+	case message := <-subscription.Messages():
+		change := models.AvailabilityChange{}
+		assert.NoError(t, json.Unmarshal([]byte(message), &change))
+		assert.Equal(t, models.AvailabilityChange{OrganizerID: testOrganizerID, TicketID: 1}, change)
+	case <-time.After(time.Second):
+		t.Fatal("expected the purchase to publish an availability change")
+	}
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAvailabilityService_NotifiesWatchersOfTheTicket(t *testing.T) {
+	availabilityService, cache := setupAvailabilityTest(t)
+
+	subscription, err := cache.Subscribe(models.AvailabilityChannel)
+	assert.NoError(t, err)
+	go availabilityService.Listen(subscription)
+
+	changes, stop := availabilityService.Watch(testOrganizerID, 1)
+	defer stop()
+	otherChanges, stopOther := availabilityService.Watch(testOrganizerID+1, 1)
+	defer stopOther()
+
+	err = cache.Publish(models.AvailabilityChannel, `{"organizer_id":9,"ticket_id":1}`)
+	assert.NoError(t, err)
+
+	select {
+	case <-changes:
+	case <-time.After(time.Second):
+		t.Fatal("expected the watcher of the ticket to be notified")
+	}
+
+	// The same ticket ID of another organizer is another ticket.
+	select {
+	case <-otherChanges:
+		t.Fatal("expected the watcher of another organizer's ticket not to be notified")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	subscription.Close()
+}
+
+func TestAvailabilityService_MergesPendingChanges(t *testing.T) {
+	availabilityService, cache := setupAvailabilityTest(t)
+
+	subscription, err := cache.Subscribe(models.AvailabilityChannel)
+	assert.NoError(t, err)
+
+	changes, stop := availabilityService.Watch(testOrganizerID, 1)
+	defer stop()
+
+	for i := 0; i < 3; i++ {
+		err = cache.Publish(models.AvailabilityChannel, `{"organizer_id":9,"ticket_id":1}`)
+		assert.NoError(t, err)
+	}
+	subscription.Close()
+
+	// Listen returns once the closed subscription is drained, without blocking on
+	// the watcher that didn't read yet.
+	availabilityService.Listen(subscription)
+
+	assert.Len(t, changes, 1, "expected the pending changes to be merged")
+}
@@ -0,0 +1,227 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"gowitcase/db"
+	"gowitcase/errors"
+	"gowitcase/models"
+	"log"
+	"math"
+	"sort"
+)
+
+const capacityPoolColumns = "id, organizer_id, name, capacity, used, created_at, updated_at"
+
+type CapacityPoolService struct {
+	DB    db.DatabaseInterface
+	Cache db.RedisInterface
+}
+
+func NewCapacityPoolService(db db.DatabaseInterface, cache db.RedisInterface) *CapacityPoolService {
+	return &CapacityPoolService{DB: db, Cache: cache}
+}
+
+func (s *CapacityPoolService) CreateCapacityPool(organizerID int, pool *models.CapacityPool) error {
+	if pool == nil {
+		return fmt.Errorf("capacity pool is nil")
+	}
+
+	pool.OrganizerID = organizerID
+	err := validateCapacityPool(*pool)
+	if err != nil {
+		return err
+	}
+
+	err = s.DB.QueryRow(
+		"INSERT INTO capacity_pool (organizer_id, name, capacity) VALUES ($1, $2, $3) RETURNING id, created_at, updated_at",
+		pool.OrganizerID, pool.Name, pool.Capacity,
+	).Scan(&pool.ID, &pool.CreatedAt, &pool.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create capacity pool: %v", err)
+	}
+
+	pool.Used = 0
+	pool.Available = pool.Capacity
+
+	return nil
+}
+
+func (s *CapacityPoolService) GetCapacityPool(organizerID int, id int) (*models.CapacityPool, error) {
+	pool := &models.CapacityPool{}
+	err := scanCapacityPool(s.DB.QueryRow(
+		"SELECT "+capacityPoolColumns+" FROM capacity_pool WHERE id = $1 AND organizer_id = $2", id, organizerID,
+	), pool)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NewRestError(fmt.Sprintf("Capacity pool %d not found", id), 404)
+		}
+		return nil, fmt.Errorf("failed to get capacity pool: %v", err)
+	}
+
+	return pool, nil
+}
+
+// UpdateCapacityPool applies the non-nil fields of update to the pool. The capacity
+// can't go below what its tickets already hold or sold.
+func (s *CapacityPoolService) UpdateCapacityPool(organizerID int, id int, update models.CapacityPoolUpdate) (*models.CapacityPool, error) {
+	tx, err := s.DB.BeginTransaction()
+	if err != nil {
+		log.Printf("Failed to start transaction: %v", err)
+		return nil, fmt.Errorf("failed to start transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	pool := &models.CapacityPool{}
+	err = scanCapacityPool(tx.QueryRow(
+		"SELECT "+capacityPoolColumns+" FROM capacity_pool WHERE id = $1 AND organizer_id = $2 FOR UPDATE", id, organizerID,
+	), pool)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NewRestError(fmt.Sprintf("Capacity pool %d not found", id), 404)
+		}
+		return nil, fmt.Errorf("failed to get capacity pool: %v", err)
+	}
+
+	if update.Name != nil {
+		pool.Name = *update.Name
+	}
+	if update.Capacity != nil {
+		pool.Capacity = *update.Capacity
+	}
+
+	err = validateCapacityPool(*pool)
+	if err != nil {
+		return nil, err
+	}
+
+	if pool.Capacity < pool.Used {
+		return nil, errors.NewRestError(
+			fmt.Sprintf("Field 'capacity' can't be less than the %d tickets already sold or held", pool.Used), 400,
+		)
+	}
+
+	err = tx.QueryRow(
+		"UPDATE capacity_pool SET name = $1, capacity = $2, updated_at = CURRENT_TIMESTAMP WHERE id = $3 RETURNING updated_at",
+		pool.Name, pool.Capacity, pool.ID,
+	).Scan(&pool.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update capacity pool: %v", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	pool.Available = pool.Capacity - pool.Used
+
+	return pool, nil
+}
+
+func validateCapacityPool(pool models.CapacityPool) error {
+	var fields errors.FieldErrors
+
+	if pool.Name == "" {
+		fields.Add("name", "Field 'name' is required")
+	} else if len(pool.Name) > 255 {
+		fields.Add("name", "Field 'name' must be less than 255 characters")
+	}
+
+	if pool.Capacity <= 0 {
+		fields.Add("capacity", "Field 'capacity' must be greater than 0")
+	} else if pool.Capacity > math.MaxInt32 {
+		fields.Add("capacity", "Field 'capacity' is too large")
+	}
+
+	return fields.Err()
+}
+
+// checkCapacityPool makes sure a ticket is put in a pool of its organizer. Pools
+// of other organizers are reported as not existing.
+func checkCapacityPool(q queryRower, organizerID int, poolID int) error {
+	var id int
+	err := q.QueryRow(
+		"SELECT id FROM capacity_pool WHERE id = $1 AND organizer_id = $2", poolID, organizerID,
+	).Scan(&id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errors.NewRestError(fmt.Sprintf("Capacity pool %d does not exist", poolID), 400)
+		}
+		return fmt.Errorf("failed to get capacity pool: %v", err)
+	}
+
+	return nil
+}
+
+// lockCapacityPools locks the given pools in ID order, so transactions touching the
+// same pools in opposite orders wait for each other instead of deadlocking. Nil
+// pools are skipped.
+func lockCapacityPools(tx *sql.Tx, poolIDs ...*int) error {
+	var ids []int
+	for _, poolID := range poolIDs {
+		if poolID != nil {
+			ids = append(ids, *poolID)
+		}
+	}
+	sort.Ints(ids)
+
+	for _, id := range ids {
+		err := tx.QueryRow("SELECT id FROM capacity_pool WHERE id = $1 FOR UPDATE", id).Scan(&id)
+		if err != nil {
+			return fmt.Errorf("failed to lock capacity pool: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// takePoolCapacity puts quantity tickets of a pool in use, in the same statement
+// that checks they fit, and fails when they don't. A nil pool has no limit. The
+// pool row stays locked until the caller's transaction ends, so callers taking
+// from several pools do it in pool ID order.
+func takePoolCapacity(tx *sql.Tx, poolID *int, quantity int) error {
+	if poolID == nil || quantity == 0 {
+		return nil
+	}
+
+	result, err := tx.Exec(
+		"UPDATE capacity_pool SET used = used + $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2 AND used + $1 <= capacity",
+		quantity, *poolID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update capacity pool: %v", err)
+	}
+
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to update capacity pool: %v", err)
+	}
+	if updated == 0 {
+		return errors.NewRestError("Not enough tickets available, the venue capacity is reached", 400).WithCode(errors.CodeCapacityReached)
+	}
+
+	return nil
+}
+
+// returnPoolCapacity gives quantity released or refunded tickets back to their pool.
+func returnPoolCapacity(tx *sql.Tx, poolID *int, quantity int) error {
+	if poolID == nil || quantity == 0 {
+		return nil
+	}
+
+	_, err := tx.Exec(
+		"UPDATE capacity_pool SET used = used - $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2",
+		quantity, *poolID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update capacity pool: %v", err)
+	}
+	return nil
+}
+
+func scanCapacityPool(row rowScanner, pool *models.CapacityPool) error {
+	err := row.Scan(&pool.ID, &pool.OrganizerID, &pool.Name, &pool.Capacity, &pool.Used, &pool.CreatedAt, &pool.UpdatedAt)
+	pool.Available = pool.Capacity - pool.Used
+	return err
+}
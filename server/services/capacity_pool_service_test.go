@@ -0,0 +1,253 @@
+package services_test
+
+import (
+	"gowitcase/errors"
+	"gowitcase/mocks"
+	"gowitcase/models"
+	"gowitcase/services"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupCapacityPoolTest(t *testing.T) (*services.CapacityPoolService, sqlmock.Sqlmock) {
+	mockDB, mock, err := mocks.NewMockDatabase()
+	assert.NoError(t, err)
+
+	capacityPoolService := services.NewCapacityPoolService(mockDB, mocks.NewMockRedis())
+
+	return capacityPoolService, mock
+}
+
+func newCapacityPoolRows(pool *models.CapacityPool) *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"id", "organizer_id", "name", "capacity", "used", "created_at", "updated_at"}).
+		AddRow(pool.ID, pool.OrganizerID, pool.Name, pool.Capacity, pool.Used, pool.CreatedAt, pool.UpdatedAt)
+}
+
+func TestCreateCapacityPool_Success(t *testing.T) {
+	capacityPoolService, mock := setupCapacityPoolTest(t)
+
+	mock.ExpectQuery("INSERT INTO capacity_pool").
+		WithArgs(testOrganizerID, "Main hall", 5000).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow(1, time.Now(), time.Now()))
+
+	pool := &models.CapacityPool{Name: "Main hall", Capacity: 5000}
+	err := capacityPoolService.CreateCapacityPool(testOrganizerID, pool)
+	assert.NoError(t, err, "failed to create capacity pool")
+	assert.Equal(t, 5000, pool.Available)
+	assert.Equal(t, testOrganizerID, pool.OrganizerID)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateCapacityPool_BelowUsed(t *testing.T) {
+	capacityPoolService, mock := setupCapacityPoolTest(t)
+
+	mock.ExpectBegin()
+
+	mock.ExpectQuery("SELECT (.+) FROM capacity_pool WHERE id = (.+) AND organizer_id = (.+) FOR UPDATE").
+		WithArgs(1, testOrganizerID).
+		WillReturnRows(newCapacityPoolRows(&models.CapacityPool{ID: 1, Name: "Main hall", Capacity: 5000, Used: 4200}))
+
+	mock.ExpectRollback()
+
+	capacity := 4000
+	_, err := capacityPoolService.UpdateCapacityPool(testOrganizerID, 1, models.CapacityPoolUpdate{Capacity: &capacity})
+	assert.Error(t, err, "expected error when capacity is less than used")
+	restErr, ok := err.(errors.RestError)
+	assert.True(t, ok, "expected a RestError")
+	assert.Equal(t, 400, restErr.Status)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPurchaseTicket_TakesPoolCapacity(t *testing.T) {
+	ticketService, mock := setupTest(t)
+
+	poolID := 5
+
+	mock.ExpectBegin()
+
+	mock.ExpectQuery("SELECT (.+) FROM ticket WHERE id = (.+) FOR UPDATE").
+		WithArgs(1, testOrganizerID).
+		WillReturnRows(newTicketRows(&models.Ticket{ID: 1, Name: "Standing", Allocation: 100, CapacityPoolID: &poolID}))
+
+	expectWaitlistDemand(mock, 1, 0)
+
+	mock.ExpectExec("UPDATE capacity_pool SET used = used \\+ (.+) AND used \\+ (.+) <= capacity").
+		WithArgs(2, poolID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	mock.ExpectExec("UPDATE ticket SET allocation").
+		WithArgs(98, 0, 2, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	mock.ExpectQuery("INSERT INTO purchase").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(7, time.Now()))
+
+	expectTicketInstances(mock, 7)
+
+	mock.ExpectCommit()
+
+	_, err := ticketService.PurchaseTicket(testOrganizerID, 1, models.PurchaseRequest{BuyerID: "buyer", Quantity: 2})
+	assert.NoError(t, err, "failed to purchase ticket")
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPurchaseTicket_PoolFull(t *testing.T) {
+	ticketService, mock := setupTest(t)
+
+	poolID := 5
+
+	mock.ExpectBegin()
+
+	mock.ExpectQuery("SELECT (.+) FROM ticket WHERE id = (.+) FOR UPDATE").
+		WithArgs(1, testOrganizerID).
+		WillReturnRows(newTicketRows(&models.Ticket{ID: 1, Name: "Standing", Allocation: 100, CapacityPoolID: &poolID}))
+
+	expectWaitlistDemand(mock, 1, 0)
+
+	mock.ExpectExec("UPDATE capacity_pool SET used = used \\+").
+		WithArgs(2, poolID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	mock.ExpectRollback()
+
+	_, err := ticketService.PurchaseTicket(testOrganizerID, 1, models.PurchaseRequest{BuyerID: "buyer", Quantity: 2})
+	assert.Error(t, err, "expected error when the pool is full")
+	restErr, ok := err.(errors.RestError)
+	assert.True(t, ok, "expected a RestError")
+	assert.Equal(t, 400, restErr.Status)
+	assert.Contains(t, restErr.Message, "venue capacity is reached")
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReleaseHold_ReturnsPoolCapacity(t *testing.T) {
+	holdService, mock := setupHoldTest(t)
+
+	poolID := 5
+
+	mock.ExpectBegin()
+
+	mock.ExpectQuery("SELECT (.+) FROM ticket_hold WHERE id = (.+) FOR UPDATE").
+		WithArgs(3, testOrganizerID).
+		WillReturnRows(newHoldRows(&models.Hold{ID: 3, TicketID: 1, BuyerID: "buyer", Quantity: 2, Status: models.HoldStatusActive}))
+
+	mock.ExpectQuery("SELECT (.+) FROM ticket WHERE id = (.+) FOR UPDATE").
+		WithArgs(1, testOrganizerID).
+		WillReturnRows(newTicketRows(&models.Ticket{ID: 1, Name: "Standing", Allocation: 0, Held: 2, CapacityPoolID: &poolID}))
+
+	mock.ExpectExec("UPDATE ticket SET allocation").
+		WithArgs(2, 0, 0, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	mock.ExpectExec("UPDATE capacity_pool SET used = used - ").
+		WithArgs(2, poolID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	expectEmptyWaitlist(mock, 1)
+
+	mock.ExpectExec("UPDATE ticket_hold SET status").
+		WithArgs(models.HoldStatusReleased, 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	mock.ExpectCommit()
+
+	err := holdService.ReleaseHold(testOrganizerID, 3)
+	assert.NoError(t, err, "failed to release hold")
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateTicket_OtherOrganizerCapacityPool(t *testing.T) {
+	ticketService, mock := setupTest(t)
+
+	poolID := 5
+
+	mock.ExpectQuery("SELECT id FROM capacity_pool WHERE id = (.+) AND organizer_id = ").
+		WithArgs(poolID, testOrganizerID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	err := ticketService.CreateTicket(testOrganizerID, &models.Ticket{Name: "Standing", Allocation: 100, CapacityPoolID: &poolID})
+	assert.EqualError(t, err, "Capacity pool 5 does not exist", "expected the pool of another organizer to be rejected")
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateTicket_OtherOrganizerCapacityPool(t *testing.T) {
+	ticketService, mock := setupTest(t)
+
+	poolID := 5
+
+	mock.ExpectBegin()
+
+	mock.ExpectQuery("SELECT (.+) FROM ticket WHERE id = (.+) FOR UPDATE").
+		WithArgs(1, testOrganizerID).
+		WillReturnRows(newTicketRows(&models.Ticket{ID: 1, OrganizerID: testOrganizerID, Name: "Standing", Allocation: 100, Price: models.NewMoney(0, "EUR")}))
+
+	mock.ExpectQuery("SELECT id FROM capacity_pool WHERE id = (.+) AND organizer_id = ").
+		WithArgs(poolID, testOrganizerID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	mock.ExpectRollback()
+
+	_, err := ticketService.UpdateTicket(testOrganizerID, 1, models.TicketUpdate{
+		CapacityPoolID: models.OptionalInt{Set: true, Value: &poolID},
+	})
+	assert.EqualError(t, err, "Capacity pool 5 does not exist", "expected the pool of another organizer to be rejected")
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateTicket_MovesCapacityPoolInIDOrder(t *testing.T) {
+	ticketService, mock := setupTest(t)
+
+	oldPoolID, newPoolID := 7, 5
+
+	mock.ExpectBegin()
+
+	mock.ExpectQuery("SELECT (.+) FROM ticket WHERE id = (.+) FOR UPDATE").
+		WithArgs(1, testOrganizerID).
+		WillReturnRows(newTicketRows(&models.Ticket{
+			ID: 1, OrganizerID: testOrganizerID, Name: "Standing", Allocation: 90, Sold: 8, Held: 2, CapacityPoolID: &oldPoolID, Price: models.NewMoney(0, "EUR"),
+		}))
+
+	mock.ExpectQuery("SELECT id FROM capacity_pool WHERE id = (.+) AND organizer_id = ").
+		WithArgs(newPoolID, testOrganizerID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(newPoolID))
+
+	// Both pools are locked, the lower ID first, before either one changes.
+	mock.ExpectQuery("SELECT id FROM capacity_pool WHERE id = (.+) FOR UPDATE").
+		WithArgs(newPoolID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(newPoolID))
+	mock.ExpectQuery("SELECT id FROM capacity_pool WHERE id = (.+) FOR UPDATE").
+		WithArgs(oldPoolID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(oldPoolID))
+
+	mock.ExpectExec("UPDATE capacity_pool SET used = used - ").
+		WithArgs(10, oldPoolID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	mock.ExpectExec("UPDATE capacity_pool SET used = used \\+").
+		WithArgs(10, newPoolID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	mock.ExpectQuery("UPDATE ticket SET name").
+		WillReturnRows(sqlmock.NewRows([]string{"updated_at"}).AddRow(time.Now()))
+
+	expectEmptyWaitlist(mock, 1)
+
+	mock.ExpectCommit()
+
+	ticket, err := ticketService.UpdateTicket(testOrganizerID, 1, models.TicketUpdate{
+		CapacityPoolID: models.OptionalInt{Set: true, Value: &newPoolID},
+	})
+	assert.NoError(t, err, "failed to move the ticket")
+	assert.Equal(t, newPoolID, *ticket.CapacityPoolID)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
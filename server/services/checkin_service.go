@@ -0,0 +1,137 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"gowitcase/db"
+	"gowitcase/errors"
+	"gowitcase/models"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type CheckInService struct {
+	DB     db.DatabaseInterface
+	Cache  db.RedisInterface
+	Signer *TicketSigner
+}
+
+func NewCheckInService(db db.DatabaseInterface, cache db.RedisInterface, signer *TicketSigner) *CheckInService {
+	return &CheckInService{DB: db, Cache: cache, Signer: signer}
+}
+
+// CheckIn admits the ticket instance of a scanned token at a gate. Void instances
+// are turned away, and so is a second scan of an instance, with the time and gate
+// it was first admitted at. Tickets of other organizers are reported as not found.
+func (s *CheckInService) CheckIn(organizerID int, request models.CheckInRequest) (*models.CheckIn, error) {
+	gate := strings.TrimSpace(request.Gate)
+	if gate == "" {
+		return nil, errors.NewRestError("Gate is required", 400)
+	}
+	if len(gate) > models.CheckInGateMaxLength {
+		return nil, errors.NewRestError(fmt.Sprintf("Gate must be at most %d characters", models.CheckInGateMaxLength), 400)
+	}
+
+	code, err := s.Signer.Verify(request.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.DB.BeginTransaction()
+	if err != nil {
+		log.Printf("Failed to start transaction: %v", err)
+		return nil, fmt.Errorf("failed to start transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	// The instance lock serializes scans of the same ticket at different gates.
+	checkIn := &models.CheckIn{Gate: gate}
+	var status string
+	err = tx.QueryRow(
+		"SELECT id, ticket_id, purchase_id, seat_id, status FROM ticket_instance "+
+			"WHERE code = $1 AND ticket_id IN (SELECT id FROM ticket WHERE organizer_id = $2) FOR UPDATE",
+		code, organizerID,
+	).Scan(&checkIn.TicketInstanceID, &checkIn.TicketID, &checkIn.PurchaseID, &checkIn.SeatID, &status)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NewRestError("Ticket not found", 404)
+		}
+		return nil, fmt.Errorf("failed to get ticket instance: %v", err)
+	}
+
+	if status != models.TicketInstanceStatusValid {
+		return nil, errors.NewRestError(fmt.Sprintf("Ticket instance %d is %s", checkIn.TicketInstanceID, status), 400).WithCode(errors.CodeTicketNotValid)
+	}
+
+	var checkedInGate string
+	var checkedInAt time.Time
+	err = tx.QueryRow(
+		"SELECT gate, created_at FROM checkin WHERE ticket_instance_id = $1",
+		checkIn.TicketInstanceID,
+	).Scan(&checkedInGate, &checkedInAt)
+	if err == nil {
+		return nil, errors.NewRestError(
+			fmt.Sprintf("Ticket already checked in at %s at gate %s", checkedInAt.UTC().Format(time.RFC3339), checkedInGate), 409,
+		).WithCode(errors.CodeAlreadyCheckedIn).WithDetail("checked_in_at", checkedInAt.UTC()).WithDetail("gate", checkedInGate)
+	}
+	if err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to get check-in: %v", err)
+	}
+
+	err = tx.QueryRow(
+		"INSERT INTO checkin (ticket_instance_id, ticket_id, gate) VALUES ($1, $2, $3) RETURNING id, created_at",
+		checkIn.TicketInstanceID, checkIn.TicketID, checkIn.Gate,
+	).Scan(&checkIn.ID, &checkIn.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create check-in: %v", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	if err := s.Cache.Del(checkInCountCacheKey(organizerID, checkIn.TicketID)); err != nil {
+		log.Printf("Failed to invalidate check-in count cache: %v", err)
+	}
+
+	return checkIn, nil
+}
+
+// GetCheckInCount returns how many instances of a ticket are checked in. The count
+// is cached until the next check-in of the ticket.
+func (s *CheckInService) GetCheckInCount(organizerID int, ticketID int) (*models.CheckInCount, error) {
+	count := &models.CheckInCount{TicketID: ticketID}
+
+	cached, err := s.Cache.Get(checkInCountCacheKey(organizerID, ticketID))
+	if err == nil {
+		count.CheckedIn, err = strconv.Atoi(cached)
+		if err == nil {
+			return count, nil
+		}
+	}
+
+	var id int
+	err = s.DB.QueryRow(
+		"SELECT t.id, (SELECT COUNT(*) FROM checkin c WHERE c.ticket_id = t.id) FROM ticket t WHERE t.id = $1 AND t.organizer_id = $2",
+		ticketID, organizerID,
+	).Scan(&id, &count.CheckedIn)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NewRestError(fmt.Sprintf("Ticket %d not found", ticketID), 404)
+		}
+		return nil, fmt.Errorf("failed to count check-ins: %v", err)
+	}
+
+	if err := s.Cache.Set(checkInCountCacheKey(organizerID, ticketID), strconv.Itoa(count.CheckedIn), 5*time.Minute); err != nil {
+		log.Printf("Failed to cache check-in count: %v", err)
+	}
+
+	return count, nil
+}
+
+func checkInCountCacheKey(organizerID int, ticketID int) string {
+	return models.CheckInCountCachePrefix + strconv.Itoa(organizerID) + ":" + strconv.Itoa(ticketID)
+}
@@ -0,0 +1,137 @@
+package services_test
+
+import (
+	"gowitcase/errors"
+	"gowitcase/mocks"
+	"gowitcase/models"
+	"gowitcase/services"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupCheckInTest(t *testing.T) (*services.CheckInService, sqlmock.Sqlmock, *mocks.MockRedis) {
+	mockDB, mock, err := mocks.NewMockDatabase()
+	assert.NoError(t, err)
+
+	mockRedis := mocks.NewMockRedis()
+	checkInService := services.NewCheckInService(mockDB, mockRedis, newTestSigner(t))
+
+	return checkInService, mock, mockRedis
+}
+
+func newInstanceLockRows(status string) *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"id", "ticket_id", "purchase_id", "seat_id", "status"}).
+		AddRow(5, 1, 7, nil, status)
+}
+
+func TestCheckIn_Success(t *testing.T) {
+	checkInService, mock, mockRedis := setupCheckInTest(t)
+	mockRedis.Set("checkins:count:9:1", "3", 0)
+
+	mock.ExpectBegin()
+
+	mock.ExpectQuery("SELECT (.+) FROM ticket_instance WHERE code = (.+) FOR UPDATE").
+		WithArgs("code", testOrganizerID).
+		WillReturnRows(newInstanceLockRows(models.TicketInstanceStatusValid))
+
+	mock.ExpectQuery("SELECT gate, created_at FROM checkin").
+		WithArgs(5).
+		WillReturnRows(sqlmock.NewRows([]string{"gate", "created_at"}))
+
+	mock.ExpectQuery("INSERT INTO checkin").
+		WithArgs(5, 1, "North").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(1, time.Now()))
+
+	mock.ExpectCommit()
+
+	checkIn, err := checkInService.CheckIn(testOrganizerID, models.CheckInRequest{Token: newTestSigner(t).Sign("code"), Gate: " North "})
+	assert.NoError(t, err, "failed to check in")
+	assert.Equal(t, 5, checkIn.TicketInstanceID)
+	assert.Equal(t, "North", checkIn.Gate)
+
+	_, err = mockRedis.Get("checkins:count:9:1")
+	assert.Error(t, err, "expected the check-in count cache to be invalidated")
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCheckIn_AlreadyCheckedIn(t *testing.T) {
+	checkInService, mock, _ := setupCheckInTest(t)
+
+	checkedInAt := time.Date(2026, 5, 1, 19, 30, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+
+	mock.ExpectQuery("SELECT (.+) FROM ticket_instance WHERE code = (.+) FOR UPDATE").
+		WithArgs("code", testOrganizerID).
+		WillReturnRows(newInstanceLockRows(models.TicketInstanceStatusValid))
+
+	mock.ExpectQuery("SELECT gate, created_at FROM checkin").
+		WithArgs(5).
+		WillReturnRows(sqlmock.NewRows([]string{"gate", "created_at"}).AddRow("South", checkedInAt))
+
+	mock.ExpectRollback()
+
+	_, err := checkInService.CheckIn(testOrganizerID, models.CheckInRequest{Token: newTestSigner(t).Sign("code"), Gate: "North"})
+	assert.Error(t, err, "expected error for a second check-in")
+	restErr, ok := err.(errors.RestError)
+	assert.True(t, ok, "expected a RestError")
+	assert.Equal(t, 409, restErr.Status)
+	assert.Equal(t, "Ticket already checked in at 2026-05-01T19:30:00Z at gate South", restErr.Message)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCheckIn_VoidInstance(t *testing.T) {
+	checkInService, mock, _ := setupCheckInTest(t)
+
+	mock.ExpectBegin()
+
+	mock.ExpectQuery("SELECT (.+) FROM ticket_instance WHERE code = (.+) FOR UPDATE").
+		WithArgs("code", testOrganizerID).
+		WillReturnRows(newInstanceLockRows(models.TicketInstanceStatusVoid))
+
+	mock.ExpectRollback()
+
+	_, err := checkInService.CheckIn(testOrganizerID, models.CheckInRequest{Token: newTestSigner(t).Sign("code"), Gate: "North"})
+	assert.Error(t, err, "expected error for a refunded ticket")
+	restErr, ok := err.(errors.RestError)
+	assert.True(t, ok, "expected a RestError")
+	assert.Equal(t, 400, restErr.Status)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCheckIn_InvalidToken(t *testing.T) {
+	checkInService, mock, _ := setupCheckInTest(t)
+
+	token := newTestSigner(t).Sign("code")
+	_, err := checkInService.CheckIn(testOrganizerID, models.CheckInRequest{Token: token[:len(token)-1], Gate: "North"})
+	assert.Error(t, err, "expected error for a forged token")
+	restErr, ok := err.(errors.RestError)
+	assert.True(t, ok, "expected a RestError")
+	assert.Equal(t, 400, restErr.Status)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetCheckInCount_CachesCount(t *testing.T) {
+	checkInService, mock, _ := setupCheckInTest(t)
+
+	mock.ExpectQuery("SELECT (.+) FROM ticket t WHERE t.id = (.+) AND t.organizer_id = (.+)").
+		WithArgs(1, testOrganizerID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "count"}).AddRow(1, 42))
+
+	count, err := checkInService.GetCheckInCount(testOrganizerID, 1)
+	assert.NoError(t, err, "failed to count check-ins")
+	assert.Equal(t, 42, count.CheckedIn)
+
+	count, err = checkInService.GetCheckInCount(testOrganizerID, 1)
+	assert.NoError(t, err, "failed to count check-ins")
+	assert.Equal(t, 42, count.CheckedIn, "expected the cached count")
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
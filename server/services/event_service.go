@@ -0,0 +1,395 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"gowitcase/db"
+	"gowitcase/errors"
+	"gowitcase/models"
+	"log"
+	"strconv"
+	"time"
+)
+
+const eventColumns = "id, organizer_id, name, description, venue, starts_at, ends_at, created_at, updated_at, archived_at"
+
+// cachedEvent is what GetEvent keeps in Redis. Only references to the ticket types
+// are cached with the event, the tickets themselves come from their own cache
+// entries, so purchases never have to invalidate the event.
+type cachedEvent struct {
+	Event       models.Event `json:"event"`
+	TicketTypes []ticketRef  `json:"ticket_types"`
+}
+
+// ticketRef is what it takes to find a ticket in the cache, which is namespaced
+// per organizer.
+type ticketRef struct {
+	ID          int `json:"id"`
+	OrganizerID int `json:"organizer_id"`
+}
+
+type EventService struct {
+	DB    db.DatabaseInterface
+	Cache db.RedisInterface
+}
+
+func NewEventService(db db.DatabaseInterface, cache db.RedisInterface) *EventService {
+	return &EventService{DB: db, Cache: cache}
+}
+
+func (s *EventService) CreateEvent(organizerID int, event *models.Event) error {
+	if event == nil {
+		return fmt.Errorf("event is nil")
+	}
+
+	event.OrganizerID = organizerID
+	err := s.ValidateEvent(*event)
+	if err != nil {
+		return err
+	}
+
+	err = s.DB.QueryRow(
+		"INSERT INTO event (organizer_id, name, description, venue, starts_at, ends_at) VALUES ($1, $2, $3, $4, $5, $6) "+
+			"RETURNING id, created_at, updated_at",
+		event.OrganizerID, event.Name, event.Description, event.Venue, event.StartsAt, event.EndsAt,
+	).Scan(&event.ID, &event.CreatedAt, &event.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create event: %v", err)
+	}
+
+	event.TicketTypes = nil
+
+	return nil
+}
+
+// GetEvent returns the event of the organizer with all of its ticket types that
+// aren't archived. Tickets found in the ticket cache are served from it and the
+// rest are loaded in a single query.
+func (s *EventService) GetEvent(organizerID int, id int) (*models.Event, error) {
+	entry, err := s.getCacheEvent(organizerID, id)
+	if err == nil && entry != nil {
+		log.Printf("Cache hit for event: %d", id)
+	} else {
+		entry, err = s.loadEvent(organizerID, id)
+		if err != nil {
+			return nil, err
+		}
+
+		err = s.cacheEvent(entry)
+		if err != nil {
+			log.Printf("Failed to cache event: %v", err)
+		}
+	}
+
+	event := entry.Event
+	event.TicketTypes, err = s.getTicketTypes(organizerID, entry.TicketTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &event, nil
+}
+
+// UpdateEvent applies the non-nil fields of update to the event.
+func (s *EventService) UpdateEvent(organizerID int, id int, update models.EventUpdate) (*models.Event, error) {
+	tx, err := s.DB.BeginTransaction()
+	if err != nil {
+		log.Printf("Failed to start transaction: %v", err)
+		return nil, fmt.Errorf("failed to start transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	event := &models.Event{}
+	err = scanEvent(tx.QueryRow("SELECT "+eventColumns+" FROM event WHERE id = $1 AND organizer_id = $2 FOR UPDATE", id, organizerID), event)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NewRestError(fmt.Sprintf("Event %d not found", id), 404)
+		}
+		return nil, fmt.Errorf("failed to get event: %v", err)
+	}
+
+	if event.ArchivedAt != nil {
+		return nil, errors.NewRestError(fmt.Sprintf("Event %d is archived", id), 400).WithCode(errors.CodeEventArchived)
+	}
+
+	if update.Name != nil {
+		event.Name = *update.Name
+	}
+	if update.Description != nil {
+		event.Description = *update.Description
+	}
+	if update.Venue != nil {
+		event.Venue = *update.Venue
+	}
+	if update.StartsAt.Set {
+		event.StartsAt = update.StartsAt.Value
+	}
+	if update.EndsAt.Set {
+		event.EndsAt = update.EndsAt.Value
+	}
+
+	err = s.ValidateEvent(*event)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.QueryRow(
+		"UPDATE event SET name = $1, description = $2, venue = $3, starts_at = $4, ends_at = $5, updated_at = CURRENT_TIMESTAMP "+
+			"WHERE id = $6 RETURNING updated_at",
+		event.Name, event.Description, event.Venue, event.StartsAt, event.EndsAt, event.ID,
+	).Scan(&event.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update event: %v", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	err = invalidateEventCache(s.Cache, organizerID, id)
+	if err != nil {
+		log.Printf("Failed to invalidate cache: %v for event: %d", err, id)
+	}
+
+	return event, nil
+}
+
+// ArchiveEvent archives the event of the organizer together with its ticket types,
+// which stops their sales. Like archiving a ticket, it is idempotent and keeps the rows.
+func (s *EventService) ArchiveEvent(organizerID int, id int) error {
+	tx, err := s.DB.BeginTransaction()
+	if err != nil {
+		log.Printf("Failed to start transaction: %v", err)
+		return fmt.Errorf("failed to start transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	var archivedAt time.Time
+	err = tx.QueryRow(
+		"UPDATE event SET archived_at = COALESCE(archived_at, CURRENT_TIMESTAMP), updated_at = CURRENT_TIMESTAMP "+
+			"WHERE id = $1 AND organizer_id = $2 RETURNING archived_at",
+		id, organizerID,
+	).Scan(&archivedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errors.NewRestError(fmt.Sprintf("Event %d not found", id), 404)
+		}
+		return fmt.Errorf("failed to archive event: %v", err)
+	}
+
+	rows, err := tx.Query(
+		"UPDATE ticket SET archived_at = $1, updated_at = CURRENT_TIMESTAMP "+
+			"WHERE event_id = $2 AND organizer_id = $3 AND archived_at IS NULL RETURNING id, organizer_id",
+		archivedAt, id, organizerID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to archive ticket types: %v", err)
+	}
+
+	var archived []ticketRef
+	for rows.Next() {
+		var ref ticketRef
+		if err := rows.Scan(&ref.ID, &ref.OrganizerID); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan ticket ID: %v", err)
+		}
+		archived = append(archived, ref)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to archive ticket types: %v", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	for _, ref := range archived {
+		err = invalidateTicketCache(s.Cache, ref.OrganizerID, ref.ID)
+		if err != nil {
+			log.Printf("Failed to invalidate cache: %v for ticket: %d", err, ref.ID)
+		}
+	}
+
+	err = invalidateEventCache(s.Cache, organizerID, id)
+	if err != nil {
+		log.Printf("Failed to invalidate cache: %v for event: %d", err, id)
+	}
+
+	return nil
+}
+
+// ListEvents returns the events of the organizer that aren't archived, oldest
+// first. The cursor is the next_cursor of the previous page.
+func (s *EventService) ListEvents(organizerID int, cursor string, limit int) (*models.EventList, error) {
+	if limit < 0 {
+		return nil, errors.NewRestError("Limit must be a positive number", 400)
+	}
+	if limit == 0 {
+		limit = models.EventListDefaultLimit
+	}
+	if limit > models.EventListMaxLimit {
+		limit = models.EventListMaxLimit
+	}
+
+	afterID := 0
+	if cursor != "" {
+		var err error
+		afterID, err = decodeIDCursor(cursor)
+		if err != nil {
+			return nil, errors.NewRestError("Invalid cursor", 400)
+		}
+	}
+
+	// One extra row tells whether there is a next page.
+	rows, err := s.DB.Query(
+		"SELECT "+eventColumns+" FROM event WHERE organizer_id = $1 AND archived_at IS NULL AND id > $2 ORDER BY id LIMIT $3",
+		organizerID, afterID, limit+1,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list events: %v", err)
+	}
+	defer rows.Close()
+
+	list := &models.EventList{Events: []models.Event{}}
+	for rows.Next() {
+		event := models.Event{}
+		if err := scanEvent(rows, &event); err != nil {
+			return nil, fmt.Errorf("failed to scan event: %v", err)
+		}
+		list.Events = append(list.Events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list events: %v", err)
+	}
+
+	if len(list.Events) > limit {
+		list.Events = list.Events[:limit]
+		list.NextCursor = encodeIDCursor(list.Events[limit-1].ID)
+	}
+
+	return list, nil
+}
+
+// ValidateEvent checks every field of the event and reports all the invalid ones.
+func (s *EventService) ValidateEvent(event models.Event) error {
+	var fields errors.FieldErrors
+
+	if event.Name == "" {
+		fields.Add("name", "Field 'name' is required")
+	} else if len(event.Name) > 255 {
+		fields.Add("name", "Field 'name' must be less than 255 characters")
+	}
+
+	if len(event.Venue) > 255 {
+		fields.Add("venue", "Field 'venue' must be less than 255 characters")
+	}
+
+	if event.StartsAt != nil && event.EndsAt != nil && !event.EndsAt.After(*event.StartsAt) {
+		fields.Add("ends_at", "Field 'ends_at' must be after 'starts_at'")
+	}
+
+	return fields.Err()
+}
+
+func (s *EventService) loadEvent(organizerID int, id int) (*cachedEvent, error) {
+	entry := &cachedEvent{TicketTypes: []ticketRef{}}
+
+	err := scanEvent(s.DB.QueryRow("SELECT "+eventColumns+" FROM event WHERE id = $1 AND organizer_id = $2", id, organizerID), &entry.Event)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NewRestError(fmt.Sprintf("Event %d not found", id), 404)
+		}
+		return nil, fmt.Errorf("failed to get event: %v", err)
+	}
+
+	rows, err := s.DB.Query(
+		"SELECT id, organizer_id FROM ticket WHERE event_id = $1 AND organizer_id = $2 AND archived_at IS NULL ORDER BY id",
+		id, organizerID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ticket types: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var ref ticketRef
+		if err := rows.Scan(&ref.ID, &ref.OrganizerID); err != nil {
+			return nil, fmt.Errorf("failed to scan ticket ID: %v", err)
+		}
+		entry.TicketTypes = append(entry.TicketTypes, ref)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list ticket types: %v", err)
+	}
+
+	return entry, nil
+}
+
+// getTicketTypes returns the organizer's tickets in the order of refs, with the
+// same batched lookup as TicketService.GetTickets.
+func (s *EventService) getTicketTypes(organizerID int, refs []ticketRef) ([]models.Ticket, error) {
+	ids := make([]int, len(refs))
+	for i, ref := range refs {
+		ids[i] = ref.ID
+	}
+
+	tickets, err := getTicketsByID(s.DB, s.Cache, organizerID, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	ticketTypes := make([]models.Ticket, 0, len(refs))
+	for _, ref := range refs {
+		ticket, ok := tickets[ref.ID]
+		if !ok {
+			continue
+		}
+		ticketTypes = append(ticketTypes, *ticket)
+	}
+
+	return ticketTypes, nil
+}
+
+func scanEvent(row rowScanner, event *models.Event) error {
+	return row.Scan(
+		&event.ID, &event.OrganizerID, &event.Name, &event.Description, &event.Venue, &event.StartsAt, &event.EndsAt,
+		&event.CreatedAt, &event.UpdatedAt, &event.ArchivedAt,
+	)
+}
+
+func (s *EventService) cacheEvent(entry *cachedEvent) error {
+	entryBytes, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %v", err)
+	}
+	return s.Cache.Set(eventCacheKey(entry.Event.OrganizerID, entry.Event.ID), string(entryBytes), 5*time.Minute)
+}
+
+func (s *EventService) getCacheEvent(organizerID int, eventID int) (*cachedEvent, error) {
+	entry := &cachedEvent{}
+	entryJSON, err := s.Cache.Get(eventCacheKey(organizerID, eventID))
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal([]byte(entryJSON), entry)
+	if err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+func eventCacheKey(organizerID int, eventID int) string {
+	return models.EventCachePrefix + strconv.Itoa(organizerID) + ":" + strconv.Itoa(eventID)
+}
+
+// invalidateEventCache drops the cached event, so its list of ticket types is
+// loaded again on the next read.
+func invalidateEventCache(cache db.RedisInterface, organizerID int, eventID int) error {
+	return cache.Del(eventCacheKey(organizerID, eventID))
+}
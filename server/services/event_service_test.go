@@ -0,0 +1,238 @@
+package services_test
+
+import (
+	"database/sql"
+	"gowitcase/mocks"
+	"gowitcase/models"
+	"gowitcase/services"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupEventTest(t *testing.T) (*services.EventService, sqlmock.Sqlmock) {
+	mockDB, mock, err := mocks.NewMockDatabase()
+	assert.NoError(t, err)
+
+	eventService := services.NewEventService(mockDB, mocks.NewMockRedis())
+
+	return eventService, mock
+}
+
+func newEventRows(events ...*models.Event) *sqlmock.Rows {
+	rows := sqlmock.NewRows([]string{
+		"id", "organizer_id", "name", "description", "venue", "starts_at", "ends_at", "created_at", "updated_at", "archived_at",
+	})
+	for _, event := range events {
+		rows.AddRow(
+			event.ID, event.OrganizerID, event.Name, event.Description, event.Venue, event.StartsAt, event.EndsAt,
+			event.CreatedAt, event.UpdatedAt, event.ArchivedAt,
+		)
+	}
+	return rows
+}
+
+func TestCreateEvent_Success(t *testing.T) {
+	eventService, mock := setupEventTest(t)
+
+	mock.ExpectQuery("INSERT INTO event").
+		WithArgs(testOrganizerID, "festival", "summer festival", "park", nil, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow(1, time.Now(), time.Now()))
+
+	event := &models.Event{Name: "festival", Description: "summer festival", Venue: "park"}
+	err := eventService.CreateEvent(testOrganizerID, event)
+	assert.NoError(t, err, "failed to create event")
+	assert.Equal(t, 1, event.ID, "expected event ID 1")
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
+
+func TestCreateEvent_MissingName(t *testing.T) {
+	eventService, _ := setupEventTest(t)
+
+	err := eventService.CreateEvent(testOrganizerID, &models.Event{Venue: "park"})
+	assert.Error(t, err, "expected error when name is missing")
+}
+
+func TestCreateEvent_EndsBeforeStart(t *testing.T) {
+	eventService, _ := setupEventTest(t)
+
+	startsAt := time.Date(2024, 6, 1, 18, 0, 0, 0, time.UTC)
+	endsAt := startsAt.Add(-time.Hour)
+
+	err := eventService.CreateEvent(testOrganizerID, &models.Event{Name: "festival", StartsAt: &startsAt, EndsAt: &endsAt})
+	assert.Error(t, err, "expected error when event ends before it starts")
+}
+
+func TestGetEvent_WithTicketTypes(t *testing.T) {
+	eventService, mock := setupEventTest(t)
+
+	eventID := 1
+	event := &models.Event{ID: eventID, OrganizerID: testOrganizerID, Name: "festival"}
+	ga := &models.Ticket{ID: 2, OrganizerID: testOrganizerID, EventID: &eventID, Name: "GA", Allocation: 100}
+	vip := &models.Ticket{ID: 3, OrganizerID: testOrganizerID, EventID: &eventID, Name: "VIP", Allocation: 10}
+
+	mock.ExpectQuery("SELECT (.+) FROM event WHERE id = (.+) AND organizer_id = ").
+		WithArgs(eventID, testOrganizerID).
+		WillReturnRows(newEventRows(event))
+
+	mock.ExpectQuery("SELECT id, organizer_id FROM ticket WHERE event_id = (.+) AND organizer_id = ").
+		WithArgs(eventID, testOrganizerID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "organizer_id"}).AddRow(ga.ID, testOrganizerID).AddRow(vip.ID, testOrganizerID))
+
+	mock.ExpectQuery("SELECT (.+) FROM ticket WHERE id = ANY").
+		WithArgs("{2,3}", testOrganizerID).
+		WillReturnRows(newTicketRows(vip, ga))
+
+	returnedEvent, err := eventService.GetEvent(testOrganizerID, eventID)
+	assert.NoError(t, err, "failed to get event")
+	assert.Len(t, returnedEvent.TicketTypes, 2, "expected both ticket types")
+	assert.Equal(t, "GA", returnedEvent.TicketTypes[0].Name, "expected ticket types in ID order")
+	assert.Equal(t, 10, returnedEvent.TicketTypes[1].Allocation, "expected remaining allocation of the VIP tickets")
+
+	// Served from the event and ticket caches without hitting the database.
+	returnedEvent, err = eventService.GetEvent(testOrganizerID, eventID)
+	assert.NoError(t, err, "failed to get event")
+	assert.Len(t, returnedEvent.TicketTypes, 2, "expected both ticket types from cache")
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
+
+func TestGetEvent_ReadsTicketCache(t *testing.T) {
+	mockDB, mock, err := mocks.NewMockDatabase()
+	assert.NoError(t, err)
+
+	mockRedis := mocks.NewMockRedis()
+	ticketService := services.NewTicketService(mockDB, mockRedis)
+	eventService := services.NewEventService(mockDB, mockRedis)
+
+	eventID := 1
+	ga := &models.Ticket{ID: 2, OrganizerID: testOrganizerID, EventID: &eventID, Name: "GA", Allocation: 100}
+	vip := &models.Ticket{ID: 3, OrganizerID: testOrganizerID, EventID: &eventID, Name: "VIP", Allocation: 10}
+
+	mock.ExpectQuery("SELECT (.+) FROM ticket WHERE id = ").
+		WithArgs(ga.ID, testOrganizerID).
+		WillReturnRows(newTicketRows(ga))
+
+	_, err = ticketService.GetTicket(testOrganizerID, ga.ID)
+	assert.NoError(t, err, "failed to get ticket")
+
+	mock.ExpectQuery("SELECT (.+) FROM event WHERE id = (.+) AND organizer_id = ").
+		WithArgs(eventID, testOrganizerID).
+		WillReturnRows(newEventRows(&models.Event{ID: eventID, OrganizerID: testOrganizerID, Name: "festival"}))
+
+	mock.ExpectQuery("SELECT id, organizer_id FROM ticket WHERE event_id = (.+) AND organizer_id = ").
+		WithArgs(eventID, testOrganizerID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "organizer_id"}).AddRow(ga.ID, testOrganizerID).AddRow(vip.ID, testOrganizerID))
+
+	mock.ExpectQuery("SELECT (.+) FROM ticket WHERE id = ANY").
+		WithArgs("{3}", testOrganizerID).
+		WillReturnRows(newTicketRows(vip))
+
+	event, err := eventService.GetEvent(testOrganizerID, eventID)
+	assert.NoError(t, err, "failed to get event")
+	assert.Len(t, event.TicketTypes, 2, "expected both ticket types")
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
+
+func TestGetEvent_NotFound(t *testing.T) {
+	eventService, mock := setupEventTest(t)
+
+	mock.ExpectQuery("SELECT (.+) FROM event WHERE id = (.+) AND organizer_id = ").
+		WithArgs(1, testOrganizerID).
+		WillReturnError(sql.ErrNoRows)
+
+	_, err := eventService.GetEvent(testOrganizerID, 1)
+	assert.Error(t, err, "expected error when event not found")
+}
+
+func TestUpdateEvent_Archived(t *testing.T) {
+	eventService, mock := setupEventTest(t)
+
+	archivedAt := time.Now()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT (.+) FROM event WHERE id = (.+) FOR UPDATE").
+		WithArgs(1, testOrganizerID).
+		WillReturnRows(newEventRows(&models.Event{ID: 1, Name: "festival", ArchivedAt: &archivedAt}))
+	mock.ExpectRollback()
+
+	name := "renamed"
+	_, err := eventService.UpdateEvent(testOrganizerID, 1, models.EventUpdate{Name: &name})
+	assert.EqualError(t, err, "Event 1 is archived", "expected error when event is archived")
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
+
+func TestArchiveEvent_ArchivesTicketTypes(t *testing.T) {
+	eventService, mock := setupEventTest(t)
+
+	archivedAt := time.Now()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("UPDATE event SET archived_at").
+		WithArgs(1, testOrganizerID).
+		WillReturnRows(sqlmock.NewRows([]string{"archived_at"}).AddRow(archivedAt))
+	mock.ExpectQuery("UPDATE ticket SET archived_at (.+) WHERE event_id = (.+) AND organizer_id = ").
+		WithArgs(archivedAt, 1, testOrganizerID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "organizer_id"}).AddRow(2, testOrganizerID).AddRow(3, testOrganizerID))
+	mock.ExpectCommit()
+
+	err := eventService.ArchiveEvent(testOrganizerID, 1)
+	assert.NoError(t, err, "failed to archive event")
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
+
+func TestListEvents_Pagination(t *testing.T) {
+	eventService, mock := setupEventTest(t)
+
+	first := &models.Event{ID: 1, Name: "first"}
+	second := &models.Event{ID: 2, Name: "second"}
+
+	mock.ExpectQuery("SELECT (.+) FROM event WHERE organizer_id = (.+) AND archived_at IS NULL").
+		WithArgs(testOrganizerID, 0, 2).
+		WillReturnRows(newEventRows(first, second))
+
+	list, err := eventService.ListEvents(testOrganizerID, "", 1)
+	assert.NoError(t, err, "failed to list events")
+	assert.Equal(t, []models.Event{*first}, list.Events, "expected first page of events")
+	assert.NotEmpty(t, list.NextCursor, "expected next cursor")
+
+	mock.ExpectQuery("SELECT (.+) FROM event WHERE organizer_id = (.+) AND archived_at IS NULL").
+		WithArgs(testOrganizerID, first.ID, 2).
+		WillReturnRows(newEventRows(second))
+
+	list, err = eventService.ListEvents(testOrganizerID, list.NextCursor, 1)
+	assert.NoError(t, err, "failed to list events")
+	assert.Equal(t, []models.Event{*second}, list.Events, "expected second page of events")
+	assert.Empty(t, list.NextCursor, "expected no next cursor on last page")
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
+
+func TestArchiveEvent_OtherOrganizer(t *testing.T) {
+	eventService, mock := setupEventTest(t)
+
+	// The tickets of the event are left alone when it isn't the organizer's.
+	mock.ExpectBegin()
+	mock.ExpectQuery("UPDATE event SET archived_at (.+) WHERE id = (.+) AND organizer_id = ").
+		WithArgs(1, testOrganizerID).
+		WillReturnRows(sqlmock.NewRows([]string{"archived_at"}))
+	mock.ExpectRollback()
+
+	err := eventService.ArchiveEvent(testOrganizerID, 1)
+	assert.EqualError(t, err, "Event 1 not found", "expected the event to be reported as not found")
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
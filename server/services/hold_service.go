@@ -0,0 +1,384 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"gowitcase/db"
+	"gowitcase/errors"
+	"gowitcase/models"
+	"log"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const holdColumns = "id, ticket_id, buyer_id, quantity, unit_price, currency, status, expires_at, purchase_id, seat_ids, created_at"
+
+type HoldService struct {
+	DB    db.DatabaseInterface
+	Cache db.RedisInterface
+}
+
+func NewHoldService(db db.DatabaseInterface, cache db.RedisInterface) *HoldService {
+	return &HoldService{DB: db, Cache: cache}
+}
+
+func (s *HoldService) CreateHold(organizerID int, ticketID int, request models.HoldRequest) (*models.Hold, error) {
+	quantity, err := validatePurchaseRequest(request.BuyerID, request.Quantity, request.SeatIDs)
+	if err != nil {
+		return nil, err
+	}
+	request.Quantity = quantity
+
+	tx, err := s.DB.BeginTransaction()
+	if err != nil {
+		log.Printf("Failed to start transaction: %v", err)
+		return nil, fmt.Errorf("failed to start transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	ticket, err := lockOrganizerTicket(tx, organizerID, ticketID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkAvailability(tx, ticket, request.BuyerID, request.Quantity)
+	if err != nil {
+		return nil, err
+	}
+
+	err = lockSeats(tx, ticket, request.SeatIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	err = takePoolCapacity(tx, ticket.CapacityPoolID, request.Quantity)
+	if err != nil {
+		return nil, err
+	}
+
+	ticket.Allocation -= request.Quantity
+	ticket.Held += request.Quantity
+
+	err = updateTicketInventory(tx, ticket)
+	if err != nil {
+		return nil, err
+	}
+
+	hold, err := insertHold(tx, ticket, request.BuyerID, request.Quantity, request.SeatIDs, models.HoldDuration)
+	if err != nil {
+		return nil, err
+	}
+
+	if ticket.Seated {
+		err = assignSeats(tx, hold.SeatIDs, models.SeatStatusHeld, &hold.ID, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	err = invalidateTicketCache(s.Cache, ticket.OrganizerID, ticketID)
+	if err != nil {
+		log.Printf("Failed to invalidate cache: %v for ticket: %d", err, ticketID)
+	}
+
+	return hold, nil
+}
+
+func (s *HoldService) GetHold(organizerID int, id int) (*models.Hold, error) {
+	hold := &models.Hold{}
+
+	err := scanHold(s.DB.QueryRow(
+		"SELECT "+holdColumns+" FROM ticket_hold WHERE id = $1 AND ticket_id IN (SELECT id FROM ticket WHERE organizer_id = $2)",
+		id, organizerID,
+	), hold)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NewRestError(fmt.Sprintf("Hold %d not found", id), 404)
+		}
+		return nil, err
+	}
+
+	return hold, nil
+}
+
+// ConfirmHold turns an active hold into a purchase at the price of the hold. The
+// tickets already left the allocation when the hold was created, so they only
+// move from held to sold.
+func (s *HoldService) ConfirmHold(organizerID int, id int) (*models.Purchase, error) {
+	tx, err := s.DB.BeginTransaction()
+	if err != nil {
+		log.Printf("Failed to start transaction: %v", err)
+		return nil, fmt.Errorf("failed to start transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	hold, err := lockHold(tx, organizerID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if hold.Status != models.HoldStatusActive {
+		return nil, errors.NewRestError(fmt.Sprintf("Hold is already %s", hold.Status), 400).WithCode(errors.CodeHoldClosed)
+	}
+
+	if !hold.ExpiresAt.After(time.Now()) {
+		return nil, errors.NewRestError("Hold has expired", 400).WithCode(errors.CodeHoldExpired)
+	}
+
+	ticket, err := lockOrganizerTicket(tx, organizerID, hold.TicketID)
+	if err != nil {
+		return nil, err
+	}
+
+	ticket.Held -= hold.Quantity
+	ticket.Sold += hold.Quantity
+
+	err = updateTicketInventory(tx, ticket)
+	if err != nil {
+		return nil, err
+	}
+
+	purchase := &models.Purchase{
+		TicketID: hold.TicketID, BuyerID: hold.BuyerID, Quantity: hold.Quantity, UnitPrice: hold.UnitPrice, SeatIDs: hold.SeatIDs,
+	}
+	err = insertPurchase(tx, purchase)
+	if err != nil {
+		return nil, err
+	}
+
+	if ticket.Seated {
+		err = assignSeats(tx, hold.SeatIDs, models.SeatStatusSold, nil, &purchase.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	_, err = tx.Exec(
+		"UPDATE ticket_hold SET status = $1, purchase_id = $2, updated_at = CURRENT_TIMESTAMP WHERE id = $3",
+		models.HoldStatusConfirmed, purchase.ID, hold.ID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update hold: %v", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	err = invalidateTicketCache(s.Cache, ticket.OrganizerID, hold.TicketID)
+	if err != nil {
+		log.Printf("Failed to invalidate cache: %v for ticket: %d", err, hold.TicketID)
+	}
+
+	return purchase, nil
+}
+
+func (s *HoldService) ReleaseHold(organizerID int, id int) error {
+	return s.endHold(organizerID, id, models.HoldStatusReleased)
+}
+
+// ReleaseExpiredHolds returns the tickets of every expired hold to their allocation.
+// Each hold is released in its own transaction, so one failure doesn't block the rest.
+func (s *HoldService) ReleaseExpiredHolds() (int, error) {
+	rows, err := s.DB.Query(
+		"SELECT ticket_hold.id, ticket.organizer_id FROM ticket_hold JOIN ticket ON ticket.id = ticket_hold.ticket_id "+
+			"WHERE ticket_hold.status = $1 AND ticket_hold.expires_at <= $2 ORDER BY ticket_hold.id",
+		models.HoldStatusActive, time.Now().UTC(),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list expired holds: %v", err)
+	}
+
+	// Holds are released as the organizer of their ticket.
+	type expiredHold struct{ id, organizerID int }
+	var expired []expiredHold
+	for rows.Next() {
+		var hold expiredHold
+		if err := rows.Scan(&hold.id, &hold.organizerID); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan hold: %v", err)
+		}
+		expired = append(expired, hold)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to list expired holds: %v", err)
+	}
+
+	released := 0
+	for _, hold := range expired {
+		err := s.endHold(hold.organizerID, hold.id, models.HoldStatusExpired)
+		if err != nil {
+			if _, ok := err.(errors.RestError); ok {
+				// Confirmed or released since it was listed.
+				continue
+			}
+			log.Printf("Failed to release expired hold %d: %v", hold.id, err)
+			continue
+		}
+		released++
+	}
+
+	return released, nil
+}
+
+func (s *HoldService) StartHoldReaper(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		released, err := s.ReleaseExpiredHolds()
+		if err != nil {
+			log.Printf("Failed to release expired holds: %v", err)
+			continue
+		}
+		if released > 0 {
+			log.Printf("Released %d expired holds", released)
+		}
+	}
+}
+
+// endHold returns the held tickets and seats to the allocation, where they are
+// offered to the waitlist first, and closes the hold of the organizer with status.
+func (s *HoldService) endHold(organizerID int, id int, status string) error {
+	tx, err := s.DB.BeginTransaction()
+	if err != nil {
+		log.Printf("Failed to start transaction: %v", err)
+		return fmt.Errorf("failed to start transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	hold, err := lockHold(tx, organizerID, id)
+	if err != nil {
+		return err
+	}
+
+	if hold.Status != models.HoldStatusActive {
+		return errors.NewRestError(fmt.Sprintf("Hold is already %s", hold.Status), 400).WithCode(errors.CodeHoldClosed)
+	}
+
+	ticket, err := lockOrganizerTicket(tx, organizerID, hold.TicketID)
+	if err != nil {
+		return err
+	}
+
+	if ticket.Seated {
+		err = assignSeats(tx, hold.SeatIDs, models.SeatStatusAvailable, nil, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	ticket.Held -= hold.Quantity
+	ticket.Allocation += hold.Quantity
+
+	err = updateTicketInventory(tx, ticket)
+	if err != nil {
+		return err
+	}
+
+	err = returnPoolCapacity(tx, ticket.CapacityPoolID, hold.Quantity)
+	if err != nil {
+		return err
+	}
+
+	err = offerToWaitlist(tx, ticket)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(
+		"UPDATE ticket_hold SET status = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2",
+		status, hold.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update hold: %v", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	err = invalidateTicketCache(s.Cache, ticket.OrganizerID, hold.TicketID)
+	if err != nil {
+		log.Printf("Failed to invalidate cache: %v for ticket: %d", err, hold.TicketID)
+	}
+
+	return nil
+}
+
+// insertHold stores an active hold on tickets that the caller already moved from
+// the ticket's allocation to held. seatIDs are the held seats of a seated ticket.
+// The hold keeps the price of the locked ticket, whatever sells before it's confirmed.
+func insertHold(tx *sql.Tx, ticket *models.Ticket, buyerID string, quantity int, seatIDs []int, duration time.Duration) (*models.Hold, error) {
+	unitPrice, _, err := currentPrice(tx, ticket)
+	if err != nil {
+		return nil, err
+	}
+
+	hold := &models.Hold{
+		TicketID:  ticket.ID,
+		BuyerID:   buyerID,
+		Quantity:  quantity,
+		UnitPrice: unitPrice,
+		Status:    models.HoldStatusActive,
+		ExpiresAt: time.Now().UTC().Add(duration),
+		SeatIDs:   seatIDs,
+	}
+	err = tx.QueryRow(
+		"INSERT INTO ticket_hold (ticket_id, buyer_id, quantity, unit_price, currency, status, expires_at, seat_ids) "+
+			"VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id, created_at",
+		hold.TicketID, hold.BuyerID, hold.Quantity, hold.UnitPrice.Amount, hold.UnitPrice.Currency, hold.Status, hold.ExpiresAt,
+		pq.Array(hold.SeatIDs),
+	).Scan(&hold.ID, &hold.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create hold: %v", err)
+	}
+	return hold, nil
+}
+
+// lockHold selects the hold on a ticket of the organizer FOR UPDATE. Holds are
+// always locked before their ticket.
+func lockHold(tx *sql.Tx, organizerID int, id int) (*models.Hold, error) {
+	hold := &models.Hold{}
+
+	err := scanHold(tx.QueryRow(
+		"SELECT "+holdColumns+" FROM ticket_hold WHERE id = $1 AND ticket_id IN (SELECT id FROM ticket WHERE organizer_id = $2) FOR UPDATE",
+		id, organizerID,
+	), hold)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NewRestError(fmt.Sprintf("Hold %d not found", id), 404)
+		}
+		return nil, fmt.Errorf("failed to get hold: %v", err)
+	}
+
+	return hold, nil
+}
+
+func scanHold(row rowScanner, hold *models.Hold) error {
+	var purchaseID sql.NullInt64
+
+	err := row.Scan(
+		&hold.ID, &hold.TicketID, &hold.BuyerID, &hold.Quantity, &hold.UnitPrice.Amount, &hold.UnitPrice.Currency, &hold.Status,
+		&hold.ExpiresAt, &purchaseID, scanSeatIDs(&hold.SeatIDs), &hold.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	if purchaseID.Valid {
+		id := int(purchaseID.Int64)
+		hold.PurchaseID = &id
+	}
+
+	return nil
+}
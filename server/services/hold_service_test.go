@@ -0,0 +1,204 @@
+package services_test
+
+import (
+	"gowitcase/mocks"
+	"gowitcase/models"
+	"gowitcase/services"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupHoldTest(t *testing.T) (*services.HoldService, sqlmock.Sqlmock) {
+	mockDB, mock, err := mocks.NewMockDatabase()
+	assert.NoError(t, err)
+
+	holdService := services.NewHoldService(mockDB, mocks.NewMockRedis())
+
+	return holdService, mock
+}
+
+func newHoldRows(holds ...*models.Hold) *sqlmock.Rows {
+	rows := sqlmock.NewRows([]string{
+		"id", "ticket_id", "buyer_id", "quantity", "unit_price", "currency", "status", "expires_at", "purchase_id", "seat_ids", "created_at",
+	})
+	for _, hold := range holds {
+		rows.AddRow(
+			hold.ID, hold.TicketID, hold.BuyerID, hold.Quantity, hold.UnitPrice.Amount, hold.UnitPrice.Currency, hold.Status,
+			hold.ExpiresAt, hold.PurchaseID, intArrayValue(hold.SeatIDs), hold.CreatedAt,
+		)
+	}
+	return rows
+}
+
+func TestCreateHold_Success(t *testing.T) {
+	holdService, mock := setupHoldTest(t)
+
+	mock.ExpectBegin()
+
+	mock.ExpectQuery("SELECT (.+) FROM ticket WHERE id = (.+) FOR UPDATE").
+		WithArgs(1, testOrganizerID).
+		WillReturnRows(newTicketRows(&models.Ticket{ID: 1, Name: "test", Allocation: 10}))
+
+	expectWaitlistDemand(mock, 1, 0)
+
+	mock.ExpectExec("UPDATE ticket SET allocation").
+		WithArgs(7, 3, 0, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	mock.ExpectQuery("INSERT INTO ticket_hold").
+		WithArgs(1, "buyer", 3, int64(0), "", models.HoldStatusActive, sqlmock.AnyArg(), nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(5, time.Now()))
+
+	mock.ExpectCommit()
+
+	hold, err := holdService.CreateHold(testOrganizerID, 1, models.HoldRequest{BuyerID: "buyer", Quantity: 3})
+	assert.NoError(t, err, "failed to create hold")
+
+	assert.Equal(t, 5, hold.ID, "expected hold ID 5")
+	assert.Equal(t, models.HoldStatusActive, hold.Status, "expected hold to be active")
+	assert.True(t, hold.ExpiresAt.After(time.Now()), "expected hold to expire in the future")
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
+
+func TestCreateHold_NotEnoughAvailable(t *testing.T) {
+	holdService, mock := setupHoldTest(t)
+
+	mock.ExpectBegin()
+
+	mock.ExpectQuery("SELECT (.+) FROM ticket WHERE id = (.+) FOR UPDATE").
+		WithArgs(1, testOrganizerID).
+		WillReturnRows(newTicketRows(&models.Ticket{ID: 1, Name: "test", Allocation: 2, Held: 8}))
+
+	mock.ExpectRollback()
+
+	_, err := holdService.CreateHold(testOrganizerID, 1, models.HoldRequest{BuyerID: "buyer", Quantity: 3})
+	assert.Error(t, err, "expected error when held tickets leave too few available")
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
+
+func TestConfirmHold_Success(t *testing.T) {
+	holdService, mock := setupHoldTest(t)
+
+	hold := &models.Hold{ID: 5, TicketID: 1, BuyerID: "buyer", Quantity: 3, Status: models.HoldStatusActive, ExpiresAt: time.Now().Add(time.Minute)}
+
+	mock.ExpectBegin()
+
+	mock.ExpectQuery("SELECT (.+) FROM ticket_hold WHERE id = (.+) FOR UPDATE").
+		WithArgs(hold.ID, testOrganizerID).
+		WillReturnRows(newHoldRows(hold))
+
+	mock.ExpectQuery("SELECT (.+) FROM ticket WHERE id = (.+) FOR UPDATE").
+		WithArgs(1, testOrganizerID).
+		WillReturnRows(newTicketRows(&models.Ticket{ID: 1, Name: "test", Allocation: 7, Held: 3}))
+
+	mock.ExpectExec("UPDATE ticket SET allocation").
+		WithArgs(7, 0, 3, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	mock.ExpectQuery("INSERT INTO purchase").
+		WithArgs(1, "buyer", 3, int64(0), int64(0), int64(0), "", nil, nil, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(9, time.Now()))
+
+	expectTicketInstances(mock, 9)
+
+	mock.ExpectExec("UPDATE ticket_hold SET status").
+		WithArgs(models.HoldStatusConfirmed, 9, hold.ID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	mock.ExpectCommit()
+
+	purchase, err := holdService.ConfirmHold(testOrganizerID, hold.ID)
+	assert.NoError(t, err, "failed to confirm hold")
+	assert.Equal(t, 9, purchase.ID, "expected purchase ID 9")
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
+
+func TestConfirmHold_Expired(t *testing.T) {
+	holdService, mock := setupHoldTest(t)
+
+	hold := &models.Hold{ID: 5, TicketID: 1, BuyerID: "buyer", Quantity: 3, Status: models.HoldStatusActive, ExpiresAt: time.Now().Add(-time.Minute)}
+
+	mock.ExpectBegin()
+
+	mock.ExpectQuery("SELECT (.+) FROM ticket_hold").
+		WithArgs(hold.ID, testOrganizerID).
+		WillReturnRows(newHoldRows(hold))
+
+	mock.ExpectRollback()
+
+	_, err := holdService.ConfirmHold(testOrganizerID, hold.ID)
+	assert.Error(t, err, "expected error when hold has expired")
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
+
+func TestReleaseHold_AlreadyConfirmed(t *testing.T) {
+	holdService, mock := setupHoldTest(t)
+
+	purchaseID := 9
+	hold := &models.Hold{ID: 5, TicketID: 1, BuyerID: "buyer", Quantity: 3, Status: models.HoldStatusConfirmed, PurchaseID: &purchaseID}
+
+	mock.ExpectBegin()
+
+	mock.ExpectQuery("SELECT (.+) FROM ticket_hold").
+		WithArgs(hold.ID, testOrganizerID).
+		WillReturnRows(newHoldRows(hold))
+
+	mock.ExpectRollback()
+
+	err := holdService.ReleaseHold(testOrganizerID, hold.ID)
+	assert.Error(t, err, "expected error when hold is already confirmed")
+	assert.Equal(t, "Hold is already confirmed", err.Error(), "expected error message to match")
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
+
+func TestReleaseExpiredHolds(t *testing.T) {
+	holdService, mock := setupHoldTest(t)
+
+	hold := &models.Hold{ID: 5, TicketID: 1, BuyerID: "buyer", Quantity: 3, Status: models.HoldStatusActive, ExpiresAt: time.Now().Add(-time.Minute)}
+
+	mock.ExpectQuery("SELECT ticket_hold.id, ticket.organizer_id FROM ticket_hold").
+		WithArgs(models.HoldStatusActive, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "organizer_id"}).AddRow(hold.ID, testOrganizerID))
+
+	mock.ExpectBegin()
+
+	mock.ExpectQuery("SELECT (.+) FROM ticket_hold WHERE id = (.+) FOR UPDATE").
+		WithArgs(hold.ID, testOrganizerID).
+		WillReturnRows(newHoldRows(hold))
+
+	mock.ExpectQuery("SELECT (.+) FROM ticket WHERE id = (.+) FOR UPDATE").
+		WithArgs(1, testOrganizerID).
+		WillReturnRows(newTicketRows(&models.Ticket{ID: 1, Name: "test", Allocation: 7, Held: 3}))
+
+	mock.ExpectExec("UPDATE ticket SET allocation").
+		WithArgs(10, 0, 0, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	expectEmptyWaitlist(mock, 1)
+
+	mock.ExpectExec("UPDATE ticket_hold SET status").
+		WithArgs(models.HoldStatusExpired, hold.ID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	mock.ExpectCommit()
+
+	released, err := holdService.ReleaseExpiredHolds()
+	assert.NoError(t, err, "failed to release expired holds")
+	assert.Equal(t, 1, released, "expected one released hold")
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
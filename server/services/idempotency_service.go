@@ -0,0 +1,181 @@
+package services
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"gowitcase/db"
+	"gowitcase/models"
+	"log"
+	"time"
+)
+
+type IdempotencyService struct {
+	DB    db.DatabaseInterface
+	Cache db.RedisInterface
+}
+
+func NewIdempotencyService(db db.DatabaseInterface, cache db.RedisInterface) *IdempotencyService {
+	return &IdempotencyService{DB: db, Cache: cache}
+}
+
+// Begin reserves the key for a new request and returns the token of the
+// reservation, which Complete and Release need. If the key is taken, it returns the
+// record stored for it instead, which may still be in progress. A reservation
+// expires after IdempotencyReservationTTL and a response after
+// IdempotencyResponseTTL, the key can be reserved again after that.
+func (s *IdempotencyService) Begin(key string, fingerprint string) (*models.IdempotencyRecord, string, error) {
+	record, err := s.getCacheRecord(key)
+	if err == nil && record != nil {
+		return record, "", nil
+	}
+
+	token, err := newReservationToken()
+	if err != nil {
+		return nil, "", err
+	}
+
+	now := time.Now()
+	var reservedKey string
+	err = s.DB.QueryRow(
+		"INSERT INTO idempotency_key (key, fingerprint, token, expires_at) VALUES ($1, $2, $3, $4) "+
+			"ON CONFLICT (key) DO UPDATE SET fingerprint = EXCLUDED.fingerprint, token = EXCLUDED.token, status_code = NULL, "+
+			"response_body = NULL, created_at = CURRENT_TIMESTAMP, expires_at = EXCLUDED.expires_at "+
+			"WHERE idempotency_key.expires_at < $5 RETURNING key",
+		key, fingerprint, token, now.Add(models.IdempotencyReservationTTL), now,
+	).Scan(&reservedKey)
+	if err == nil {
+		return nil, token, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, "", fmt.Errorf("failed to reserve idempotency key: %v", err)
+	}
+
+	record = &models.IdempotencyRecord{Key: key}
+	var statusCode sql.NullInt64
+	var responseBody sql.NullString
+	err = s.DB.QueryRow(
+		"SELECT fingerprint, status_code, response_body, expires_at FROM idempotency_key WHERE key = $1",
+		key,
+	).Scan(&record.Fingerprint, &statusCode, &responseBody, &record.ExpiresAt)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get idempotency key: %v", err)
+	}
+
+	record.StatusCode = int(statusCode.Int64)
+	record.ResponseBody = responseBody.String
+
+	if record.IsCompleted() {
+		err = s.cacheRecord(record)
+		if err != nil {
+			log.Printf("Failed to cache idempotency key: %v", err)
+		}
+	}
+
+	return record, "", nil
+}
+
+// Complete stores the response of the request holding the reservation token. A
+// request whose reservation expired and was taken over by a retry can't overwrite
+// the retry's response.
+func (s *IdempotencyService) Complete(key string, token string, statusCode int, responseBody string) error {
+	record := &models.IdempotencyRecord{Key: key, StatusCode: statusCode, ResponseBody: responseBody}
+
+	err := s.DB.QueryRow(
+		"UPDATE idempotency_key SET status_code = $1, response_body = $2, expires_at = $3 "+
+			"WHERE key = $4 AND token = $5 AND status_code IS NULL RETURNING fingerprint, expires_at",
+		statusCode, responseBody, time.Now().Add(models.IdempotencyResponseTTL), key, token,
+	).Scan(&record.Fingerprint, &record.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to complete idempotency key: %v", err)
+	}
+
+	err = s.cacheRecord(record)
+	if err != nil {
+		log.Printf("Failed to cache idempotency key: %v", err)
+	}
+
+	return nil
+}
+
+// Release frees the key reserved with token so the request can be retried, used
+// when it failed without a result worth replaying.
+func (s *IdempotencyService) Release(key string, token string) error {
+	_, err := s.DB.Exec("DELETE FROM idempotency_key WHERE key = $1 AND token = $2 AND status_code IS NULL", key, token)
+	if err != nil {
+		return fmt.Errorf("failed to release idempotency key: %v", err)
+	}
+	return nil
+}
+
+// DeleteExpired deletes the keys Begin would no longer hold, so the table only
+// keeps the keys that can still be replayed.
+func (s *IdempotencyService) DeleteExpired() (int, error) {
+	result, err := s.DB.Exec("DELETE FROM idempotency_key WHERE expires_at < $1", time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired idempotency keys: %v", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to count expired idempotency keys: %v", err)
+	}
+	return int(deleted), nil
+}
+
+func (s *IdempotencyService) StartKeyReaper(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		deleted, err := s.DeleteExpired()
+		if err != nil {
+			log.Printf("Failed to delete expired idempotency keys: %v", err)
+			continue
+		}
+		if deleted > 0 {
+			log.Printf("Deleted %d expired idempotency keys", deleted)
+		}
+	}
+}
+
+func (s *IdempotencyService) cacheRecord(record *models.IdempotencyRecord) error {
+	recordBytes, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal idempotency record: %v", err)
+	}
+
+	// The cached record expires with the row, so both replay the response equally long.
+	ttl := time.Until(record.ExpiresAt)
+	if ttl <= 0 {
+		return nil
+	}
+	return s.Cache.Set(models.IdempotencyCachePrefix+record.Key, string(recordBytes), ttl)
+}
+
+func (s *IdempotencyService) getCacheRecord(key string) (*models.IdempotencyRecord, error) {
+	record := &models.IdempotencyRecord{}
+	recordJSON, err := s.Cache.Get(models.IdempotencyCachePrefix + key)
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal([]byte(recordJSON), record)
+	if err != nil {
+		return nil, err
+	}
+
+	return record, nil
+}
+
+// newReservationToken returns a random token telling the reservations of a key apart.
+func newReservationToken() (string, error) {
+	token := make([]byte, 16)
+	_, err := rand.Read(token)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate idempotency token: %v", err)
+	}
+	return hex.EncodeToString(token), nil
+}
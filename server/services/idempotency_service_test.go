@@ -0,0 +1,200 @@
+package services_test
+
+import (
+	"gowitcase/mocks"
+	"gowitcase/services"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupIdempotencyTest(t *testing.T) (*services.IdempotencyService, sqlmock.Sqlmock) {
+	mockDB, mock, err := mocks.NewMockDatabase()
+	assert.NoError(t, err)
+
+	idempotencyService := services.NewIdempotencyService(mockDB, mocks.NewMockRedis())
+
+	return idempotencyService, mock
+}
+
+func TestIdempotencyBegin_NewKey(t *testing.T) {
+	idempotencyService, mock := setupIdempotencyTest(t)
+
+	mock.ExpectQuery("INSERT INTO idempotency_key").
+		WithArgs("key", "fingerprint", sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"key"}).AddRow("key"))
+
+	record, token, err := idempotencyService.Begin("key", "fingerprint")
+	assert.NoError(t, err, "failed to begin idempotent request")
+	assert.Nil(t, record, "expected no record for a new key")
+	assert.Len(t, token, 32, "expected the token of the reservation")
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
+
+func TestIdempotencyBegin_InProgress(t *testing.T) {
+	idempotencyService, mock := setupIdempotencyTest(t)
+
+	mock.ExpectQuery("INSERT INTO idempotency_key").
+		WithArgs("key", "fingerprint", sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"key"}))
+
+	mock.ExpectQuery("SELECT fingerprint, status_code, response_body, expires_at FROM idempotency_key").
+		WithArgs("key").
+		WillReturnRows(sqlmock.NewRows([]string{"fingerprint", "status_code", "response_body", "expires_at"}).
+			AddRow("fingerprint", nil, nil, time.Now().Add(time.Minute)))
+
+	record, token, err := idempotencyService.Begin("key", "fingerprint")
+	assert.NoError(t, err, "failed to begin idempotent request")
+	assert.NotNil(t, record, "expected record for a used key")
+	assert.False(t, record.IsCompleted(), "expected record to be in progress")
+	assert.Empty(t, token, "expected no reservation")
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
+
+func TestIdempotencyBegin_Completed(t *testing.T) {
+	idempotencyService, mock := setupIdempotencyTest(t)
+
+	mock.ExpectQuery("INSERT INTO idempotency_key").
+		WithArgs("key", "other", sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"key"}))
+
+	mock.ExpectQuery("SELECT fingerprint, status_code, response_body, expires_at FROM idempotency_key").
+		WithArgs("key").
+		WillReturnRows(sqlmock.NewRows([]string{"fingerprint", "status_code", "response_body", "expires_at"}).
+			AddRow("fingerprint", 201, `{"id":1}`, time.Now().Add(time.Hour)))
+
+	record, _, err := idempotencyService.Begin("key", "other")
+	assert.NoError(t, err, "failed to begin idempotent request")
+	assert.Equal(t, "fingerprint", record.Fingerprint, "expected stored fingerprint")
+	assert.Equal(t, 201, record.StatusCode, "expected stored status code")
+	assert.Equal(t, `{"id":1}`, record.ResponseBody, "expected stored response body")
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
+
+func TestIdempotencyComplete_ServedFromCache(t *testing.T) {
+	idempotencyService, mock := setupIdempotencyTest(t)
+
+	mock.ExpectQuery("UPDATE idempotency_key SET status_code").
+		WithArgs(201, `{"id":1}`, sqlmock.AnyArg(), "key", "token").
+		WillReturnRows(sqlmock.NewRows([]string{"fingerprint", "expires_at"}).AddRow("fingerprint", time.Now().Add(time.Hour)))
+
+	err := idempotencyService.Complete("key", "token", 201, `{"id":1}`)
+	assert.NoError(t, err, "failed to complete idempotent request")
+
+	record, _, err := idempotencyService.Begin("key", "fingerprint")
+	assert.NoError(t, err, "failed to begin idempotent request")
+	assert.Equal(t, 201, record.StatusCode, "expected cached status code")
+	assert.Equal(t, `{"id":1}`, record.ResponseBody, "expected cached response body")
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
+
+func TestIdempotencyRelease(t *testing.T) {
+	idempotencyService, mock := setupIdempotencyTest(t)
+
+	mock.ExpectExec("DELETE FROM idempotency_key WHERE key = (.+) AND token = ").
+		WithArgs("key", "token").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := idempotencyService.Release("key", "token")
+	assert.NoError(t, err, "failed to release idempotency key")
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
+
+func TestIdempotencyBegin_TakesOverStaleReservation(t *testing.T) {
+	idempotencyService, mock := setupIdempotencyTest(t)
+
+	// A reservation left behind by a crashed request, or an expired response, is
+	// replaced by the new request instead of blocking it.
+	mock.ExpectQuery("INSERT INTO idempotency_key (.+) ON CONFLICT \\(key\\) DO UPDATE (.+) WHERE idempotency_key.expires_at < ").
+		WithArgs("key", "fingerprint", sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"key"}).AddRow("key"))
+
+	record, token, err := idempotencyService.Begin("key", "fingerprint")
+	assert.NoError(t, err, "failed to begin idempotent request")
+	assert.Nil(t, record, "expected the stale key to be reserved again")
+	assert.NotEmpty(t, token, "expected a new token for the new reservation")
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
+
+func TestIdempotencyComplete_OnlyReservation(t *testing.T) {
+	idempotencyService, mock := setupIdempotencyTest(t)
+
+	// A request whose reservation was taken over by a retry holds a stale token, and
+	// can't overwrite the retry's response.
+	mock.ExpectQuery("UPDATE idempotency_key SET status_code (.+) WHERE key = (.+) AND token = (.+) AND status_code IS NULL").
+		WithArgs(201, `{"id":1}`, sqlmock.AnyArg(), "key", "stale-token").
+		WillReturnRows(sqlmock.NewRows([]string{"fingerprint", "expires_at"}))
+
+	err := idempotencyService.Complete("key", "stale-token", 201, `{"id":1}`)
+	assert.Error(t, err, "expected error when the key isn't reserved with the token")
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
+
+func TestIdempotencyDeleteExpired(t *testing.T) {
+	idempotencyService, mock := setupIdempotencyTest(t)
+
+	mock.ExpectExec("DELETE FROM idempotency_key WHERE expires_at < ").
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	deleted, err := idempotencyService.DeleteExpired()
+	assert.NoError(t, err, "failed to delete expired idempotency keys")
+	assert.Equal(t, 3, deleted, "expected every expired key to be deleted")
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
+
+// ttlRedis records the TTL of every key set in the cache.
+type ttlRedis struct {
+	*mocks.MockRedis
+	ttls map[string]time.Duration
+}
+
+func (r *ttlRedis) Set(key string, value interface{}, ttl time.Duration) error {
+	r.ttls[key] = ttl
+	return r.MockRedis.Set(key, value, ttl)
+}
+
+func TestIdempotencyBegin_CachesForRemainingLifetime(t *testing.T) {
+	mockDB, mock, err := mocks.NewMockDatabase()
+	assert.NoError(t, err)
+
+	cache := &ttlRedis{MockRedis: mocks.NewMockRedis(), ttls: map[string]time.Duration{}}
+	idempotencyService := services.NewIdempotencyService(mockDB, cache)
+
+	mock.ExpectQuery("INSERT INTO idempotency_key").
+		WithArgs("key", "fingerprint", sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"key"}))
+
+	// The response was stored 23 hours ago, so an hour of its lifetime is left.
+	mock.ExpectQuery("SELECT fingerprint, status_code, response_body, expires_at FROM idempotency_key").
+		WithArgs("key").
+		WillReturnRows(sqlmock.NewRows([]string{"fingerprint", "status_code", "response_body", "expires_at"}).
+			AddRow("fingerprint", 201, `{"id":1}`, time.Now().Add(time.Hour)))
+
+	_, _, err = idempotencyService.Begin("key", "fingerprint")
+	assert.NoError(t, err, "failed to begin idempotent request")
+
+	ttl := cache.ttls["idempotency:key"]
+	assert.True(t, ttl > 59*time.Minute && ttl <= time.Hour, "expected the cache to expire with the row, got %s", ttl)
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
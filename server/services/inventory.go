@@ -0,0 +1,356 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"gowitcase/db"
+	"gowitcase/errors"
+	"gowitcase/models"
+	"log"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Inventory helpers shared by the services that move tickets between the
+// allocation, held and sold counters. They run inside the caller's transaction.
+
+// validatePurchaseRequest checks the buyer, quantity and seats of a purchase
+// payload together and returns the number of tickets it stands for.
+func validatePurchaseRequest(buyerID string, quantity int, seatIDs []int) (int, error) {
+	var fields errors.FieldErrors
+	quantity = seatQuantity(quantity, seatIDs, &fields)
+	if !fields.Has("quantity") {
+		checkPurchaseQuantity(quantity, &fields)
+	}
+	checkBuyerID(buyerID, &fields)
+	return quantity, fields.Err()
+}
+
+func validateBuyerID(buyerID string) error {
+	var fields errors.FieldErrors
+	checkBuyerID(buyerID, &fields)
+	return fields.Err()
+}
+
+func checkPurchaseQuantity(quantity int, fields *errors.FieldErrors) {
+	if quantity <= 0 || quantity > math.MaxInt32 {
+		fields.Add("quantity", "Field 'quantity' must be a positive number within the valid range")
+	}
+}
+
+func checkBuyerID(buyerID string, fields *errors.FieldErrors) {
+	if buyerID == "" {
+		fields.Add("buyer_id", "Field 'buyer_id' is required")
+	} else if len(buyerID) > 255 {
+		fields.Add("buyer_id", "Field 'buyer_id' must be less than 255 characters")
+	}
+}
+
+// lockOrganizerTicket selects the organizer's ticket FOR UPDATE, so concurrent
+// inventory changes to it are serialized until the transaction ends. Tickets of
+// other organizers are reported as not found, so their IDs don't leak.
+func lockOrganizerTicket(tx *sql.Tx, organizerID int, ticketID int) (*models.Ticket, error) {
+	ticket := &models.Ticket{}
+	err := scanTicket(tx.QueryRow(
+		"SELECT "+ticketColumns+" FROM ticket WHERE id = $1 AND organizer_id = $2 FOR UPDATE",
+		ticketID, organizerID,
+	), ticket)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NewRestError(fmt.Sprintf("Ticket %d not found", ticketID), 404)
+		}
+		return nil, fmt.Errorf("failed to get ticket: %v", err)
+	}
+
+	return ticket, nil
+}
+
+// checkAvailability tells whether the buyer can take quantity tickets out of the
+// locked ticket's allocation. Tickets the waitlist is waiting for aren't available,
+// so a buyer can't jump the queue.
+func checkAvailability(tx *sql.Tx, ticket *models.Ticket, buyerID string, quantity int) error {
+	err := checkSaleOpen(ticket)
+	if err != nil {
+		return err
+	}
+
+	err = checkOrderQuantity(ticket, quantity)
+	if err != nil {
+		return err
+	}
+
+	available, err := availableAllocation(tx, ticket)
+	if err != nil {
+		return err
+	}
+
+	if available == 0 {
+		return errors.NewRestError("Ticket is sold out", 400).WithCode(errors.CodeSoldOut)
+	}
+
+	if available < quantity {
+		return errors.NewRestError("Not enough tickets available", 400).
+			WithCode(errors.CodeNotEnoughTickets).
+			WithDetail("available", available)
+	}
+
+	return checkBuyerLimit(tx, ticket, buyerID, quantity)
+}
+
+// availableAllocation is the part of the locked ticket's allocation a new buyer can
+// take. The tickets waiting buyers asked for are kept for the waitlist, which gets
+// them as offers once enough are free for the first buyer in the queue. Entries
+// that can never be filled keep nothing.
+func availableAllocation(tx *sql.Tx, ticket *models.Ticket) (int, error) {
+	if ticket.Allocation == 0 || ticket.Seated {
+		return ticket.Allocation, nil
+	}
+
+	var waiting int
+	err := tx.QueryRow(
+		"SELECT COALESCE(SUM(quantity), 0) FROM waitlist_entry WHERE ticket_id = $1 AND status = $2 AND quantity <= $3",
+		ticket.ID, models.WaitlistStatusWaiting, fillableQuantity(ticket),
+	).Scan(&waiting)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count waitlisted tickets: %v", err)
+	}
+
+	if waiting >= ticket.Allocation {
+		return 0, nil
+	}
+	return ticket.Allocation - waiting, nil
+}
+
+// checkSaleOpen tells whether the ticket is on sale right now, regardless of
+// how many tickets are left.
+func checkSaleOpen(ticket *models.Ticket) error {
+	if ticket.ArchivedAt != nil {
+		return errors.NewRestError(fmt.Sprintf("Ticket %d is archived", ticket.ID), 400).WithCode(errors.CodeTicketArchived)
+	}
+
+	now := time.Now()
+	if ticket.SaleStartsAt != nil && now.Before(*ticket.SaleStartsAt) {
+		return errors.NewRestError(
+			fmt.Sprintf("Ticket sale has not started yet, it starts at %s", ticket.SaleStartsAt.UTC().Format(time.RFC3339)), 400,
+		).WithCode(errors.CodeSaleNotStarted).WithDetail("sale_starts_at", ticket.SaleStartsAt.UTC())
+	}
+
+	if ticket.SaleEndsAt != nil && !now.Before(*ticket.SaleEndsAt) {
+		return errors.NewRestError(
+			fmt.Sprintf("Ticket sale has ended at %s", ticket.SaleEndsAt.UTC().Format(time.RFC3339)), 400,
+		).WithCode(errors.CodeSaleEnded).WithDetail("sale_ends_at", ticket.SaleEndsAt.UTC())
+	}
+
+	return nil
+}
+
+// checkOrderQuantity tells whether quantity tickets can be bought in a single order
+// of the ticket.
+func checkOrderQuantity(ticket *models.Ticket, quantity int) error {
+	if quantity < ticket.MinPerOrder {
+		return errors.NewRestError(
+			fmt.Sprintf("Ticket %d is sold in orders of at least %d tickets, got %d", ticket.ID, ticket.MinPerOrder, quantity), 400,
+		).WithCode(errors.CodeInvalidQuantity).WithDetail("min_per_order", ticket.MinPerOrder)
+	}
+
+	if ticket.MaxPerOrder > 0 && quantity > ticket.MaxPerOrder {
+		return errors.NewRestError(
+			fmt.Sprintf("Ticket %d is sold in orders of at most %d tickets, got %d", ticket.ID, ticket.MaxPerOrder, quantity), 400,
+		).WithCode(errors.CodeInvalidQuantity).WithDetail("max_per_order", ticket.MaxPerOrder)
+	}
+
+	if ticket.QuantityStep > 1 && quantity%ticket.QuantityStep != 0 {
+		return errors.NewRestError(
+			fmt.Sprintf("Ticket %d is sold in multiples of %d tickets, got %d", ticket.ID, ticket.QuantityStep, quantity), 400,
+		).WithCode(errors.CodeInvalidQuantity).WithDetail("quantity_step", ticket.QuantityStep)
+	}
+
+	return nil
+}
+
+// checkBuyerLimit tells whether the buyer can get quantity more tickets without going
+// over the per-buyer limit. Active holds count towards it, refunded tickets don't.
+func checkBuyerLimit(tx *sql.Tx, ticket *models.Ticket, buyerID string, quantity int) error {
+	if ticket.MaxPerBuyer > 0 {
+		var taken int
+		err := tx.QueryRow(
+			"SELECT (SELECT COALESCE(SUM(quantity - refunded_quantity), 0) FROM purchase WHERE ticket_id = $1 AND buyer_id = $2) + "+
+				"(SELECT COALESCE(SUM(quantity), 0) FROM ticket_hold WHERE ticket_id = $1 AND buyer_id = $2 AND status = $3)",
+			ticket.ID, buyerID, models.HoldStatusActive,
+		).Scan(&taken)
+		if err != nil {
+			return fmt.Errorf("failed to count buyer tickets: %v", err)
+		}
+
+		if taken+quantity > ticket.MaxPerBuyer {
+			return errors.NewRestError(
+				fmt.Sprintf("Purchase limit is %d tickets per buyer, %d already purchased or held", ticket.MaxPerBuyer, taken), 400,
+			).WithCode(errors.CodeBuyerLimitExceeded).WithDetail("max_per_buyer", ticket.MaxPerBuyer).WithDetail("taken", taken)
+		}
+	}
+
+	return nil
+}
+
+func updateTicketInventory(tx *sql.Tx, ticket *models.Ticket) error {
+	_, err := tx.Exec(
+		"UPDATE ticket SET allocation = $1, held = $2, sold = $3 WHERE id = $4",
+		ticket.Allocation, ticket.Held, ticket.Sold, ticket.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update ticket: %v", err)
+	}
+	return nil
+}
+
+// insertPurchase stores the purchase with its total computed from the unit price,
+// less the discount of its promo code, and issues its ticket instances.
+func insertPurchase(tx *sql.Tx, purchase *models.Purchase) error {
+	subtotal, err := purchase.UnitPrice.Mul(int64(purchase.Quantity))
+	if err != nil {
+		return errors.NewRestError("Purchase total is too large", 400)
+	}
+
+	purchase.Discount.Currency = purchase.UnitPrice.Currency
+	purchase.Total, err = subtotal.Sub(purchase.Discount)
+	if err != nil {
+		return fmt.Errorf("failed to apply discount: %v", err)
+	}
+
+	err = tx.QueryRow(
+		"INSERT INTO purchase (ticket_id, buyer_id, quantity, unit_price, discount, total, currency, promo_code_id, seat_ids, order_id) "+
+			"VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id, created_at",
+		purchase.TicketID, purchase.BuyerID, purchase.Quantity, purchase.UnitPrice.Amount, purchase.Discount.Amount,
+		purchase.Total.Amount, purchase.UnitPrice.Currency, purchase.PromoCodeID, pq.Array(purchase.SeatIDs), purchase.OrderID,
+	).Scan(&purchase.ID, &purchase.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create purchase: %v", err)
+	}
+
+	return issueTicketInstances(tx, purchase)
+}
+
+// ticketCacheKey namespaces the cached ticket by organizer, so a ticket cached for
+// one organizer is never served to another.
+func ticketCacheKey(organizerID int, ticketID int) string {
+	return models.TicketCachePrefix + strconv.Itoa(organizerID) + ":" + strconv.Itoa(ticketID)
+}
+
+func cacheTicket(cache db.RedisInterface, ticket *models.Ticket) error {
+	ticketBytes, err := json.Marshal(ticket)
+	if err != nil {
+		return fmt.Errorf("failed to marshal ticket: %v", err)
+	}
+	return cache.Set(ticketCacheKey(ticket.OrganizerID, ticket.ID), string(ticketBytes), 5*time.Minute)
+}
+
+func getCachedTicket(cache db.RedisInterface, organizerID int, ticketID int) (*models.Ticket, error) {
+	ticket := &models.Ticket{}
+	ticketJSON, err := cache.Get(ticketCacheKey(organizerID, ticketID))
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal([]byte(ticketJSON), ticket)
+	if err != nil {
+		return nil, err
+	}
+
+	return ticket, nil
+}
+
+// getTicketsByID returns the organizer's tickets among ids, keyed by ID. It reads
+// the ticket cache first and loads all misses with one query, caching what it
+// loads. IDs that aren't found are left out.
+func getTicketsByID(database db.DatabaseInterface, cache db.RedisInterface, organizerID int, ids []int) (map[int]*models.Ticket, error) {
+	tickets := make(map[int]*models.Ticket, len(ids))
+	seen := make(map[int]bool, len(ids))
+	var missing []int64
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		ticket, err := getCachedTicket(cache, organizerID, id)
+		if err != nil {
+			missing = append(missing, int64(id))
+			continue
+		}
+		tickets[id] = ticket
+	}
+
+	if len(missing) > 0 {
+		rows, err := database.Query(
+			"SELECT "+ticketColumns+" FROM ticket WHERE id = ANY($1) AND organizer_id = $2",
+			pq.Array(missing), organizerID,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get tickets: %v", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			ticket := &models.Ticket{}
+			if err := scanTicket(rows, ticket); err != nil {
+				return nil, fmt.Errorf("failed to scan ticket: %v", err)
+			}
+			tickets[ticket.ID] = ticket
+
+			err = cacheTicket(cache, ticket)
+			if err != nil {
+				log.Printf("Failed to cache ticket: %v", err)
+			}
+		}
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("failed to get tickets: %v", err)
+		}
+	}
+
+	now := time.Now()
+	for _, ticket := range tickets {
+		ticket.SaleStatus = ticket.CurrentSaleStatus(now)
+	}
+
+	return tickets, nil
+}
+
+// invalidateTicketCache drops the cached ticket and every cached ticket list page
+// of its organizer, then announces the change to the availability streams. Every
+// change to the inventory of a ticket ends here once committed.
+func invalidateTicketCache(cache db.RedisInterface, organizerID int, ticketID int) error {
+	err := cache.Del(ticketCacheKey(organizerID, ticketID))
+	if err != nil {
+		return err
+	}
+
+	err = invalidateTicketListCache(cache, organizerID)
+	if err != nil {
+		return err
+	}
+
+	return publishAvailabilityChange(cache, organizerID, ticketID)
+}
+
+func publishAvailabilityChange(cache db.RedisInterface, organizerID int, ticketID int) error {
+	message, err := json.Marshal(models.AvailabilityChange{OrganizerID: organizerID, TicketID: ticketID})
+	if err != nil {
+		return err
+	}
+	return cache.Publish(models.AvailabilityChannel, string(message))
+}
+
+// invalidateTicketListCache moves the organizer's list cache to a new version, so
+// pages cached before one of its tickets changed are never served again and simply
+// expire.
+func invalidateTicketListCache(cache db.RedisInterface, organizerID int) error {
+	return cache.Set(ticketListVersionKey(organizerID), strconv.FormatInt(time.Now().UnixNano(), 10), 0)
+}
+
+func ticketListVersionKey(organizerID int) string {
+	return models.TicketListVersionPrefix + strconv.Itoa(organizerID)
+}
@@ -0,0 +1,313 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"gowitcase/db"
+	"gowitcase/errors"
+	"gowitcase/models"
+	"log"
+	"sort"
+)
+
+type OrderService struct {
+	DB    db.DatabaseInterface
+	Cache db.RedisInterface
+}
+
+func NewOrderService(db db.DatabaseInterface, cache db.RedisInterface) *OrderService {
+	return &OrderService{DB: db, Cache: cache}
+}
+
+// orderLine is a line of an order request on its way to becoming a purchase.
+type orderLine struct {
+	index    int
+	request  models.OrderLineRequest
+	ticket   *models.Ticket
+	purchase *models.Purchase
+}
+
+// Checkout purchases every line of the order in one transaction, or none of them.
+// The tickets are locked in ID order, then the capacity pools in ID order and the
+// promo codes in code order, so two orders sharing any of them can't deadlock.
+// Every line is checked before giving up, and the failed ones are reported
+// together as LineErrors. Every ticket has to belong to the organizer.
+func (s *OrderService) Checkout(organizerID int, request models.OrderRequest) (*models.Order, error) {
+	err := validateBuyerID(request.BuyerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(request.Lines) == 0 {
+		return nil, errors.NewFieldError("lines", "Field 'lines' must not be empty")
+	}
+
+	if len(request.Lines) > models.OrderMaxLines {
+		return nil, errors.NewRestError(fmt.Sprintf("An order can have at most %d lines", models.OrderMaxLines), 400)
+	}
+
+	lines := make([]*orderLine, len(request.Lines))
+	var lineErrors []errors.LineError
+	fail := func(line *orderLine, err error) error {
+		restErr, ok := err.(errors.RestError)
+		if !ok {
+			return err
+		}
+		lineError := errors.LineError{
+			Line: line.index, TicketID: line.request.TicketID, Code: restErr.Code, Message: restErr.Message, Status: restErr.Status,
+		}
+		for _, field := range restErr.Fields {
+			lineError.Fields = append(lineError.Fields, errors.FieldError{
+				Field: fmt.Sprintf("lines[%d].%s", line.index, field.Field), Message: field.Message,
+			})
+		}
+		lineErrors = append(lineErrors, lineError)
+		return nil
+	}
+
+	seen := make(map[int]bool, len(request.Lines))
+	for i, lineRequest := range request.Lines {
+		line := &orderLine{index: i, request: lineRequest}
+		lines[i] = line
+
+		if seen[lineRequest.TicketID] {
+			fail(line, errors.NewRestError(fmt.Sprintf("Ticket %d is listed more than once", lineRequest.TicketID), 400))
+			continue
+		}
+		seen[lineRequest.TicketID] = true
+
+		line.request.Quantity, err = validatePurchaseRequest(request.BuyerID, lineRequest.Quantity, lineRequest.SeatIDs)
+		if err != nil {
+			fail(line, err)
+		}
+	}
+
+	if len(lineErrors) > 0 {
+		return nil, errors.NewLineErrors("Order has invalid lines", lineErrors)
+	}
+
+	byTicket := make([]*orderLine, len(lines))
+	copy(byTicket, lines)
+	sort.Slice(byTicket, func(i, j int) bool {
+		return byTicket[i].request.TicketID < byTicket[j].request.TicketID
+	})
+
+	tx, err := s.DB.BeginTransaction()
+	if err != nil {
+		log.Printf("Failed to start transaction: %v", err)
+		return nil, fmt.Errorf("failed to start transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	for _, line := range byTicket {
+		err := s.lockLine(tx, organizerID, request.BuyerID, line)
+		if err != nil {
+			if err := fail(line, err); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if len(lineErrors) == 0 {
+		err = s.takePoolCapacities(tx, byTicket, fail)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(lineErrors) == 0 {
+		err = s.redeemPromoCodes(tx, lines, fail)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(lineErrors) > 0 {
+		sort.Slice(lineErrors, func(i, j int) bool { return lineErrors[i].Line < lineErrors[j].Line })
+		return nil, errors.NewLineErrors("Order could not be placed", lineErrors)
+	}
+
+	order := &models.Order{OrganizerID: organizerID, BuyerID: request.BuyerID, Purchases: make([]models.Purchase, 0, len(lines))}
+	err = tx.QueryRow(
+		"INSERT INTO purchase_order (organizer_id, buyer_id) VALUES ($1, $2) RETURNING id, created_at",
+		order.OrganizerID, order.BuyerID,
+	).Scan(&order.ID, &order.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create order: %v", err)
+	}
+
+	// Every row is locked by now, so the lines are written in the order they were requested.
+	for _, line := range lines {
+		line.ticket.Allocation -= line.purchase.Quantity
+		line.ticket.Sold += line.purchase.Quantity
+
+		err = updateTicketInventory(tx, line.ticket)
+		if err != nil {
+			return nil, err
+		}
+
+		line.purchase.OrderID = &order.ID
+		err = insertPurchase(tx, line.purchase)
+		if err != nil {
+			return nil, err
+		}
+
+		if line.ticket.Seated {
+			err = assignSeats(tx, line.purchase.SeatIDs, models.SeatStatusSold, nil, &line.purchase.ID)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		order.Purchases = append(order.Purchases, *line.purchase)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	for _, line := range lines {
+		err = invalidateTicketCache(s.Cache, line.ticket.OrganizerID, line.ticket.ID)
+		if err != nil {
+			log.Printf("Failed to invalidate cache: %v for ticket: %d", err, line.ticket.ID)
+		}
+	}
+
+	return order, nil
+}
+
+// lockLine locks the ticket and seats of a line and checks that they can be bought.
+func (s *OrderService) lockLine(tx *sql.Tx, organizerID int, buyerID string, line *orderLine) error {
+	ticket, err := lockOrganizerTicket(tx, organizerID, line.request.TicketID)
+	if err != nil {
+		return err
+	}
+
+	err = checkAvailability(tx, ticket, buyerID, line.request.Quantity)
+	if err != nil {
+		return err
+	}
+
+	err = lockSeats(tx, ticket, line.request.SeatIDs)
+	if err != nil {
+		return err
+	}
+
+	unitPrice, _, err := currentPrice(tx, ticket)
+	if err != nil {
+		return err
+	}
+
+	line.ticket = ticket
+	line.purchase = &models.Purchase{
+		TicketID: ticket.ID, BuyerID: buyerID, Quantity: line.request.Quantity, UnitPrice: unitPrice,
+		SeatIDs: line.request.SeatIDs,
+	}
+	return nil
+}
+
+// takePoolCapacities takes the capacity of all lines of a pool at once, pool by pool
+// in ID order. When a pool is full, every line in it fails.
+func (s *OrderService) takePoolCapacities(tx *sql.Tx, lines []*orderLine, fail func(*orderLine, error) error) error {
+	poolLines := make(map[int][]*orderLine)
+	var poolIDs []int
+	for _, line := range lines {
+		poolID := line.ticket.CapacityPoolID
+		if poolID == nil {
+			continue
+		}
+		if _, ok := poolLines[*poolID]; !ok {
+			poolIDs = append(poolIDs, *poolID)
+		}
+		poolLines[*poolID] = append(poolLines[*poolID], line)
+	}
+	sort.Ints(poolIDs)
+
+	for _, poolID := range poolIDs {
+		quantity := 0
+		for _, line := range poolLines[poolID] {
+			quantity += line.purchase.Quantity
+		}
+
+		err := takePoolCapacity(tx, &poolID, quantity)
+		if err != nil {
+			for _, line := range poolLines[poolID] {
+				if err := fail(line, err); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// redeemPromoCodes redeems the promo codes of the lines in code order.
+func (s *OrderService) redeemPromoCodes(tx *sql.Tx, lines []*orderLine, fail func(*orderLine, error) error) error {
+	var promoLines []*orderLine
+	for _, line := range lines {
+		if line.request.PromoCode != "" {
+			promoLines = append(promoLines, line)
+		}
+	}
+	sort.SliceStable(promoLines, func(i, j int) bool {
+		return normalizePromoCode(promoLines[i].request.PromoCode) < normalizePromoCode(promoLines[j].request.PromoCode)
+	})
+
+	for _, line := range promoLines {
+		subtotal, err := line.purchase.UnitPrice.Mul(int64(line.purchase.Quantity))
+		if err != nil {
+			err = errors.NewRestError("Purchase total is too large", 400)
+		} else {
+			var promoCode *models.PromoCode
+			promoCode, line.purchase.Discount, err = redeemPromoCode(tx, line.request.PromoCode, line.ticket, subtotal)
+			if err == nil {
+				line.purchase.PromoCodeID = &promoCode.ID
+			}
+		}
+		if err != nil {
+			if err := fail(line, err); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *OrderService) GetOrder(organizerID int, id int) (*models.Order, error) {
+	order := &models.Order{Purchases: []models.Purchase{}}
+
+	err := s.DB.QueryRow(
+		"SELECT id, organizer_id, buyer_id, created_at FROM purchase_order WHERE id = $1 AND organizer_id = $2",
+		id, organizerID,
+	).Scan(&order.ID, &order.OrganizerID, &order.BuyerID, &order.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NewRestError(fmt.Sprintf("Order %d not found", id), 404)
+		}
+		return nil, fmt.Errorf("failed to get order: %v", err)
+	}
+
+	rows, err := s.DB.Query("SELECT "+purchaseColumns+" FROM purchase WHERE order_id = $1 ORDER BY id", id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list order purchases: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		purchase := models.Purchase{}
+		err := scanPurchase(rows, &purchase)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan purchase: %v", err)
+		}
+		order.Purchases = append(order.Purchases, purchase)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list order purchases: %v", err)
+	}
+
+	return order, nil
+}
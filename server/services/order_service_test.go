@@ -0,0 +1,166 @@
+package services_test
+
+import (
+	"gowitcase/errors"
+	"gowitcase/mocks"
+	"gowitcase/models"
+	"gowitcase/services"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupOrderTest(t *testing.T) (*services.OrderService, sqlmock.Sqlmock) {
+	mockDB, mock, err := mocks.NewMockDatabase()
+	assert.NoError(t, err)
+
+	orderService := services.NewOrderService(mockDB, mocks.NewMockRedis())
+
+	return orderService, mock
+}
+
+func TestCheckout_Success(t *testing.T) {
+	orderService, mock := setupOrderTest(t)
+
+	vip := &models.Ticket{ID: 2, Name: "VIP", Allocation: 10, Price: models.NewMoney(10000, "EUR")}
+	ga := &models.Ticket{ID: 1, Name: "GA", Allocation: 100, Price: models.NewMoney(2500, "EUR")}
+
+	mock.ExpectBegin()
+
+	// Tickets are locked in ID order, whatever the order of the lines.
+	mock.ExpectQuery("SELECT (.+) FROM ticket WHERE id = (.+) FOR UPDATE").
+		WithArgs(1, testOrganizerID).
+		WillReturnRows(newTicketRows(ga))
+
+	expectWaitlistDemand(mock, 1, 0)
+
+	mock.ExpectQuery("SELECT (.+) FROM ticket WHERE id = (.+) FOR UPDATE").
+		WithArgs(2, testOrganizerID).
+		WillReturnRows(newTicketRows(vip))
+
+	expectWaitlistDemand(mock, 2, 0)
+
+	mock.ExpectQuery("INSERT INTO purchase_order").
+		WithArgs(testOrganizerID, "buyer").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(3, time.Now()))
+
+	mock.ExpectExec("UPDATE ticket SET allocation").
+		WithArgs(8, 0, 2, 2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	mock.ExpectQuery("INSERT INTO purchase").
+		WithArgs(2, "buyer", 2, int64(10000), int64(0), int64(20000), "EUR", nil, nil, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(10, time.Now()))
+
+	expectTicketInstances(mock, 10)
+
+	mock.ExpectExec("UPDATE ticket SET allocation").
+		WithArgs(96, 0, 4, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	mock.ExpectQuery("INSERT INTO purchase").
+		WithArgs(1, "buyer", 4, int64(2500), int64(0), int64(10000), "EUR", nil, nil, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(11, time.Now()))
+
+	expectTicketInstances(mock, 11)
+
+	mock.ExpectCommit()
+
+	order, err := orderService.Checkout(testOrganizerID, models.OrderRequest{
+		BuyerID: "buyer",
+		Lines: []models.OrderLineRequest{
+			{TicketID: 2, Quantity: 2},
+			{TicketID: 1, Quantity: 4},
+		},
+	})
+	assert.NoError(t, err, "failed to check out order")
+	assert.Equal(t, 3, order.ID)
+	assert.Len(t, order.Purchases, 2, "expected a purchase per line")
+	assert.Equal(t, 2, order.Purchases[0].TicketID, "expected purchases in line order")
+	assert.Equal(t, 3, *order.Purchases[1].OrderID)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCheckout_ReportsEveryFailedLine(t *testing.T) {
+	orderService, mock := setupOrderTest(t)
+
+	mock.ExpectBegin()
+
+	mock.ExpectQuery("SELECT (.+) FROM ticket WHERE id = (.+) FOR UPDATE").
+		WithArgs(1, testOrganizerID).
+		WillReturnRows(newTicketRows(&models.Ticket{ID: 1, Name: "GA", Allocation: 100}))
+
+	expectWaitlistDemand(mock, 1, 0)
+
+	mock.ExpectQuery("SELECT (.+) FROM ticket WHERE id = (.+) FOR UPDATE").
+		WithArgs(2, testOrganizerID).
+		WillReturnRows(newTicketRows(&models.Ticket{ID: 2, Name: "VIP", Allocation: 1}))
+
+	expectWaitlistDemand(mock, 2, 0)
+
+	mock.ExpectQuery("SELECT (.+) FROM ticket WHERE id = (.+) FOR UPDATE").
+		WithArgs(3, testOrganizerID).
+		WillReturnRows(newTicketRows())
+
+	mock.ExpectRollback()
+
+	_, err := orderService.Checkout(testOrganizerID, models.OrderRequest{
+		BuyerID: "buyer",
+		Lines: []models.OrderLineRequest{
+			{TicketID: 3, Quantity: 1},
+			{TicketID: 2, Quantity: 2},
+			{TicketID: 1, Quantity: 1},
+		},
+	})
+	assert.Error(t, err, "expected error for an order with failed lines")
+	lineErrs, ok := err.(errors.LineErrors)
+	assert.True(t, ok, "expected LineErrors")
+	assert.Equal(t, 400, lineErrs.Status)
+	assert.Equal(t, []errors.LineError{
+		{Line: 0, TicketID: 3, Code: errors.CodeNotFound, Message: "Ticket 3 not found", Status: 404},
+		{Line: 1, TicketID: 2, Code: errors.CodeNotEnoughTickets, Message: "Not enough tickets available", Status: 400},
+	}, lineErrs.Lines)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCheckout_InvalidLines(t *testing.T) {
+	orderService, mock := setupOrderTest(t)
+
+	_, err := orderService.Checkout(testOrganizerID, models.OrderRequest{
+		BuyerID: "buyer",
+		Lines: []models.OrderLineRequest{
+			{TicketID: 1, Quantity: 1},
+			{TicketID: 1, Quantity: 2},
+			{TicketID: 2, Quantity: 0},
+		},
+	})
+	assert.Error(t, err, "expected error for invalid lines")
+	lineErrs, ok := err.(errors.LineErrors)
+	assert.True(t, ok, "expected LineErrors")
+	assert.Equal(t, 400, lineErrs.Status)
+	assert.Len(t, lineErrs.Lines, 2, "expected the duplicate and the empty line to fail")
+	assert.Equal(t, 1, lineErrs.Lines[0].Line)
+	assert.Equal(t, 2, lineErrs.Lines[1].Line)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetOrder_NotFound(t *testing.T) {
+	orderService, mock := setupOrderTest(t)
+
+	mock.ExpectQuery("SELECT id, organizer_id, buyer_id, created_at FROM purchase_order").
+		WithArgs(3, testOrganizerID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "organizer_id", "buyer_id", "created_at"}))
+
+	_, err := orderService.GetOrder(testOrganizerID, 3)
+	assert.Error(t, err, "expected error for an unknown order")
+	restErr, ok := err.(errors.RestError)
+	assert.True(t, ok, "expected a RestError")
+	assert.Equal(t, 404, restErr.Status)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
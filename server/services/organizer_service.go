@@ -0,0 +1,164 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"gowitcase/db"
+	"gowitcase/errors"
+	"gowitcase/models"
+	"log"
+	"strconv"
+	"time"
+)
+
+type OrganizerService struct {
+	DB    db.DatabaseInterface
+	Cache db.RedisInterface
+}
+
+func NewOrganizerService(db db.DatabaseInterface, cache db.RedisInterface) *OrganizerService {
+	return &OrganizerService{DB: db, Cache: cache}
+}
+
+// CreateOrganizer stores the organizer with a new API key, which is returned in
+// APIKey and can't be read back afterwards.
+func (s *OrganizerService) CreateOrganizer(organizer *models.Organizer) error {
+	if organizer == nil {
+		return fmt.Errorf("organizer is nil")
+	}
+
+	err := validateOrganizer(*organizer)
+	if err != nil {
+		return err
+	}
+
+	apiKey, err := newAPIKey()
+	if err != nil {
+		return err
+	}
+
+	err = s.DB.QueryRow(
+		"INSERT INTO organizer (name, api_key_hash) VALUES ($1, $2) RETURNING id, created_at, updated_at",
+		organizer.Name, hashAPIKey(apiKey),
+	).Scan(&organizer.ID, &organizer.CreatedAt, &organizer.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create organizer: %v", err)
+	}
+
+	organizer.APIKey = apiKey
+
+	return nil
+}
+
+// RotateAPIKey issues a new API key for the organizer. The previous key stops
+// working right away.
+func (s *OrganizerService) RotateAPIKey(id int) (*models.Organizer, error) {
+	apiKey, err := newAPIKey()
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.DB.BeginTransaction()
+	if err != nil {
+		log.Printf("Failed to start transaction: %v", err)
+		return nil, fmt.Errorf("failed to start transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	var previousHash *string
+	err = tx.QueryRow("SELECT api_key_hash FROM organizer WHERE id = $1 FOR UPDATE", id).Scan(&previousHash)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NewRestError(fmt.Sprintf("Organizer %d not found", id), 404)
+		}
+		return nil, fmt.Errorf("failed to get organizer: %v", err)
+	}
+
+	organizer := &models.Organizer{ID: id, APIKey: apiKey}
+	err = tx.QueryRow(
+		"UPDATE organizer SET api_key_hash = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2 RETURNING name, created_at, updated_at",
+		hashAPIKey(apiKey), id,
+	).Scan(&organizer.Name, &organizer.CreatedAt, &organizer.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update organizer: %v", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	if previousHash != nil {
+		err = s.Cache.Del(models.OrganizerKeyCachePrefix + *previousHash)
+		if err != nil {
+			log.Printf("Failed to invalidate cache: %v for organizer: %d", err, id)
+		}
+	}
+
+	return organizer, nil
+}
+
+// Authenticate returns the ID of the organizer owning apiKey. Only the hash of the
+// key is looked up, in the cache first.
+func (s *OrganizerService) Authenticate(apiKey string) (int, error) {
+	if apiKey == "" {
+		return 0, errors.NewRestError("API key is required", 401)
+	}
+
+	hash := hashAPIKey(apiKey)
+	cacheKey := models.OrganizerKeyCachePrefix + hash
+
+	cached, err := s.Cache.Get(cacheKey)
+	if err == nil {
+		organizerID, err := strconv.Atoi(cached)
+		if err == nil {
+			return organizerID, nil
+		}
+	}
+
+	var organizerID int
+	err = s.DB.QueryRow("SELECT id FROM organizer WHERE api_key_hash = $1", hash).Scan(&organizerID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, errors.NewRestError("Invalid API key", 401)
+		}
+		return 0, fmt.Errorf("failed to get organizer: %v", err)
+	}
+
+	err = s.Cache.Set(cacheKey, strconv.Itoa(organizerID), 5*time.Minute)
+	if err != nil {
+		log.Printf("Failed to cache organizer key: %v", err)
+	}
+
+	return organizerID, nil
+}
+
+func validateOrganizer(organizer models.Organizer) error {
+	var fields errors.FieldErrors
+
+	if organizer.Name == "" {
+		fields.Add("name", "Field 'name' is required")
+	} else if len(organizer.Name) > 255 {
+		fields.Add("name", "Field 'name' must be less than 255 characters")
+	}
+
+	return fields.Err()
+}
+
+func newAPIKey() (string, error) {
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate API key: %v", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(key), nil
+}
+
+func hashAPIKey(apiKey string) string {
+	hash := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,117 @@
+package services_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"gowitcase/errors"
+	"gowitcase/mocks"
+	"gowitcase/models"
+	"gowitcase/services"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupOrganizerTest(t *testing.T) (*services.OrganizerService, sqlmock.Sqlmock) {
+	mockDB, mock, err := mocks.NewMockDatabase()
+	assert.NoError(t, err)
+
+	organizerService := services.NewOrganizerService(mockDB, mocks.NewMockRedis())
+
+	return organizerService, mock
+}
+
+func hashTestAPIKey(apiKey string) string {
+	hash := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(hash[:])
+}
+
+func TestCreateOrganizer_Success(t *testing.T) {
+	organizerService, mock := setupOrganizerTest(t)
+
+	mock.ExpectQuery("INSERT INTO organizer").
+		WithArgs("Promoter", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow(testOrganizerID, time.Now(), time.Now()))
+
+	organizer := &models.Organizer{Name: "Promoter"}
+	err := organizerService.CreateOrganizer(organizer)
+	assert.NoError(t, err, "failed to create organizer")
+	assert.Equal(t, testOrganizerID, organizer.ID)
+	assert.NotEmpty(t, organizer.APIKey, "expected the API key to be returned")
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAuthenticate_CachesOrganizer(t *testing.T) {
+	organizerService, mock := setupOrganizerTest(t)
+
+	mock.ExpectQuery("SELECT id FROM organizer WHERE api_key_hash = ").
+		WithArgs(hashTestAPIKey("key")).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(testOrganizerID))
+
+	organizerID, err := organizerService.Authenticate("key")
+	assert.NoError(t, err, "failed to authenticate organizer")
+	assert.Equal(t, testOrganizerID, organizerID)
+
+	// Served from the cache without hitting the database.
+	organizerID, err = organizerService.Authenticate("key")
+	assert.NoError(t, err, "failed to authenticate organizer from cache")
+	assert.Equal(t, testOrganizerID, organizerID)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAuthenticate_InvalidKey(t *testing.T) {
+	organizerService, mock := setupOrganizerTest(t)
+
+	mock.ExpectQuery("SELECT id FROM organizer WHERE api_key_hash = ").
+		WithArgs(hashTestAPIKey("unknown")).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	_, err := organizerService.Authenticate("unknown")
+	assert.Error(t, err, "expected error for an unknown API key")
+	restErr, ok := err.(errors.RestError)
+	assert.True(t, ok, "expected a RestError")
+	assert.Equal(t, 401, restErr.Status)
+
+	_, err = organizerService.Authenticate("")
+	assert.Error(t, err, "expected error for a missing API key")
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRotateAPIKey_RevokesPreviousKey(t *testing.T) {
+	organizerService, mock := setupOrganizerTest(t)
+
+	mock.ExpectQuery("SELECT id FROM organizer WHERE api_key_hash = ").
+		WithArgs(hashTestAPIKey("key")).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(testOrganizerID))
+
+	_, err := organizerService.Authenticate("key")
+	assert.NoError(t, err, "failed to authenticate organizer")
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT api_key_hash FROM organizer WHERE id = (.+) FOR UPDATE").
+		WithArgs(testOrganizerID).
+		WillReturnRows(sqlmock.NewRows([]string{"api_key_hash"}).AddRow(hashTestAPIKey("key")))
+	mock.ExpectQuery("UPDATE organizer SET api_key_hash").
+		WithArgs(sqlmock.AnyArg(), testOrganizerID).
+		WillReturnRows(sqlmock.NewRows([]string{"name", "created_at", "updated_at"}).AddRow("Promoter", time.Now(), time.Now()))
+	mock.ExpectCommit()
+
+	organizer, err := organizerService.RotateAPIKey(testOrganizerID)
+	assert.NoError(t, err, "failed to rotate API key")
+	assert.NotEmpty(t, organizer.APIKey, "expected the new API key to be returned")
+
+	// The previous key isn't served from the cache anymore.
+	mock.ExpectQuery("SELECT id FROM organizer WHERE api_key_hash = ").
+		WithArgs(hashTestAPIKey("key")).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	_, err = organizerService.Authenticate("key")
+	assert.Error(t, err, "expected the previous API key to be rejected")
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,211 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"gowitcase/db"
+	"gowitcase/errors"
+	"gowitcase/models"
+	"log"
+	"math"
+	"sort"
+)
+
+const pricingRuleColumns = "id, sold_bps, change_bps, created_at"
+
+type PricingRuleService struct {
+	DB    db.DatabaseInterface
+	Cache db.RedisInterface
+}
+
+func NewPricingRuleService(db db.DatabaseInterface, cache db.RedisInterface) *PricingRuleService {
+	return &PricingRuleService{DB: db, Cache: cache}
+}
+
+func (s *PricingRuleService) GetPricingRules(organizerID int, ticketID int) (*models.PricingRules, error) {
+	var id int
+	err := s.DB.QueryRow("SELECT id FROM ticket WHERE id = $1 AND organizer_id = $2", ticketID, organizerID).Scan(&id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NewRestError(fmt.Sprintf("Ticket %d not found", ticketID), 404)
+		}
+		return nil, fmt.Errorf("failed to get ticket: %v", err)
+	}
+
+	rows, err := s.DB.Query(
+		"SELECT "+pricingRuleColumns+" FROM pricing_rule WHERE ticket_id = $1 ORDER BY sold_bps", ticketID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pricing rules: %v", err)
+	}
+	defer rows.Close()
+
+	rules := &models.PricingRules{TicketID: ticketID, Rules: []models.PricingRule{}}
+	for rows.Next() {
+		rule := models.PricingRule{}
+		if err := scanPricingRule(rows, &rule); err != nil {
+			return nil, fmt.Errorf("failed to scan pricing rule: %v", err)
+		}
+		rules.Rules = append(rules.Rules, rule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list pricing rules: %v", err)
+	}
+
+	return rules, nil
+}
+
+// SetPricingRules replaces all the pricing rules of the ticket. The ticket is
+// locked meanwhile, so a sale is priced either by the old rules or the new ones.
+func (s *PricingRuleService) SetPricingRules(organizerID int, ticketID int, rules models.PricingRules) (*models.PricingRules, error) {
+	err := validatePricingRules(rules.Rules)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.DB.BeginTransaction()
+	if err != nil {
+		log.Printf("Failed to start transaction: %v", err)
+		return nil, fmt.Errorf("failed to start transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	ticket, err := lockOrganizerTicket(tx, organizerID, ticketID)
+	if err != nil {
+		return nil, err
+	}
+
+	if ticket.ArchivedAt != nil {
+		return nil, errors.NewRestError(fmt.Sprintf("Ticket %d is archived", ticketID), 400).WithCode(errors.CodeTicketArchived)
+	}
+
+	_, err = tx.Exec("DELETE FROM pricing_rule WHERE ticket_id = $1", ticketID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete pricing rules: %v", err)
+	}
+
+	sort.Slice(rules.Rules, func(i, j int) bool { return rules.Rules[i].SoldBps < rules.Rules[j].SoldBps })
+
+	updated := &models.PricingRules{TicketID: ticketID, Rules: make([]models.PricingRule, 0, len(rules.Rules))}
+	for _, rule := range rules.Rules {
+		err = tx.QueryRow(
+			"INSERT INTO pricing_rule (ticket_id, sold_bps, change_bps) VALUES ($1, $2, $3) RETURNING id, created_at",
+			ticketID, rule.SoldBps, rule.ChangeBps,
+		).Scan(&rule.ID, &rule.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create pricing rule: %v", err)
+		}
+		updated.Rules = append(updated.Rules, rule)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	return updated, nil
+}
+
+// Quote previews the price of quantity tickets from the ticket's current counters.
+// A purchase made afterwards is priced again when the ticket is locked.
+func (s *PricingRuleService) Quote(organizerID int, ticketID int, quantity int) (*models.PriceQuote, error) {
+	if quantity == 0 {
+		quantity = 1
+	}
+
+	if quantity < 0 || quantity > math.MaxInt32 {
+		return nil, errors.NewRestError("Quantity must be a positive number within the valid range", 400)
+	}
+
+	ticket := &models.Ticket{}
+	err := scanTicket(s.DB.QueryRow(
+		"SELECT "+ticketColumns+" FROM ticket WHERE id = $1 AND organizer_id = $2",
+		ticketID, organizerID,
+	), ticket)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NewRestError(fmt.Sprintf("Ticket %d not found", ticketID), 404)
+		}
+		return nil, fmt.Errorf("failed to get ticket: %v", err)
+	}
+
+	quote := &models.PriceQuote{TicketID: ticket.ID, Quantity: quantity, BasePrice: ticket.Price}
+	quote.UnitPrice, quote.Rule, err = currentPrice(s.DB, ticket)
+	if err != nil {
+		return nil, err
+	}
+
+	quote.Total, err = quote.UnitPrice.Mul(int64(quantity))
+	if err != nil {
+		return nil, errors.NewRestError("Purchase total is too large", 400)
+	}
+
+	return quote, nil
+}
+
+// validatePricingRules checks every rule and reports all the invalid fields, keyed
+// by the index of their rule such as 'rules[2].sold_bps'.
+func validatePricingRules(rules []models.PricingRule) error {
+	if len(rules) > models.PricingRulesMax {
+		return errors.NewFieldError("rules", fmt.Sprintf("A ticket can have at most %d pricing rules", models.PricingRulesMax))
+	}
+
+	var fields errors.FieldErrors
+	thresholds := make(map[int64]bool, len(rules))
+	for i, rule := range rules {
+		path := fmt.Sprintf("rules[%d]", i)
+
+		if rule.SoldBps <= 0 || rule.SoldBps > 10000 {
+			fields.Add(path+".sold_bps", "Field 'sold_bps' must be between 1 and 10000")
+		} else if thresholds[rule.SoldBps] {
+			fields.Add(path+".sold_bps", fmt.Sprintf("More than one pricing rule starts at %d basis points sold", rule.SoldBps))
+		}
+		thresholds[rule.SoldBps] = true
+
+		if rule.ChangeBps <= -10000 || rule.ChangeBps > math.MaxInt32 {
+			fields.Add(path+".change_bps", "Field 'change_bps' must be greater than -10000 and within the valid range")
+		}
+	}
+
+	return fields.Err()
+}
+
+// currentPrice is the unit price of the ticket at its current counters, with the
+// pricing rule that set it. Sales pass the locked ticket and their transaction, so
+// the price they charge matches the allocation they take tickets from.
+func currentPrice(q queryRower, ticket *models.Ticket) (models.Money, *models.PricingRule, error) {
+	total := int64(ticket.Allocation) + int64(ticket.Held) + int64(ticket.Sold)
+
+	// Every rule starts after some tickets are sold, so there is nothing to look up before.
+	if ticket.Sold == 0 || total == 0 {
+		return ticket.Price, nil, nil
+	}
+
+	rule := &models.PricingRule{}
+	err := scanPricingRule(q.QueryRow(
+		"SELECT "+pricingRuleColumns+" FROM pricing_rule WHERE ticket_id = $1 AND sold_bps::BIGINT * $2 <= $3::BIGINT * 10000 "+
+			"ORDER BY sold_bps DESC LIMIT 1",
+		ticket.ID, total, int64(ticket.Sold),
+	), rule)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ticket.Price, nil, nil
+		}
+		return models.Money{}, nil, fmt.Errorf("failed to get pricing rule: %v", err)
+	}
+
+	change, err := ticket.Price.Percent(rule.ChangeBps)
+	if err == nil {
+		var price models.Money
+		price, err = ticket.Price.Add(change)
+		if err == nil {
+			return price, rule, nil
+		}
+	}
+
+	return models.Money{}, nil, errors.NewRestError("Ticket price is too large", 400)
+}
+
+func scanPricingRule(row rowScanner, rule *models.PricingRule) error {
+	return row.Scan(&rule.ID, &rule.SoldBps, &rule.ChangeBps, &rule.CreatedAt)
+}
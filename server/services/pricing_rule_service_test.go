@@ -0,0 +1,186 @@
+package services_test
+
+import (
+	"gowitcase/errors"
+	"gowitcase/mocks"
+	"gowitcase/models"
+	"gowitcase/services"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupPricingRuleTest(t *testing.T) (*services.PricingRuleService, sqlmock.Sqlmock) {
+	mockDB, mock, err := mocks.NewMockDatabase()
+	assert.NoError(t, err)
+
+	pricingRuleService := services.NewPricingRuleService(mockDB, mocks.NewMockRedis())
+
+	return pricingRuleService, mock
+}
+
+func newPricingRuleRows(rules ...models.PricingRule) *sqlmock.Rows {
+	rows := sqlmock.NewRows([]string{"id", "sold_bps", "change_bps", "created_at"})
+	for _, rule := range rules {
+		rows.AddRow(rule.ID, rule.SoldBps, rule.ChangeBps, rule.CreatedAt)
+	}
+	return rows
+}
+
+// expectNoPricingRule expects the pricing rules of a ticket that has sold tickets
+// to be looked up, without any rule in effect.
+func expectNoPricingRule(mock sqlmock.Sqlmock, ticketID int) {
+	mock.ExpectQuery("SELECT (.+) FROM pricing_rule WHERE ticket_id = ").
+		WithArgs(ticketID, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(newPricingRuleRows())
+}
+
+func TestPurchaseTicket_AppliesPricingRule(t *testing.T) {
+	ticketService, mock := setupTest(t)
+
+	mock.ExpectBegin()
+
+	mock.ExpectQuery("SELECT (.+) FROM ticket WHERE id = (.+) FOR UPDATE").
+		WithArgs(1, testOrganizerID).
+		WillReturnRows(newTicketRows(&models.Ticket{ID: 1, Name: "GA", Allocation: 40, Sold: 60, Price: models.NewMoney(2000, "EUR")}))
+
+	expectWaitlistDemand(mock, 1, 0)
+
+	// 60 of 100 tickets are sold, so the rule starting at 50% is in effect.
+	mock.ExpectQuery("SELECT (.+) FROM pricing_rule WHERE ticket_id = ").
+		WithArgs(1, int64(100), int64(60)).
+		WillReturnRows(newPricingRuleRows(models.PricingRule{ID: 3, SoldBps: 5000, ChangeBps: 1000}))
+
+	mock.ExpectExec("UPDATE ticket SET allocation").
+		WithArgs(38, 0, 62, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	mock.ExpectQuery("INSERT INTO purchase").
+		WithArgs(1, "buyer", 2, int64(2200), int64(0), int64(4400), "EUR", nil, nil, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(7, time.Now()))
+
+	expectTicketInstances(mock, 7)
+
+	mock.ExpectCommit()
+
+	purchase, err := ticketService.PurchaseTicket(testOrganizerID, 1, models.PurchaseRequest{BuyerID: "buyer", Quantity: 2})
+	assert.NoError(t, err, "failed to purchase ticket")
+	assert.Equal(t, models.NewMoney(2200, "EUR"), purchase.UnitPrice, "expected the price of the pricing rule")
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestQuote_Success(t *testing.T) {
+	pricingRuleService, mock := setupPricingRuleTest(t)
+
+	mock.ExpectQuery("SELECT (.+) FROM ticket WHERE id = (.+) AND organizer_id = ").
+		WithArgs(1, testOrganizerID).
+		WillReturnRows(newTicketRows(&models.Ticket{ID: 1, Name: "GA", Allocation: 10, Sold: 90, Price: models.NewMoney(2000, "EUR")}))
+
+	mock.ExpectQuery("SELECT (.+) FROM pricing_rule WHERE ticket_id = ").
+		WithArgs(1, int64(100), int64(90)).
+		WillReturnRows(newPricingRuleRows(models.PricingRule{ID: 4, SoldBps: 9000, ChangeBps: 2500}))
+
+	quote, err := pricingRuleService.Quote(testOrganizerID, 1, 3)
+	assert.NoError(t, err, "failed to quote ticket")
+	assert.Equal(t, models.NewMoney(2000, "EUR"), quote.BasePrice)
+	assert.Equal(t, models.NewMoney(2500, "EUR"), quote.UnitPrice)
+	assert.Equal(t, models.NewMoney(7500, "EUR"), quote.Total)
+	assert.Equal(t, 4, quote.Rule.ID, "expected the rule in effect")
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestQuote_NothingSold(t *testing.T) {
+	pricingRuleService, mock := setupPricingRuleTest(t)
+
+	mock.ExpectQuery("SELECT (.+) FROM ticket WHERE id = (.+) AND organizer_id = ").
+		WithArgs(1, testOrganizerID).
+		WillReturnRows(newTicketRows(&models.Ticket{ID: 1, Name: "GA", Allocation: 100, Price: models.NewMoney(2000, "EUR")}))
+
+	quote, err := pricingRuleService.Quote(testOrganizerID, 1, 0)
+	assert.NoError(t, err, "failed to quote ticket")
+	assert.Equal(t, 1, quote.Quantity, "expected a quote for one ticket by default")
+	assert.Equal(t, models.NewMoney(2000, "EUR"), quote.UnitPrice)
+	assert.Nil(t, quote.Rule, "expected no rule before any ticket is sold")
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSetPricingRules_Success(t *testing.T) {
+	pricingRuleService, mock := setupPricingRuleTest(t)
+
+	mock.ExpectBegin()
+
+	mock.ExpectQuery("SELECT (.+) FROM ticket WHERE id = (.+) FOR UPDATE").
+		WithArgs(1, testOrganizerID).
+		WillReturnRows(newTicketRows(&models.Ticket{ID: 1, Name: "GA", Allocation: 100}))
+
+	mock.ExpectExec("DELETE FROM pricing_rule").
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	mock.ExpectQuery("INSERT INTO pricing_rule").
+		WithArgs(1, int64(5000), int64(1000)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(1, time.Now()))
+
+	mock.ExpectQuery("INSERT INTO pricing_rule").
+		WithArgs(1, int64(9000), int64(2500)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(2, time.Now()))
+
+	mock.ExpectCommit()
+
+	rules, err := pricingRuleService.SetPricingRules(testOrganizerID, 1, models.PricingRules{Rules: []models.PricingRule{
+		{SoldBps: 9000, ChangeBps: 2500},
+		{SoldBps: 5000, ChangeBps: 1000},
+	}})
+	assert.NoError(t, err, "failed to set pricing rules")
+	assert.Len(t, rules.Rules, 2)
+	assert.Equal(t, int64(5000), rules.Rules[0].SoldBps, "expected rules in threshold order")
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSetPricingRules_Invalid(t *testing.T) {
+	pricingRuleService, mock := setupPricingRuleTest(t)
+
+	tests := []struct {
+		name  string
+		rules []models.PricingRule
+	}{
+		{"zero threshold", []models.PricingRule{{SoldBps: 0, ChangeBps: 1000}}},
+		{"threshold above 100%", []models.PricingRule{{SoldBps: 10001, ChangeBps: 1000}}},
+		{"free tickets", []models.PricingRule{{SoldBps: 5000, ChangeBps: -10000}}},
+		{"duplicate threshold", []models.PricingRule{{SoldBps: 5000, ChangeBps: 1000}, {SoldBps: 5000, ChangeBps: 2000}}},
+	}
+
+	for _, tt := range tests {
+		_, err := pricingRuleService.SetPricingRules(testOrganizerID, 1, models.PricingRules{Rules: tt.rules})
+		assert.Error(t, err, "expected error for %s", tt.name)
+		restErr, ok := err.(errors.RestError)
+		assert.True(t, ok, "expected a RestError")
+		assert.Equal(t, 400, restErr.Status)
+	}
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSetPricingRules_ReportsEveryInvalidRule(t *testing.T) {
+	pricingRuleService, mock := setupPricingRuleTest(t)
+
+	_, err := pricingRuleService.SetPricingRules(testOrganizerID, 1, models.PricingRules{Rules: []models.PricingRule{
+		{SoldBps: 5000, ChangeBps: 1000},
+		{SoldBps: 0, ChangeBps: -10000},
+		{SoldBps: 5000, ChangeBps: 2000},
+	}})
+	assert.Error(t, err, "expected error for invalid rules")
+
+	restErr, ok := err.(errors.RestError)
+	assert.True(t, ok, "expected a RestError")
+	assert.Equal(t, errors.CodeValidationFailed, restErr.Code)
+	assert.Equal(t, []string{"rules[1].sold_bps", "rules[1].change_bps", "rules[2].sold_bps"}, fieldPaths(restErr))
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,351 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"gowitcase/db"
+	"gowitcase/errors"
+	"gowitcase/models"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const promoCodeColumns = "id, organizer_id, code, type, discount_bps, amount_off, currency, ticket_id, max_uses, used_count, expires_at, " +
+	"created_at, updated_at, archived_at"
+
+var promoCodePattern = regexp.MustCompile(`^[A-Z0-9_-]{3,64}$`)
+
+type PromoCodeService struct {
+	DB    db.DatabaseInterface
+	Cache db.RedisInterface
+}
+
+func NewPromoCodeService(db db.DatabaseInterface, cache db.RedisInterface) *PromoCodeService {
+	return &PromoCodeService{DB: db, Cache: cache}
+}
+
+// CreatePromoCode stores a new code of the organizer. Codes are case-insensitive
+// and kept upper case, and an organizer can't reuse a code, even after it was archived.
+func (s *PromoCodeService) CreatePromoCode(organizerID int, promoCode *models.PromoCode) error {
+	if promoCode == nil {
+		return fmt.Errorf("promo code is nil")
+	}
+
+	promoCode.OrganizerID = organizerID
+	promoCode.Code = normalizePromoCode(promoCode.Code)
+	if promoCode.AmountOff != nil && promoCode.AmountOff.Currency == "" {
+		promoCode.AmountOff.Currency = models.DefaultCurrency
+	}
+
+	err := s.ValidatePromoCode(*promoCode)
+	if err != nil {
+		return err
+	}
+
+	if promoCode.TicketID != nil {
+		var ticketID int
+		err = s.DB.QueryRow(
+			"SELECT id FROM ticket WHERE id = $1 AND organizer_id = $2", *promoCode.TicketID, organizerID,
+		).Scan(&ticketID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return errors.NewRestError(fmt.Sprintf("Ticket %d does not exist", *promoCode.TicketID), 400)
+			}
+			return fmt.Errorf("failed to get ticket: %v", err)
+		}
+	}
+
+	amountOff := models.NewMoney(0, models.DefaultCurrency)
+	if promoCode.AmountOff != nil {
+		amountOff = *promoCode.AmountOff
+	}
+
+	err = s.DB.QueryRow(
+		"INSERT INTO promo_code (organizer_id, code, type, discount_bps, amount_off, currency, ticket_id, max_uses, expires_at) "+
+			"VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) ON CONFLICT (organizer_id, code) DO NOTHING RETURNING id, created_at, updated_at",
+		promoCode.OrganizerID, promoCode.Code, promoCode.Type, promoCode.DiscountBps, amountOff.Amount, amountOff.Currency, promoCode.TicketID,
+		promoCode.MaxUses, promoCode.ExpiresAt,
+	).Scan(&promoCode.ID, &promoCode.CreatedAt, &promoCode.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errors.NewRestError(fmt.Sprintf("Promo code '%s' already exists", promoCode.Code), 409)
+		}
+		return fmt.Errorf("failed to create promo code: %v", err)
+	}
+
+	promoCode.UsedCount = 0
+
+	return nil
+}
+
+func (s *PromoCodeService) GetPromoCode(organizerID int, id int) (*models.PromoCode, error) {
+	promoCode := &models.PromoCode{}
+	err := scanPromoCode(s.DB.QueryRow(
+		"SELECT "+promoCodeColumns+" FROM promo_code WHERE id = $1 AND organizer_id = $2", id, organizerID,
+	), promoCode)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NewRestError(fmt.Sprintf("Promo code %d not found", id), 404)
+		}
+		return nil, fmt.Errorf("failed to get promo code: %v", err)
+	}
+
+	return promoCode, nil
+}
+
+// ListPromoCodes returns the promo codes of the organizer that aren't archived,
+// oldest first. The cursor is the next_cursor of the previous page.
+func (s *PromoCodeService) ListPromoCodes(organizerID int, cursor string, limit int) (*models.PromoCodeList, error) {
+	if limit < 0 {
+		return nil, errors.NewRestError("Limit must be a positive number", 400)
+	}
+	if limit == 0 {
+		limit = models.PromoCodeListDefaultLimit
+	}
+	if limit > models.PromoCodeListMaxLimit {
+		limit = models.PromoCodeListMaxLimit
+	}
+
+	afterID := 0
+	if cursor != "" {
+		var err error
+		afterID, err = decodeIDCursor(cursor)
+		if err != nil {
+			return nil, errors.NewRestError("Invalid cursor", 400)
+		}
+	}
+
+	// One extra row tells whether there is a next page.
+	rows, err := s.DB.Query(
+		"SELECT "+promoCodeColumns+" FROM promo_code WHERE organizer_id = $1 AND archived_at IS NULL AND id > $2 ORDER BY id LIMIT $3",
+		organizerID, afterID, limit+1,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list promo codes: %v", err)
+	}
+	defer rows.Close()
+
+	list := &models.PromoCodeList{PromoCodes: []models.PromoCode{}}
+	for rows.Next() {
+		promoCode := models.PromoCode{}
+		if err := scanPromoCode(rows, &promoCode); err != nil {
+			return nil, fmt.Errorf("failed to scan promo code: %v", err)
+		}
+		list.PromoCodes = append(list.PromoCodes, promoCode)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list promo codes: %v", err)
+	}
+
+	if len(list.PromoCodes) > limit {
+		list.PromoCodes = list.PromoCodes[:limit]
+		list.NextCursor = encodeIDCursor(list.PromoCodes[limit-1].ID)
+	}
+
+	return list, nil
+}
+
+// UpdatePromoCode changes the usage cap or expiry of a code. The cap can't go
+// below the number of times the code was already redeemed.
+func (s *PromoCodeService) UpdatePromoCode(organizerID int, id int, update models.PromoCodeUpdate) (*models.PromoCode, error) {
+	tx, err := s.DB.BeginTransaction()
+	if err != nil {
+		log.Printf("Failed to start transaction: %v", err)
+		return nil, fmt.Errorf("failed to start transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	promoCode := &models.PromoCode{}
+	err = scanPromoCode(tx.QueryRow(
+		"SELECT "+promoCodeColumns+" FROM promo_code WHERE id = $1 AND organizer_id = $2 FOR UPDATE", id, organizerID,
+	), promoCode)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NewRestError(fmt.Sprintf("Promo code %d not found", id), 404)
+		}
+		return nil, fmt.Errorf("failed to get promo code: %v", err)
+	}
+
+	if promoCode.ArchivedAt != nil {
+		return nil, errors.NewRestError(fmt.Sprintf("Promo code %d is archived", id), 400)
+	}
+
+	if update.MaxUses != nil {
+		promoCode.MaxUses = *update.MaxUses
+	}
+	if update.ExpiresAt.Set {
+		promoCode.ExpiresAt = update.ExpiresAt.Value
+	}
+
+	err = s.ValidatePromoCode(*promoCode)
+	if err != nil {
+		return nil, err
+	}
+
+	if promoCode.MaxUses > 0 && promoCode.MaxUses < promoCode.UsedCount {
+		return nil, errors.NewRestError(
+			fmt.Sprintf("Field 'max_uses' can't be less than the %d times the code was already used", promoCode.UsedCount), 400,
+		)
+	}
+
+	err = tx.QueryRow(
+		"UPDATE promo_code SET max_uses = $1, expires_at = $2, updated_at = CURRENT_TIMESTAMP WHERE id = $3 RETURNING updated_at",
+		promoCode.MaxUses, promoCode.ExpiresAt, promoCode.ID,
+	).Scan(&promoCode.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update promo code: %v", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	return promoCode, nil
+}
+
+// ArchivePromoCode deactivates the code, so it can't be redeemed anymore.
+// Purchases that already used it keep their discount.
+func (s *PromoCodeService) ArchivePromoCode(organizerID int, id int) error {
+	var archivedAt time.Time
+	err := s.DB.QueryRow(
+		"UPDATE promo_code SET archived_at = COALESCE(archived_at, CURRENT_TIMESTAMP), updated_at = CURRENT_TIMESTAMP "+
+			"WHERE id = $1 AND organizer_id = $2 RETURNING archived_at",
+		id, organizerID,
+	).Scan(&archivedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errors.NewRestError(fmt.Sprintf("Promo code %d not found", id), 404)
+		}
+		return fmt.Errorf("failed to archive promo code: %v", err)
+	}
+
+	return nil
+}
+
+// ValidatePromoCode checks every field of the promo code and reports all the invalid ones.
+func (s *PromoCodeService) ValidatePromoCode(promoCode models.PromoCode) error {
+	var fields errors.FieldErrors
+
+	if !promoCodePattern.MatchString(promoCode.Code) {
+		fields.Add("code", "Field 'code' must be 3 to 64 letters, digits, '-' or '_'")
+	}
+
+	switch promoCode.Type {
+	case models.PromoCodeTypePercentage:
+		if promoCode.DiscountBps <= 0 || promoCode.DiscountBps > 10000 {
+			fields.Add("discount_bps", "Field 'discount_bps' must be between 1 and 10000")
+		}
+		if promoCode.AmountOff != nil {
+			fields.Add("amount_off", "Field 'amount_off' can't be set on a percentage code")
+		}
+	case models.PromoCodeTypeFixed:
+		if promoCode.AmountOff == nil || promoCode.AmountOff.Amount <= 0 {
+			fields.Add("amount_off", "Field 'amount_off' must be a positive amount")
+		}
+		if promoCode.AmountOff != nil && !models.IsKnownCurrency(promoCode.AmountOff.Currency) {
+			fields.Add("amount_off.currency", fmt.Sprintf("Currency '%s' is not supported", promoCode.AmountOff.Currency))
+		}
+		if promoCode.DiscountBps != 0 {
+			fields.Add("discount_bps", "Field 'discount_bps' can't be set on a fixed code")
+		}
+	default:
+		fields.Add("type", "Field 'type' must be either 'percentage' or 'fixed'")
+	}
+
+	if promoCode.MaxUses < 0 {
+		fields.Add("max_uses", "Field 'max_uses' must not be negative")
+	}
+
+	return fields.Err()
+}
+
+// redeemPromoCode checks that code of the ticket's organizer can be used for the
+// purchase of ticket and counts the use. The code stays locked until the caller's transaction ends, so a capped
+// code can't be redeemed more often than allowed by concurrent purchases.
+func redeemPromoCode(tx *sql.Tx, code string, ticket *models.Ticket, subtotal models.Money) (*models.PromoCode, models.Money, error) {
+	code = normalizePromoCode(code)
+
+	promoCode := &models.PromoCode{}
+	err := scanPromoCode(tx.QueryRow(
+		"SELECT "+promoCodeColumns+" FROM promo_code WHERE organizer_id = $1 AND code = $2 FOR UPDATE", ticket.OrganizerID, code,
+	), promoCode)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, models.Money{}, errors.NewRestError(fmt.Sprintf("Promo code '%s' is not valid", code), 400).WithCode(errors.CodePromoCodeInvalid)
+		}
+		return nil, models.Money{}, fmt.Errorf("failed to get promo code: %v", err)
+	}
+
+	if promoCode.ArchivedAt != nil {
+		return nil, models.Money{}, errors.NewRestError(fmt.Sprintf("Promo code '%s' is not valid", code), 400).WithCode(errors.CodePromoCodeInvalid)
+	}
+
+	if promoCode.ExpiresAt != nil && !time.Now().Before(*promoCode.ExpiresAt) {
+		return nil, models.Money{}, errors.NewRestError(fmt.Sprintf("Promo code '%s' has expired", code), 400).WithCode(errors.CodePromoCodeExpired)
+	}
+
+	if promoCode.TicketID != nil && *promoCode.TicketID != ticket.ID {
+		return nil, models.Money{}, errors.NewRestError(fmt.Sprintf("Promo code '%s' does not apply to ticket %d", code, ticket.ID), 400).WithCode(errors.CodePromoCodeNotEligible)
+	}
+
+	if promoCode.MaxUses > 0 && promoCode.UsedCount >= promoCode.MaxUses {
+		return nil, models.Money{}, errors.NewRestError(fmt.Sprintf("Promo code '%s' has reached its usage limit", code), 400).WithCode(errors.CodePromoCodeExhausted)
+	}
+
+	var discount models.Money
+	if promoCode.Type == models.PromoCodeTypePercentage {
+		discount, err = subtotal.Percent(promoCode.DiscountBps)
+		if err != nil {
+			return nil, models.Money{}, fmt.Errorf("failed to compute discount: %v", err)
+		}
+	} else {
+		if promoCode.AmountOff.Currency != subtotal.Currency {
+			return nil, models.Money{}, errors.NewRestError(
+				fmt.Sprintf("Promo code '%s' is in %s but the ticket is priced in %s", code, promoCode.AmountOff.Currency, subtotal.Currency), 400,
+			).WithCode(errors.CodePromoCodeNotEligible)
+		}
+
+		// A fixed discount never makes the order cost less than nothing.
+		discount = *promoCode.AmountOff
+		if discount.Amount > subtotal.Amount {
+			discount = subtotal
+		}
+	}
+
+	_, err = tx.Exec(
+		"UPDATE promo_code SET used_count = used_count + 1, updated_at = CURRENT_TIMESTAMP WHERE id = $1",
+		promoCode.ID,
+	)
+	if err != nil {
+		return nil, models.Money{}, fmt.Errorf("failed to redeem promo code: %v", err)
+	}
+	promoCode.UsedCount++
+
+	return promoCode, discount, nil
+}
+
+func normalizePromoCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func scanPromoCode(row rowScanner, promoCode *models.PromoCode) error {
+	var amountOff models.Money
+	err := row.Scan(
+		&promoCode.ID, &promoCode.OrganizerID, &promoCode.Code, &promoCode.Type, &promoCode.DiscountBps, &amountOff.Amount, &amountOff.Currency,
+		&promoCode.TicketID, &promoCode.MaxUses, &promoCode.UsedCount, &promoCode.ExpiresAt,
+		&promoCode.CreatedAt, &promoCode.UpdatedAt, &promoCode.ArchivedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	promoCode.AmountOff = nil
+	if promoCode.Type == models.PromoCodeTypeFixed {
+		promoCode.AmountOff = &amountOff
+	}
+
+	return nil
+}
@@ -0,0 +1,273 @@
+package services_test
+
+import (
+	"gowitcase/errors"
+	"gowitcase/mocks"
+	"gowitcase/models"
+	"gowitcase/services"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupPromoCodeTest(t *testing.T) (*services.PromoCodeService, sqlmock.Sqlmock) {
+	mockDB, mock, err := mocks.NewMockDatabase()
+	assert.NoError(t, err)
+
+	promoCodeService := services.NewPromoCodeService(mockDB, mocks.NewMockRedis())
+
+	return promoCodeService, mock
+}
+
+func newPromoCodeRows(promoCodes ...*models.PromoCode) *sqlmock.Rows {
+	rows := sqlmock.NewRows([]string{
+		"id", "organizer_id", "code", "type", "discount_bps", "amount_off", "currency", "ticket_id", "max_uses", "used_count", "expires_at",
+		"created_at", "updated_at", "archived_at",
+	})
+	for _, promoCode := range promoCodes {
+		amountOff := models.NewMoney(0, models.DefaultCurrency)
+		if promoCode.AmountOff != nil {
+			amountOff = *promoCode.AmountOff
+		}
+		rows.AddRow(
+			promoCode.ID, promoCode.OrganizerID, promoCode.Code, promoCode.Type, promoCode.DiscountBps, amountOff.Amount, amountOff.Currency,
+			promoCode.TicketID, promoCode.MaxUses, promoCode.UsedCount, promoCode.ExpiresAt,
+			promoCode.CreatedAt, promoCode.UpdatedAt, promoCode.ArchivedAt,
+		)
+	}
+	return rows
+}
+
+func TestCreatePromoCode_Success(t *testing.T) {
+	promoCodeService, mock := setupPromoCodeTest(t)
+
+	mock.ExpectQuery("INSERT INTO promo_code").
+		WithArgs(testOrganizerID, "SUMMER25", models.PromoCodeTypePercentage, int64(2500), int64(0), "EUR", nil, 100, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow(1, time.Now(), time.Now()))
+
+	promoCode := &models.PromoCode{Code: " summer25 ", Type: models.PromoCodeTypePercentage, DiscountBps: 2500, MaxUses: 100}
+	err := promoCodeService.CreatePromoCode(testOrganizerID, promoCode)
+	assert.NoError(t, err, "failed to create promo code")
+	assert.Equal(t, "SUMMER25", promoCode.Code, "expected code to be normalized")
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
+
+func TestCreatePromoCode_AlreadyExists(t *testing.T) {
+	promoCodeService, mock := setupPromoCodeTest(t)
+
+	mock.ExpectQuery("INSERT INTO promo_code").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}))
+
+	err := promoCodeService.CreatePromoCode(testOrganizerID, &models.PromoCode{Code: "SUMMER25", Type: models.PromoCodeTypePercentage, DiscountBps: 2500})
+	assert.Error(t, err, "expected error when code already exists")
+
+	restErr, ok := err.(errors.RestError)
+	assert.True(t, ok, "expected rest error")
+	assert.Equal(t, 409, restErr.Status, "expected conflict status")
+}
+
+func TestCreatePromoCode_Invalid(t *testing.T) {
+	promoCodeService, _ := setupPromoCodeTest(t)
+
+	tests := []*models.PromoCode{
+		{Code: "X", Type: models.PromoCodeTypePercentage, DiscountBps: 1000},
+		{Code: "SALE", Type: "bogus", DiscountBps: 1000},
+		{Code: "SALE", Type: models.PromoCodeTypePercentage, DiscountBps: 10001},
+		{Code: "SALE", Type: models.PromoCodeTypeFixed},
+		{Code: "SALE", Type: models.PromoCodeTypeFixed, AmountOff: &models.Money{Amount: 500, Currency: "XXX"}},
+		{Code: "SALE", Type: models.PromoCodeTypePercentage, DiscountBps: 1000, MaxUses: -1},
+	}
+
+	for _, promoCode := range tests {
+		err := promoCodeService.CreatePromoCode(testOrganizerID, promoCode)
+		assert.Error(t, err, "expected error for invalid promo code %+v", promoCode)
+	}
+}
+
+func TestUpdatePromoCode_MaxUsesBelowUsed(t *testing.T) {
+	promoCodeService, mock := setupPromoCodeTest(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT (.+) FROM promo_code WHERE id = (.+) AND organizer_id = (.+) FOR UPDATE").
+		WithArgs(1, testOrganizerID).
+		WillReturnRows(newPromoCodeRows(&models.PromoCode{
+			ID: 1, Code: "SALE", Type: models.PromoCodeTypePercentage, DiscountBps: 1000, MaxUses: 10, UsedCount: 5,
+		}))
+	mock.ExpectRollback()
+
+	maxUses := 4
+	_, err := promoCodeService.UpdatePromoCode(testOrganizerID, 1, models.PromoCodeUpdate{MaxUses: &maxUses})
+	assert.Error(t, err, "expected error when max uses is below used count")
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
+
+func TestPurchaseTicket_PercentagePromoCode(t *testing.T) {
+	ticketService, mock := setupTest(t)
+
+	mock.ExpectBegin()
+
+	mock.ExpectQuery("SELECT (.+) FROM ticket WHERE id = (.+) FOR UPDATE").
+		WithArgs(1, testOrganizerID).
+		WillReturnRows(newTicketRows(&models.Ticket{ID: 1, OrganizerID: testOrganizerID, Name: "test", Allocation: 10, Price: models.NewMoney(1999, "EUR")}))
+
+	expectWaitlistDemand(mock, 1, 0)
+
+	mock.ExpectQuery("SELECT (.+) FROM promo_code WHERE organizer_id = (.+) AND code = (.+) FOR UPDATE").
+		WithArgs(testOrganizerID, "SUMMER25").
+		WillReturnRows(newPromoCodeRows(&models.PromoCode{
+			ID: 4, Code: "SUMMER25", Type: models.PromoCodeTypePercentage, DiscountBps: 2500, MaxUses: 10, UsedCount: 9,
+		}))
+
+	mock.ExpectExec("UPDATE promo_code SET used_count = used_count \\+ 1").
+		WithArgs(4).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	mock.ExpectExec("UPDATE ticket SET allocation").
+		WithArgs(8, 0, 2, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	mock.ExpectQuery("INSERT INTO purchase").
+		WithArgs(1, "buyer", 2, int64(1999), int64(1000), int64(2998), "EUR", 4, nil, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(1, time.Now()))
+
+	expectTicketInstances(mock, 1)
+
+	mock.ExpectCommit()
+
+	purchase, err := ticketService.PurchaseTicket(testOrganizerID, 1, models.PurchaseRequest{BuyerID: "buyer", Quantity: 2, PromoCode: "summer25"})
+	assert.NoError(t, err, "failed to purchase ticket with promo code")
+	assert.Equal(t, models.NewMoney(1000, "EUR"), purchase.Discount, "expected 25% of the order, rounded")
+	assert.Equal(t, models.NewMoney(2998, "EUR"), purchase.Total, "expected discounted total")
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
+
+func TestPurchaseTicket_FixedPromoCodeCappedAtTotal(t *testing.T) {
+	ticketService, mock := setupTest(t)
+
+	mock.ExpectBegin()
+
+	mock.ExpectQuery("SELECT (.+) FROM ticket WHERE id = (.+) FOR UPDATE").
+		WithArgs(1, testOrganizerID).
+		WillReturnRows(newTicketRows(&models.Ticket{ID: 1, OrganizerID: testOrganizerID, Name: "test", Allocation: 10, Price: models.NewMoney(500, "EUR")}))
+
+	expectWaitlistDemand(mock, 1, 0)
+
+	mock.ExpectQuery("SELECT (.+) FROM promo_code WHERE organizer_id = (.+) AND code = (.+) FOR UPDATE").
+		WithArgs(testOrganizerID, "TENOFF").
+		WillReturnRows(newPromoCodeRows(&models.PromoCode{
+			ID: 5, Code: "TENOFF", Type: models.PromoCodeTypeFixed, AmountOff: &models.Money{Amount: 1000, Currency: "EUR"},
+		}))
+
+	mock.ExpectExec("UPDATE promo_code SET used_count").
+		WithArgs(5).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	mock.ExpectExec("UPDATE ticket SET allocation").
+		WithArgs(9, 0, 1, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	mock.ExpectQuery("INSERT INTO purchase").
+		WithArgs(1, "buyer", 1, int64(500), int64(500), int64(0), "EUR", 5, nil, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(1, time.Now()))
+
+	expectTicketInstances(mock, 1)
+
+	mock.ExpectCommit()
+
+	purchase, err := ticketService.PurchaseTicket(testOrganizerID, 1, models.PurchaseRequest{BuyerID: "buyer", Quantity: 1, PromoCode: "TENOFF"})
+	assert.NoError(t, err, "failed to purchase ticket with promo code")
+	assert.Equal(t, int64(0), purchase.Total.Amount, "expected discount to be capped at the order total")
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
+
+func TestPurchaseTicket_PromoCodeUsageLimitReached(t *testing.T) {
+	ticketService, mock := setupTest(t)
+
+	mock.ExpectBegin()
+
+	mock.ExpectQuery("SELECT (.+) FROM ticket WHERE id = (.+) FOR UPDATE").
+		WithArgs(1, testOrganizerID).
+		WillReturnRows(newTicketRows(&models.Ticket{ID: 1, OrganizerID: testOrganizerID, Name: "test", Allocation: 10, Price: models.NewMoney(500, "EUR")}))
+
+	expectWaitlistDemand(mock, 1, 0)
+
+	mock.ExpectQuery("SELECT (.+) FROM promo_code WHERE organizer_id = (.+) AND code = (.+) FOR UPDATE").
+		WithArgs(testOrganizerID, "SALE").
+		WillReturnRows(newPromoCodeRows(&models.PromoCode{
+			ID: 4, Code: "SALE", Type: models.PromoCodeTypePercentage, DiscountBps: 1000, MaxUses: 10, UsedCount: 10,
+		}))
+
+	mock.ExpectRollback()
+
+	_, err := ticketService.PurchaseTicket(testOrganizerID, 1, models.PurchaseRequest{BuyerID: "buyer", Quantity: 1, PromoCode: "SALE"})
+	assert.EqualError(t, err, "Promo code 'SALE' has reached its usage limit", "expected error when code is used up")
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
+
+func TestPurchaseTicket_PromoCodeForOtherTicket(t *testing.T) {
+	ticketService, mock := setupTest(t)
+
+	otherTicketID := 2
+
+	mock.ExpectBegin()
+
+	mock.ExpectQuery("SELECT (.+) FROM ticket WHERE id = (.+) FOR UPDATE").
+		WithArgs(1, testOrganizerID).
+		WillReturnRows(newTicketRows(&models.Ticket{ID: 1, OrganizerID: testOrganizerID, Name: "test", Allocation: 10, Price: models.NewMoney(500, "EUR")}))
+
+	expectWaitlistDemand(mock, 1, 0)
+
+	mock.ExpectQuery("SELECT (.+) FROM promo_code WHERE organizer_id = (.+) AND code = (.+) FOR UPDATE").
+		WithArgs(testOrganizerID, "VIPONLY").
+		WillReturnRows(newPromoCodeRows(&models.PromoCode{
+			ID: 6, Code: "VIPONLY", Type: models.PromoCodeTypePercentage, DiscountBps: 1000, TicketID: &otherTicketID,
+		}))
+
+	mock.ExpectRollback()
+
+	_, err := ticketService.PurchaseTicket(testOrganizerID, 1, models.PurchaseRequest{BuyerID: "buyer", Quantity: 1, PromoCode: "VIPONLY"})
+	assert.EqualError(t, err, "Promo code 'VIPONLY' does not apply to ticket 1", "expected error when code is scoped to another ticket")
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
+
+func TestPurchaseTicket_ExpiredPromoCode(t *testing.T) {
+	ticketService, mock := setupTest(t)
+
+	expiresAt := time.Now().Add(-time.Hour)
+
+	mock.ExpectBegin()
+
+	mock.ExpectQuery("SELECT (.+) FROM ticket WHERE id = (.+) FOR UPDATE").
+		WithArgs(1, testOrganizerID).
+		WillReturnRows(newTicketRows(&models.Ticket{ID: 1, OrganizerID: testOrganizerID, Name: "test", Allocation: 10, Price: models.NewMoney(500, "EUR")}))
+
+	expectWaitlistDemand(mock, 1, 0)
+
+	mock.ExpectQuery("SELECT (.+) FROM promo_code WHERE organizer_id = (.+) AND code = (.+) FOR UPDATE").
+		WithArgs(testOrganizerID, "OLD").
+		WillReturnRows(newPromoCodeRows(&models.PromoCode{
+			ID: 7, Code: "OLD", Type: models.PromoCodeTypePercentage, DiscountBps: 1000, ExpiresAt: &expiresAt,
+		}))
+
+	mock.ExpectRollback()
+
+	_, err := ticketService.PurchaseTicket(testOrganizerID, 1, models.PurchaseRequest{BuyerID: "buyer", Quantity: 1, PromoCode: "OLD"})
+	assert.EqualError(t, err, "Promo code 'OLD' has expired", "expected error when code has expired")
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
@@ -0,0 +1,316 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"fmt"
+	"gowitcase/db"
+	"gowitcase/errors"
+	"gowitcase/models"
+	"log"
+	"math"
+	"strconv"
+)
+
+const purchaseColumns = "id, ticket_id, buyer_id, quantity, refunded_quantity, unit_price, discount, total, currency, " +
+	"promo_code_id, seat_ids, order_id, created_at"
+
+const refundColumns = "id, purchase_id, quantity, amount, currency, reason, refunded_by, created_at"
+
+type PurchaseService struct {
+	DB    db.DatabaseInterface
+	Cache db.RedisInterface
+}
+
+func NewPurchaseService(db db.DatabaseInterface, cache db.RedisInterface) *PurchaseService {
+	return &PurchaseService{DB: db, Cache: cache}
+}
+
+func (s *PurchaseService) GetPurchase(organizerID int, id int) (*models.Purchase, error) {
+	purchase := &models.Purchase{}
+
+	err := scanPurchase(s.DB.QueryRow(
+		"SELECT "+purchaseColumns+" FROM purchase WHERE id = $1 AND ticket_id IN (SELECT id FROM ticket WHERE organizer_id = $2)",
+		id, organizerID,
+	), purchase)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NewRestError(fmt.Sprintf("Purchase %d not found", id), 404)
+		}
+		return nil, err
+	}
+
+	return purchase, nil
+}
+
+// ListTicketPurchases returns the purchases of a ticket, oldest first. The cursor
+// is the next_cursor of the previous page.
+func (s *PurchaseService) ListTicketPurchases(organizerID int, ticketID int, cursor string, limit int) (*models.PurchaseList, error) {
+	if limit < 0 {
+		return nil, errors.NewRestError("Limit must be a positive number", 400)
+	}
+	if limit == 0 {
+		limit = models.PurchaseListDefaultLimit
+	}
+	if limit > models.PurchaseListMaxLimit {
+		limit = models.PurchaseListMaxLimit
+	}
+
+	afterID := 0
+	if cursor != "" {
+		var err error
+		afterID, err = decodeIDCursor(cursor)
+		if err != nil {
+			return nil, errors.NewRestError("Invalid cursor", 400)
+		}
+	}
+
+	var id int
+	err := s.DB.QueryRow("SELECT id FROM ticket WHERE id = $1 AND organizer_id = $2", ticketID, organizerID).Scan(&id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NewRestError(fmt.Sprintf("Ticket %d not found", ticketID), 404)
+		}
+		return nil, fmt.Errorf("failed to get ticket: %v", err)
+	}
+
+	// One extra row tells whether there is a next page.
+	rows, err := s.DB.Query(
+		"SELECT "+purchaseColumns+" FROM purchase WHERE ticket_id = $1 AND id > $2 ORDER BY id LIMIT $3",
+		ticketID, afterID, limit+1,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list purchases: %v", err)
+	}
+	defer rows.Close()
+
+	list := &models.PurchaseList{Purchases: []models.Purchase{}}
+	for rows.Next() {
+		purchase := models.Purchase{}
+		if err := scanPurchase(rows, &purchase); err != nil {
+			return nil, fmt.Errorf("failed to scan purchase: %v", err)
+		}
+		list.Purchases = append(list.Purchases, purchase)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list purchases: %v", err)
+	}
+
+	if len(list.Purchases) > limit {
+		list.Purchases = list.Purchases[:limit]
+		list.NextCursor = encodeIDCursor(list.Purchases[limit-1].ID)
+	}
+
+	return list, nil
+}
+
+// RefundPurchase gives back some or all tickets of a purchase and returns them to
+// the ticket's allocation in the same transaction, offering them to the waitlist
+// first. A purchase can't be refunded for more tickets than it has left. Refunded
+// seats go back on sale and the instances of refunded tickets are voided.
+func (s *PurchaseService) RefundPurchase(organizerID int, purchaseID int, request models.RefundRequest) (*models.Refund, error) {
+	var fields errors.FieldErrors
+	request.Quantity = seatQuantity(request.Quantity, request.SeatIDs, &fields)
+	if !fields.Has("quantity") && (request.Quantity < 0 || request.Quantity > math.MaxInt32) {
+		fields.Add("quantity", "Field 'quantity' must be a positive number within the valid range")
+	}
+
+	if request.Reason == "" {
+		fields.Add("reason", "Field 'reason' is required")
+	}
+
+	if request.RefundedBy == "" {
+		fields.Add("refunded_by", "Field 'refunded_by' is required")
+	} else if len(request.RefundedBy) > 255 {
+		fields.Add("refunded_by", "Field 'refunded_by' must be less than 255 characters")
+	}
+
+	if err := fields.Err(); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.DB.BeginTransaction()
+	if err != nil {
+		log.Printf("Failed to start transaction: %v", err)
+		return nil, fmt.Errorf("failed to start transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	purchase := &models.Purchase{}
+	err = scanPurchase(tx.QueryRow(
+		"SELECT "+purchaseColumns+" FROM purchase "+
+			"WHERE id = $1 AND ticket_id IN (SELECT id FROM ticket WHERE organizer_id = $2) FOR UPDATE",
+		purchaseID, organizerID,
+	), purchase)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NewRestError(fmt.Sprintf("Purchase %d not found", purchaseID), 404)
+		}
+		return nil, fmt.Errorf("failed to get purchase: %v", err)
+	}
+
+	refundable := purchase.Quantity - purchase.RefundedQuantity
+	if refundable == 0 {
+		return nil, errors.NewRestError("Purchase is already fully refunded", 400)
+	}
+
+	quantity := request.Quantity
+	if quantity == 0 {
+		quantity = refundable
+	}
+
+	if quantity > refundable {
+		return nil, errors.NewRestError(fmt.Sprintf("Only %d tickets of the purchase can be refunded", refundable), 400)
+	}
+
+	ticket, err := lockOrganizerTicket(tx, organizerID, purchase.TicketID)
+	if err != nil {
+		return nil, err
+	}
+
+	seatIDs, err := refundSeats(tx, ticket, purchase.ID, request.SeatIDs, quantity)
+	if err != nil {
+		return nil, err
+	}
+
+	err = voidTicketInstances(tx, purchase.ID, quantity, seatIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	ticket.Sold -= quantity
+	ticket.Allocation += quantity
+
+	err = updateTicketInventory(tx, ticket)
+	if err != nil {
+		return nil, err
+	}
+
+	err = returnPoolCapacity(tx, ticket.CapacityPoolID, quantity)
+	if err != nil {
+		return nil, err
+	}
+
+	err = offerToWaitlist(tx, ticket)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(
+		"UPDATE purchase SET refunded_quantity = refunded_quantity + $1 WHERE id = $2",
+		quantity, purchase.ID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update purchase: %v", err)
+	}
+
+	amount, err := refundAmount(purchase, quantity)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute refund amount: %v", err)
+	}
+
+	refund := &models.Refund{
+		PurchaseID: purchase.ID,
+		Quantity:   quantity,
+		Amount:     amount,
+		Reason:     request.Reason,
+		RefundedBy: request.RefundedBy,
+	}
+	err = tx.QueryRow(
+		"INSERT INTO purchase_refund (purchase_id, quantity, amount, currency, reason, refunded_by) VALUES ($1, $2, $3, $4, $5, $6) "+
+			"RETURNING id, created_at",
+		refund.PurchaseID, refund.Quantity, refund.Amount.Amount, refund.Amount.Currency, refund.Reason, refund.RefundedBy,
+	).Scan(&refund.ID, &refund.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create refund: %v", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	err = invalidateTicketCache(s.Cache, ticket.OrganizerID, purchase.TicketID)
+	if err != nil {
+		log.Printf("Failed to invalidate cache: %v for ticket: %d", err, purchase.TicketID)
+	}
+
+	return refund, nil
+}
+
+func (s *PurchaseService) ListRefunds(organizerID int, purchaseID int) ([]models.Refund, error) {
+	_, err := s.GetPurchase(organizerID, purchaseID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.DB.Query(
+		"SELECT "+refundColumns+" FROM purchase_refund WHERE purchase_id = $1 ORDER BY id",
+		purchaseID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list refunds: %v", err)
+	}
+	defer rows.Close()
+
+	refunds := []models.Refund{}
+	for rows.Next() {
+		refund := models.Refund{}
+		if err := scanRefund(rows, &refund); err != nil {
+			return nil, fmt.Errorf("failed to scan refund: %v", err)
+		}
+		refunds = append(refunds, refund)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list refunds: %v", err)
+	}
+
+	return refunds, nil
+}
+
+// refundAmount is the part of the purchase total paid for the next quantity tickets.
+// Discounts are spread evenly over the tickets, and the last refund gets whatever
+// rounding left over, so all refunds of a purchase add up to its total.
+func refundAmount(purchase *models.Purchase, quantity int) (models.Money, error) {
+	refunded, err := purchase.Total.Share(int64(purchase.RefundedQuantity), int64(purchase.Quantity))
+	if err != nil {
+		return models.Money{}, err
+	}
+
+	refundedAfter, err := purchase.Total.Share(int64(purchase.RefundedQuantity+quantity), int64(purchase.Quantity))
+	if err != nil {
+		return models.Money{}, err
+	}
+
+	return refundedAfter.Sub(refunded)
+}
+
+func encodeIDCursor(id int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(id)))
+}
+
+func decodeIDCursor(encoded string) (int, error) {
+	idBytes, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(string(idBytes))
+}
+
+func scanPurchase(row rowScanner, purchase *models.Purchase) error {
+	err := row.Scan(
+		&purchase.ID, &purchase.TicketID, &purchase.BuyerID, &purchase.Quantity, &purchase.RefundedQuantity,
+		&purchase.UnitPrice.Amount, &purchase.Discount.Amount, &purchase.Total.Amount, &purchase.UnitPrice.Currency,
+		&purchase.PromoCodeID, scanSeatIDs(&purchase.SeatIDs), &purchase.OrderID, &purchase.CreatedAt,
+	)
+	purchase.Discount.Currency = purchase.UnitPrice.Currency
+	purchase.Total.Currency = purchase.UnitPrice.Currency
+	return err
+}
+
+func scanRefund(row rowScanner, refund *models.Refund) error {
+	return row.Scan(
+		&refund.ID, &refund.PurchaseID, &refund.Quantity, &refund.Amount.Amount, &refund.Amount.Currency,
+		&refund.Reason, &refund.RefundedBy, &refund.CreatedAt,
+	)
+}
@@ -0,0 +1,333 @@
+package services_test
+
+import (
+	"database/sql"
+	"gowitcase/errors"
+	"gowitcase/mocks"
+	"gowitcase/models"
+	"gowitcase/services"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupPurchaseTest(t *testing.T) (*services.PurchaseService, sqlmock.Sqlmock) {
+	mockDB, mock, err := mocks.NewMockDatabase()
+	assert.NoError(t, err)
+
+	purchaseService := services.NewPurchaseService(mockDB, mocks.NewMockRedis())
+
+	return purchaseService, mock
+}
+
+func newPurchaseRows(purchases ...*models.Purchase) *sqlmock.Rows {
+	rows := sqlmock.NewRows([]string{
+		"id", "ticket_id", "buyer_id", "quantity", "refunded_quantity", "unit_price", "discount", "total", "currency",
+		"promo_code_id", "seat_ids", "order_id", "created_at",
+	})
+	for _, purchase := range purchases {
+		rows.AddRow(
+			purchase.ID, purchase.TicketID, purchase.BuyerID, purchase.Quantity, purchase.RefundedQuantity,
+			purchase.UnitPrice.Amount, purchase.Discount.Amount, purchase.Total.Amount, purchase.UnitPrice.Currency,
+			purchase.PromoCodeID, intArrayValue(purchase.SeatIDs), purchase.OrderID, purchase.CreatedAt,
+		)
+	}
+	return rows
+}
+
+func TestGetPurchase_Success(t *testing.T) {
+	purchaseService, mock := setupPurchaseTest(t)
+
+	purchase := &models.Purchase{ID: 1, TicketID: 2, BuyerID: "buyer", Quantity: 3, CreatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	mock.ExpectQuery("SELECT (.+) FROM purchase WHERE id = ").
+		WithArgs(purchase.ID, testOrganizerID).
+		WillReturnRows(newPurchaseRows(purchase))
+
+	returnedPurchase, err := purchaseService.GetPurchase(testOrganizerID, purchase.ID)
+	assert.NoError(t, err, "failed to get purchase")
+	assert.Equal(t, purchase, returnedPurchase, "expected purchase to match")
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
+
+func TestGetPurchase_NotFound(t *testing.T) {
+	purchaseService, mock := setupPurchaseTest(t)
+
+	mock.ExpectQuery("SELECT (.+) FROM purchase").
+		WithArgs(1, testOrganizerID).
+		WillReturnError(sql.ErrNoRows)
+
+	_, err := purchaseService.GetPurchase(testOrganizerID, 1)
+	assert.Error(t, err, "expected error when purchase not found")
+}
+
+func TestListTicketPurchases_Pagination(t *testing.T) {
+	purchaseService, mock := setupPurchaseTest(t)
+
+	first := &models.Purchase{ID: 1, TicketID: 5, Quantity: 1}
+	second := &models.Purchase{ID: 2, TicketID: 5, Quantity: 2}
+	third := &models.Purchase{ID: 3, TicketID: 5, Quantity: 3}
+
+	mock.ExpectQuery("SELECT id FROM ticket").
+		WithArgs(5, testOrganizerID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(5))
+
+	mock.ExpectQuery("SELECT (.+) FROM purchase WHERE ticket_id = ").
+		WithArgs(5, 0, 3).
+		WillReturnRows(newPurchaseRows(first, second, third))
+
+	list, err := purchaseService.ListTicketPurchases(testOrganizerID, 5, "", 2)
+	assert.NoError(t, err, "failed to list purchases")
+	assert.Equal(t, []models.Purchase{*first, *second}, list.Purchases, "expected first page of purchases")
+	assert.NotEmpty(t, list.NextCursor, "expected next cursor")
+
+	mock.ExpectQuery("SELECT id FROM ticket").
+		WithArgs(5, testOrganizerID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(5))
+
+	mock.ExpectQuery("SELECT (.+) FROM purchase WHERE ticket_id = ").
+		WithArgs(5, second.ID, 3).
+		WillReturnRows(newPurchaseRows(third))
+
+	list, err = purchaseService.ListTicketPurchases(testOrganizerID, 5, list.NextCursor, 2)
+	assert.NoError(t, err, "failed to list purchases")
+	assert.Equal(t, []models.Purchase{*third}, list.Purchases, "expected second page of purchases")
+	assert.Empty(t, list.NextCursor, "expected no next cursor on last page")
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
+
+func TestListTicketPurchases_TicketNotFound(t *testing.T) {
+	purchaseService, mock := setupPurchaseTest(t)
+
+	mock.ExpectQuery("SELECT id FROM ticket").
+		WithArgs(1, testOrganizerID).
+		WillReturnError(sql.ErrNoRows)
+
+	_, err := purchaseService.ListTicketPurchases(testOrganizerID, 1, "", 0)
+	assert.Error(t, err, "expected error when ticket not found")
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
+
+func TestListTicketPurchases_InvalidCursor(t *testing.T) {
+	purchaseService, _ := setupPurchaseTest(t)
+
+	_, err := purchaseService.ListTicketPurchases(testOrganizerID, 1, "not a cursor", 0)
+	assert.Error(t, err, "expected error when cursor is invalid")
+}
+
+func TestRefundPurchase_Partial(t *testing.T) {
+	purchaseService, mock := setupPurchaseTest(t)
+
+	purchase := &models.Purchase{
+		ID: 1, TicketID: 2, BuyerID: "buyer", Quantity: 5, RefundedQuantity: 1,
+		UnitPrice: models.NewMoney(1200, "EUR"), Total: models.NewMoney(6000, "EUR"),
+	}
+
+	mock.ExpectBegin()
+
+	mock.ExpectQuery("SELECT (.+) FROM purchase WHERE id = (.+) FOR UPDATE").
+		WithArgs(purchase.ID, testOrganizerID).
+		WillReturnRows(newPurchaseRows(purchase))
+
+	mock.ExpectQuery("SELECT (.+) FROM ticket WHERE id = (.+) FOR UPDATE").
+		WithArgs(purchase.TicketID, testOrganizerID).
+		WillReturnRows(newTicketRows(&models.Ticket{ID: 2, Name: "test", Allocation: 10, Sold: 4}))
+
+	mock.ExpectExec("UPDATE ticket_instance SET status").
+		WithArgs(models.TicketInstanceStatusVoid, purchase.ID, models.TicketInstanceStatusValid, 2).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	mock.ExpectExec("UPDATE ticket SET allocation").
+		WithArgs(12, 0, 2, purchase.TicketID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	expectEmptyWaitlist(mock, purchase.TicketID)
+
+	mock.ExpectExec("UPDATE purchase SET refunded_quantity").
+		WithArgs(2, purchase.ID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	mock.ExpectQuery("INSERT INTO purchase_refund").
+		WithArgs(purchase.ID, 2, int64(2400), "EUR", "duplicate order", "support").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(3, time.Now()))
+
+	mock.ExpectCommit()
+
+	refund, err := purchaseService.RefundPurchase(testOrganizerID, purchase.ID, models.RefundRequest{
+		Quantity:   2,
+		Reason:     "duplicate order",
+		RefundedBy: "support",
+	})
+	assert.NoError(t, err, "failed to refund purchase")
+	assert.Equal(t, 3, refund.ID, "expected refund ID 3")
+	assert.Equal(t, 2, refund.Quantity, "expected refunded quantity to match")
+	assert.Equal(t, models.NewMoney(2400, "EUR"), refund.Amount, "expected refund amount at the purchase price")
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
+
+func TestRefundPurchase_CheckedInTickets(t *testing.T) {
+	purchaseService, mock := setupPurchaseTest(t)
+
+	purchase := &models.Purchase{
+		ID: 1, TicketID: 2, BuyerID: "buyer", Quantity: 2,
+		UnitPrice: models.NewMoney(1200, "EUR"), Total: models.NewMoney(2400, "EUR"),
+	}
+
+	mock.ExpectBegin()
+
+	mock.ExpectQuery("SELECT (.+) FROM purchase WHERE id = (.+) FOR UPDATE").
+		WithArgs(purchase.ID, testOrganizerID).
+		WillReturnRows(newPurchaseRows(purchase))
+
+	mock.ExpectQuery("SELECT (.+) FROM ticket WHERE id = (.+) FOR UPDATE").
+		WithArgs(purchase.TicketID, testOrganizerID).
+		WillReturnRows(newTicketRows(&models.Ticket{ID: 2, Name: "test", Allocation: 10, Sold: 2}))
+
+	// One of the two tickets was checked in, only the unused one is voided.
+	mock.ExpectExec("UPDATE ticket_instance SET status (.+) AND NOT EXISTS \\(SELECT 1 FROM checkin").
+		WithArgs(models.TicketInstanceStatusVoid, purchase.ID, models.TicketInstanceStatusValid, 2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	mock.ExpectRollback()
+
+	_, err := purchaseService.RefundPurchase(testOrganizerID, purchase.ID, models.RefundRequest{
+		Quantity:   2,
+		Reason:     "duplicate order",
+		RefundedBy: "support",
+	})
+	restErr, ok := err.(errors.RestError)
+	assert.True(t, ok, "expected a RestError")
+	assert.Equal(t, errors.CodeAlreadyCheckedIn, restErr.Code, "expected the checked in ticket not to be refunded")
+	assert.Equal(t, 1, restErr.Details["refundable"])
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
+
+func TestRefundPurchase_CancelsRemaining(t *testing.T) {
+	purchaseService, mock := setupPurchaseTest(t)
+
+	// 1001 after a discount, the cent that doesn't split evenly goes to the last refund
+	purchase := &models.Purchase{
+		ID: 1, TicketID: 2, BuyerID: "buyer", Quantity: 5, RefundedQuantity: 2,
+		UnitPrice: models.NewMoney(300, "EUR"), Discount: models.NewMoney(499, "EUR"), Total: models.NewMoney(1001, "EUR"),
+	}
+
+	mock.ExpectBegin()
+
+	mock.ExpectQuery("SELECT (.+) FROM purchase WHERE id = (.+) FOR UPDATE").
+		WithArgs(purchase.ID, testOrganizerID).
+		WillReturnRows(newPurchaseRows(purchase))
+
+	mock.ExpectQuery("SELECT (.+) FROM ticket WHERE id = (.+) FOR UPDATE").
+		WithArgs(purchase.TicketID, testOrganizerID).
+		WillReturnRows(newTicketRows(&models.Ticket{ID: 2, Name: "test", Allocation: 10, Sold: 3}))
+
+	mock.ExpectExec("UPDATE ticket_instance SET status").
+		WithArgs(models.TicketInstanceStatusVoid, purchase.ID, models.TicketInstanceStatusValid, 3).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	mock.ExpectExec("UPDATE ticket SET allocation").
+		WithArgs(13, 0, 0, purchase.TicketID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	expectEmptyWaitlist(mock, purchase.TicketID)
+
+	mock.ExpectExec("UPDATE purchase SET refunded_quantity").
+		WithArgs(3, purchase.ID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	mock.ExpectQuery("INSERT INTO purchase_refund").
+		WithArgs(purchase.ID, 3, int64(601), "EUR", "event cancelled", "admin").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(4, time.Now()))
+
+	mock.ExpectCommit()
+
+	refund, err := purchaseService.RefundPurchase(testOrganizerID, purchase.ID, models.RefundRequest{
+		Reason:     "event cancelled",
+		RefundedBy: "admin",
+	})
+	assert.NoError(t, err, "failed to cancel purchase")
+	assert.Equal(t, 3, refund.Quantity, "expected every remaining ticket to be refunded")
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
+
+func TestRefundPurchase_MoreThanRefundable(t *testing.T) {
+	purchaseService, mock := setupPurchaseTest(t)
+
+	purchase := &models.Purchase{ID: 1, TicketID: 2, BuyerID: "buyer", Quantity: 5, RefundedQuantity: 4}
+
+	mock.ExpectBegin()
+
+	mock.ExpectQuery("SELECT (.+) FROM purchase WHERE id = (.+) FOR UPDATE").
+		WithArgs(purchase.ID, testOrganizerID).
+		WillReturnRows(newPurchaseRows(purchase))
+
+	mock.ExpectRollback()
+
+	_, err := purchaseService.RefundPurchase(testOrganizerID, purchase.ID, models.RefundRequest{Quantity: 2, Reason: "again", RefundedBy: "support"})
+	assert.Error(t, err, "expected error when refunding more than is left")
+	assert.Equal(t, "Only 1 tickets of the purchase can be refunded", err.Error(), "expected error message to match")
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
+
+func TestRefundPurchase_AlreadyRefunded(t *testing.T) {
+	purchaseService, mock := setupPurchaseTest(t)
+
+	purchase := &models.Purchase{ID: 1, TicketID: 2, BuyerID: "buyer", Quantity: 5, RefundedQuantity: 5}
+
+	mock.ExpectBegin()
+
+	mock.ExpectQuery("SELECT (.+) FROM purchase WHERE id = (.+) FOR UPDATE").
+		WithArgs(purchase.ID, testOrganizerID).
+		WillReturnRows(newPurchaseRows(purchase))
+
+	mock.ExpectRollback()
+
+	_, err := purchaseService.RefundPurchase(testOrganizerID, purchase.ID, models.RefundRequest{Reason: "again", RefundedBy: "support"})
+	assert.Error(t, err, "expected error when purchase is already refunded")
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
+
+func TestRefundPurchase_MissingReason(t *testing.T) {
+	purchaseService, _ := setupPurchaseTest(t)
+
+	_, err := purchaseService.RefundPurchase(testOrganizerID, 1, models.RefundRequest{Quantity: 1, RefundedBy: "support"})
+	assert.Error(t, err, "expected error when reason is missing")
+}
+
+func TestRefundPurchase_OtherOrganizer(t *testing.T) {
+	purchaseService, mock := setupPurchaseTest(t)
+
+	mock.ExpectBegin()
+
+	// Purchases of another organizer's tickets are filtered out by the lookup.
+	mock.ExpectQuery("SELECT (.+) FROM purchase WHERE id = (.+) AND ticket_id IN \\(SELECT id FROM ticket WHERE organizer_id = (.+)\\) FOR UPDATE").
+		WithArgs(1, testOrganizerID).
+		WillReturnRows(newPurchaseRows())
+
+	mock.ExpectRollback()
+
+	_, err := purchaseService.RefundPurchase(testOrganizerID, 1, models.RefundRequest{Reason: "again", RefundedBy: "support"})
+	assert.Error(t, err, "expected error for a purchase of another organizer")
+	assert.Equal(t, "Purchase 1 not found", err.Error(), "expected the purchase to be reported as not found")
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
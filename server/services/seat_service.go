@@ -0,0 +1,391 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"gowitcase/db"
+	"gowitcase/errors"
+	"gowitcase/models"
+	"log"
+
+	"github.com/lib/pq"
+)
+
+type SeatService struct {
+	DB    db.DatabaseInterface
+	Cache db.RedisInterface
+}
+
+func NewSeatService(db db.DatabaseInterface, cache db.RedisInterface) *SeatService {
+	return &SeatService{DB: db, Cache: cache}
+}
+
+// SetSeatMap lays out the seats of a ticket, replacing any previous seat map, and
+// makes the ticket seated. Its allocation becomes the number of seats. The seat map
+// can only be replaced before any ticket is sold or held, or issued for a seat.
+func (s *SeatService) SetSeatMap(organizerID int, ticketID int, seatMap models.SeatMap) (*models.SeatMap, error) {
+	sections, rows, numbers, err := flattenSeatMap(seatMap)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.DB.BeginTransaction()
+	if err != nil {
+		log.Printf("Failed to start transaction: %v", err)
+		return nil, fmt.Errorf("failed to start transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	ticket, err := lockOrganizerTicket(tx, organizerID, ticketID)
+	if err != nil {
+		return nil, err
+	}
+
+	if ticket.ArchivedAt != nil {
+		return nil, errors.NewRestError(fmt.Sprintf("Ticket %d is archived", ticketID), 400).WithCode(errors.CodeTicketArchived)
+	}
+
+	if ticket.Sold+ticket.Held > 0 {
+		return nil, errors.NewRestError("Seat map can't be replaced once tickets are sold or held", 400)
+	}
+
+	// The void instances of refunded tickets still name their seats, which can't be
+	// deleted from under them.
+	var issued bool
+	err = tx.QueryRow(
+		"SELECT EXISTS (SELECT 1 FROM ticket_instance WHERE ticket_id = $1 AND seat_id IS NOT NULL)",
+		ticketID,
+	).Scan(&issued)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check issued seats: %v", err)
+	}
+	if issued {
+		return nil, errors.NewRestError("Seat map can't be replaced once tickets were issued for its seats", 409).
+			WithCode(errors.CodeSeatMapInUse)
+	}
+
+	_, err = tx.Exec("DELETE FROM seat WHERE ticket_id = $1", ticketID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete seats: %v", err)
+	}
+
+	_, err = tx.Exec(
+		"INSERT INTO seat (ticket_id, section, row_label, number) SELECT $1, * FROM unnest($2::text[], $3::text[], $4::text[])",
+		ticketID, pq.Array(sections), pq.Array(rows), pq.Array(numbers),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create seats: %v", err)
+	}
+
+	_, err = tx.Exec(
+		"UPDATE ticket SET allocation = $1, seated = TRUE, updated_at = CURRENT_TIMESTAMP WHERE id = $2",
+		len(numbers), ticketID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update ticket: %v", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	err = invalidateTicketCache(s.Cache, ticket.OrganizerID, ticketID)
+	if err != nil {
+		log.Printf("Failed to invalidate cache: %v for ticket: %d", err, ticketID)
+	}
+
+	return s.loadSeatMap(ticketID)
+}
+
+// GetSeatMap returns the seat map of a seated ticket with the live status of every
+// seat. It isn't cached, seats change with every purchase.
+func (s *SeatService) GetSeatMap(organizerID int, ticketID int) (*models.SeatMap, error) {
+	var seated bool
+	err := s.DB.QueryRow("SELECT seated FROM ticket WHERE id = $1 AND organizer_id = $2", ticketID, organizerID).Scan(&seated)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NewRestError(fmt.Sprintf("Ticket %d not found", ticketID), 404)
+		}
+		return nil, fmt.Errorf("failed to get ticket: %v", err)
+	}
+
+	if !seated {
+		return nil, errors.NewRestError(fmt.Sprintf("Ticket %d has no assigned seating", ticketID), 400)
+	}
+
+	return s.loadSeatMap(ticketID)
+}
+
+// loadSeatMap groups the seats of the ticket back into sections and rows, in the
+// order they were laid out.
+func (s *SeatService) loadSeatMap(ticketID int) (*models.SeatMap, error) {
+	rows, err := s.DB.Query(
+		"SELECT id, section, row_label, number, status FROM seat WHERE ticket_id = $1 ORDER BY id",
+		ticketID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get seats: %v", err)
+	}
+	defer rows.Close()
+
+	seatMap := &models.SeatMap{TicketID: ticketID, Sections: []models.SeatSection{}}
+	for rows.Next() {
+		var section, row string
+		seat := models.Seat{}
+		err := rows.Scan(&seat.ID, &section, &row, &seat.Number, &seat.Status)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan seat: %v", err)
+		}
+
+		if seat.Status == models.SeatStatusAvailable {
+			seatMap.Available++
+		}
+
+		sections := seatMap.Sections
+		if len(sections) == 0 || sections[len(sections)-1].Name != section {
+			seatMap.Sections = append(seatMap.Sections, models.SeatSection{Name: section})
+		}
+		current := &seatMap.Sections[len(seatMap.Sections)-1]
+
+		if len(current.Rows) == 0 || current.Rows[len(current.Rows)-1].Name != row {
+			current.Rows = append(current.Rows, models.SeatRow{Name: row})
+		}
+		currentRow := &current.Rows[len(current.Rows)-1]
+		currentRow.Seats = append(currentRow.Seats, seat)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get seats: %v", err)
+	}
+
+	return seatMap, nil
+}
+
+type seatRef struct {
+	Section string
+	Row     string
+	Number  string
+}
+
+// flattenSeatMap validates the seat map and returns the section, row and number of
+// every seat, in layout order.
+func flattenSeatMap(seatMap models.SeatMap) ([]string, []string, []string, error) {
+	var sections, rows, numbers []string
+	seen := make(map[seatRef]bool)
+
+	for _, section := range seatMap.Sections {
+		if section.Name == "" {
+			return nil, nil, nil, errors.NewRestError("Section name is required", 400)
+		}
+		if len(section.Name) > 255 {
+			return nil, nil, nil, errors.NewRestError("Section name must be less than 255 characters", 400)
+		}
+
+		for _, row := range section.Rows {
+			if row.Name == "" {
+				return nil, nil, nil, errors.NewRestError(fmt.Sprintf("Row name is required in section %s", section.Name), 400)
+			}
+			if len(row.Name) > 255 {
+				return nil, nil, nil, errors.NewRestError("Row name must be less than 255 characters", 400)
+			}
+
+			for _, seat := range row.Seats {
+				if seat.Number == "" {
+					return nil, nil, nil, errors.NewRestError(
+						fmt.Sprintf("Seat number is required in row %s of section %s", row.Name, section.Name), 400,
+					)
+				}
+				if len(seat.Number) > 255 {
+					return nil, nil, nil, errors.NewRestError("Seat number must be less than 255 characters", 400)
+				}
+				ref := seatRef{Section: section.Name, Row: row.Name, Number: seat.Number}
+				if seen[ref] {
+					return nil, nil, nil, errors.NewRestError(
+						fmt.Sprintf("Seat %s is listed more than once in row %s of section %s", seat.Number, row.Name, section.Name), 400,
+					)
+				}
+				seen[ref] = true
+
+				sections = append(sections, section.Name)
+				rows = append(rows, row.Name)
+				numbers = append(numbers, seat.Number)
+			}
+		}
+	}
+
+	if len(numbers) == 0 {
+		return nil, nil, nil, errors.NewRestError("Seat map must have at least one seat", 400)
+	}
+
+	if len(numbers) > models.SeatMapMaxSeats {
+		return nil, nil, nil, errors.NewRestError(fmt.Sprintf("Seat map can't have more than %d seats", models.SeatMapMaxSeats), 400)
+	}
+
+	return sections, rows, numbers, nil
+}
+
+// seatQuantity returns the number of tickets a request for seatIDs stands for. A
+// quantity sent along with the seats has to match them, and a seat can only be
+// listed once.
+func seatQuantity(quantity int, seatIDs []int, fields *errors.FieldErrors) int {
+	if len(seatIDs) == 0 {
+		return quantity
+	}
+
+	if quantity != 0 && quantity != len(seatIDs) {
+		fields.Add("quantity", "Field 'quantity' must match the number of seats")
+	}
+
+	seen := make(map[int]bool, len(seatIDs))
+	for i, id := range seatIDs {
+		if seen[id] {
+			fields.Add(fmt.Sprintf("seat_ids[%d]", i), fmt.Sprintf("Seat %d is listed more than once", id))
+		}
+		seen[id] = true
+	}
+
+	return len(seatIDs)
+}
+
+// lockSeats checks that seatIDs pick seats the way the locked ticket is sold and
+// locks them. Every seat has to be an available seat of the ticket.
+func lockSeats(tx *sql.Tx, ticket *models.Ticket, seatIDs []int) error {
+	if !ticket.Seated {
+		if len(seatIDs) > 0 {
+			return errors.NewRestError(fmt.Sprintf("Ticket %d has no assigned seating", ticket.ID), 400)
+		}
+		return nil
+	}
+
+	if len(seatIDs) == 0 {
+		return errors.NewFieldError("seat_ids", "Field 'seat_ids' is required for seated tickets")
+	}
+
+	rows, err := tx.Query(
+		"SELECT id, status FROM seat WHERE ticket_id = $1 AND id = ANY($2) ORDER BY id FOR UPDATE",
+		ticket.ID, pq.Array(seatIDs),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to get seats: %v", err)
+	}
+	defer rows.Close()
+
+	statuses := make(map[int]string, len(seatIDs))
+	for rows.Next() {
+		var id int
+		var status string
+		err := rows.Scan(&id, &status)
+		if err != nil {
+			return fmt.Errorf("failed to scan seat: %v", err)
+		}
+		statuses[id] = status
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to get seats: %v", err)
+	}
+
+	for _, id := range seatIDs {
+		status, ok := statuses[id]
+		if !ok {
+			return errors.NewRestError(fmt.Sprintf("Seat %d is not a seat of ticket %d", id, ticket.ID), 400)
+		}
+		if status != models.SeatStatusAvailable {
+			return errors.NewRestError(fmt.Sprintf("Seat %d is already %s", id, status), 400).WithCode(errors.CodeSeatUnavailable).WithDetail("seat_id", id)
+		}
+	}
+
+	return nil
+}
+
+// refundSeats puts the refunded seats of a purchase back on sale and returns them.
+// Without seatIDs only a refund of every seat left on the purchase is allowed.
+func refundSeats(tx *sql.Tx, ticket *models.Ticket, purchaseID int, seatIDs []int, quantity int) ([]int, error) {
+	if !ticket.Seated {
+		if len(seatIDs) > 0 {
+			return nil, errors.NewRestError(fmt.Sprintf("Ticket %d has no assigned seating", ticket.ID), 400)
+		}
+		return nil, nil
+	}
+
+	rows, err := tx.Query(
+		"SELECT id FROM seat WHERE purchase_id = $1 AND status = $2 ORDER BY id FOR UPDATE",
+		purchaseID, models.SeatStatusSold,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get seats: %v", err)
+	}
+	defer rows.Close()
+
+	sold := make(map[int]bool)
+	var soldIDs []int
+	for rows.Next() {
+		var id int
+		err := rows.Scan(&id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan seat: %v", err)
+		}
+		sold[id] = true
+		soldIDs = append(soldIDs, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get seats: %v", err)
+	}
+
+	if len(seatIDs) == 0 {
+		if quantity != len(soldIDs) {
+			return nil, errors.NewFieldError("seat_ids", "Field 'seat_ids' is required to refund part of a seated purchase")
+		}
+		seatIDs = soldIDs
+	}
+
+	for _, id := range seatIDs {
+		if !sold[id] {
+			return nil, errors.NewRestError(fmt.Sprintf("Seat %d is not part of purchase %d", id, purchaseID), 400)
+		}
+	}
+
+	err = assignSeats(tx, seatIDs, models.SeatStatusAvailable, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return seatIDs, nil
+}
+
+// assignSeats moves the seats to status, held by holdID or sold with purchaseID.
+// Both are nil for seats going back on sale.
+func assignSeats(tx *sql.Tx, seatIDs []int, status string, holdID, purchaseID *int) error {
+	_, err := tx.Exec(
+		"UPDATE seat SET status = $1, hold_id = $2, purchase_id = $3 WHERE id = ANY($4)",
+		status, holdID, purchaseID, pq.Array(seatIDs),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update seats: %v", err)
+	}
+	return nil
+}
+
+// seatIDScanner scans an INT[] of seat IDs, pq only scans arrays of int64.
+type seatIDScanner struct {
+	ids *[]int
+}
+
+func scanSeatIDs(ids *[]int) seatIDScanner {
+	return seatIDScanner{ids: ids}
+}
+
+func (s seatIDScanner) Scan(src interface{}) error {
+	var values pq.Int64Array
+	err := values.Scan(src)
+	if err != nil {
+		return err
+	}
+
+	*s.ids = nil
+	for _, value := range values {
+		*s.ids = append(*s.ids, int(value))
+	}
+	return nil
+}
@@ -0,0 +1,319 @@
+package services_test
+
+import (
+	"database/sql/driver"
+	"gowitcase/errors"
+	"gowitcase/mocks"
+	"gowitcase/models"
+	"gowitcase/services"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupSeatTest(t *testing.T) (*services.SeatService, sqlmock.Sqlmock) {
+	mockDB, mock, err := mocks.NewMockDatabase()
+	assert.NoError(t, err)
+
+	seatService := services.NewSeatService(mockDB, mocks.NewMockRedis())
+
+	return seatService, mock
+}
+
+// intArrayValue is the column value of an INT[] holding ids, NULL when there are none.
+func intArrayValue(ids []int) driver.Value {
+	value, _ := pq.Array(ids).Value()
+	return value
+}
+
+func newSeatRows() *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"id", "section", "row_label", "number", "status"})
+}
+
+func expectNoIssuedSeats(mock sqlmock.Sqlmock, ticketID int) {
+	mock.ExpectQuery("SELECT EXISTS (.+) FROM ticket_instance WHERE ticket_id = (.+) AND seat_id IS NOT NULL").
+		WithArgs(ticketID).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+}
+
+func TestSetSeatMap_Success(t *testing.T) {
+	seatService, mock := setupSeatTest(t)
+
+	mock.ExpectBegin()
+
+	mock.ExpectQuery("SELECT (.+) FROM ticket WHERE id = (.+) FOR UPDATE").
+		WithArgs(1, testOrganizerID).
+		WillReturnRows(newTicketRows(&models.Ticket{ID: 1, Name: "test", Allocation: 100}))
+
+	expectNoIssuedSeats(mock, 1)
+
+	mock.ExpectExec("DELETE FROM seat").
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	mock.ExpectExec("INSERT INTO seat").
+		WithArgs(1, `{"Stalls","Stalls","Balcony"}`, `{"A","A","A"}`, `{"1","2","1"}`).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	mock.ExpectExec("UPDATE ticket SET allocation").
+		WithArgs(3, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	mock.ExpectCommit()
+
+	mock.ExpectQuery("SELECT id, section, row_label, number, status FROM seat").
+		WithArgs(1).
+		WillReturnRows(newSeatRows().
+			AddRow(10, "Stalls", "A", "1", models.SeatStatusAvailable).
+			AddRow(11, "Stalls", "A", "2", models.SeatStatusAvailable).
+			AddRow(12, "Balcony", "A", "1", models.SeatStatusAvailable))
+
+	seatMap, err := seatService.SetSeatMap(testOrganizerID, 1, models.SeatMap{Sections: []models.SeatSection{
+		{Name: "Stalls", Rows: []models.SeatRow{{Name: "A", Seats: []models.Seat{{Number: "1"}, {Number: "2"}}}}},
+		{Name: "Balcony", Rows: []models.SeatRow{{Name: "A", Seats: []models.Seat{{Number: "1"}}}}},
+	}})
+	assert.NoError(t, err, "failed to set seat map")
+	assert.Equal(t, 3, seatMap.Available, "expected every seat to be available")
+	assert.Len(t, seatMap.Sections, 2, "expected two sections")
+	assert.Equal(t, 11, seatMap.Sections[0].Rows[0].Seats[1].ID, "expected seat IDs in layout order")
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
+
+func TestSetSeatMap_AfterRefunds(t *testing.T) {
+	seatService, mock := setupSeatTest(t)
+
+	mock.ExpectBegin()
+
+	// Every ticket was refunded, but the void instances still name their seats.
+	mock.ExpectQuery("SELECT (.+) FROM ticket WHERE id = (.+) FOR UPDATE").
+		WithArgs(1, testOrganizerID).
+		WillReturnRows(newTicketRows(&models.Ticket{ID: 1, Name: "test", Allocation: 100, Seated: true}))
+
+	mock.ExpectQuery("SELECT EXISTS (.+) FROM ticket_instance").
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+	mock.ExpectRollback()
+
+	_, err := seatService.SetSeatMap(testOrganizerID, 1, models.SeatMap{Sections: []models.SeatSection{
+		{Name: "Stalls", Rows: []models.SeatRow{{Name: "A", Seats: []models.Seat{{Number: "1"}}}}},
+	}})
+	restErr, ok := err.(errors.RestError)
+	assert.True(t, ok, "expected a RestError")
+	assert.Equal(t, 409, restErr.Status)
+	assert.Equal(t, errors.CodeSeatMapInUse, restErr.Code)
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "expected the seats to be kept")
+}
+
+func TestSetSeatMap_AfterSales(t *testing.T) {
+	seatService, mock := setupSeatTest(t)
+
+	mock.ExpectBegin()
+
+	mock.ExpectQuery("SELECT (.+) FROM ticket WHERE id = (.+) FOR UPDATE").
+		WithArgs(1, testOrganizerID).
+		WillReturnRows(newTicketRows(&models.Ticket{ID: 1, Name: "test", Allocation: 99, Sold: 1}))
+
+	mock.ExpectRollback()
+
+	_, err := seatService.SetSeatMap(testOrganizerID, 1, models.SeatMap{Sections: []models.SeatSection{
+		{Name: "Stalls", Rows: []models.SeatRow{{Name: "A", Seats: []models.Seat{{Number: "1"}}}}},
+	}})
+	assert.Equal(t, errors.NewRestError("Seat map can't be replaced once tickets are sold or held", 400), err, "expected bad request error")
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
+
+func TestSetSeatMap_DuplicateSeat(t *testing.T) {
+	seatService, _ := setupSeatTest(t)
+
+	_, err := seatService.SetSeatMap(testOrganizerID, 1, models.SeatMap{Sections: []models.SeatSection{
+		{Name: "Stalls", Rows: []models.SeatRow{{Name: "A", Seats: []models.Seat{{Number: "1"}, {Number: "1"}}}}},
+	}})
+	assert.Equal(t, errors.NewRestError("Seat 1 is listed more than once in row A of section Stalls", 400), err, "expected bad request error")
+}
+
+func TestGetSeatMap_NotSeated(t *testing.T) {
+	seatService, mock := setupSeatTest(t)
+
+	mock.ExpectQuery("SELECT seated FROM ticket").
+		WithArgs(1, testOrganizerID).
+		WillReturnRows(sqlmock.NewRows([]string{"seated"}).AddRow(false))
+
+	_, err := seatService.GetSeatMap(testOrganizerID, 1)
+	assert.Equal(t, errors.NewRestError("Ticket 1 has no assigned seating", 400), err, "expected bad request error")
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
+
+func TestPurchaseTicket_Seats(t *testing.T) {
+	ticketService, mock := setupTest(t)
+
+	mock.ExpectBegin()
+
+	mock.ExpectQuery("SELECT (.+) FROM ticket WHERE id = (.+) FOR UPDATE").
+		WithArgs(1, testOrganizerID).
+		WillReturnRows(newTicketRows(&models.Ticket{ID: 1, Name: "test", Allocation: 3, Seated: true}))
+
+	mock.ExpectQuery("SELECT id, status FROM seat").
+		WithArgs(1, "{11,12}").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "status"}).
+			AddRow(11, models.SeatStatusAvailable).
+			AddRow(12, models.SeatStatusAvailable))
+
+	mock.ExpectExec("UPDATE ticket SET allocation").
+		WithArgs(1, 0, 2, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	mock.ExpectQuery("INSERT INTO purchase").
+		WithArgs(1, "buyer", 2, int64(0), int64(0), int64(0), "", nil, "{11,12}", nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(7, time.Now()))
+
+	mock.ExpectExec("INSERT INTO ticket_instance").
+		WithArgs(1, 7, "{11,12}", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	mock.ExpectExec("UPDATE seat SET status").
+		WithArgs(models.SeatStatusSold, nil, 7, "{11,12}").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	mock.ExpectCommit()
+
+	purchase, err := ticketService.PurchaseTicket(testOrganizerID, 1, models.PurchaseRequest{BuyerID: "buyer", SeatIDs: []int{11, 12}})
+	assert.NoError(t, err, "failed to purchase seats")
+	assert.Equal(t, 2, purchase.Quantity, "expected quantity from the seats")
+	assert.Equal(t, []int{11, 12}, purchase.SeatIDs, "expected purchased seats")
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
+
+func TestPurchaseTicket_SeatTaken(t *testing.T) {
+	ticketService, mock := setupTest(t)
+
+	mock.ExpectBegin()
+
+	mock.ExpectQuery("SELECT (.+) FROM ticket WHERE id = (.+) FOR UPDATE").
+		WithArgs(1, testOrganizerID).
+		WillReturnRows(newTicketRows(&models.Ticket{ID: 1, Name: "test", Allocation: 2, Sold: 1, Seated: true}))
+
+	mock.ExpectQuery("SELECT id, status FROM seat").
+		WithArgs(1, "{11}").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "status"}).AddRow(11, models.SeatStatusSold))
+
+	mock.ExpectRollback()
+
+	_, err := ticketService.PurchaseTicket(testOrganizerID, 1, models.PurchaseRequest{BuyerID: "buyer", SeatIDs: []int{11}})
+	assert.Equal(t, errors.NewRestError("Seat 11 is already sold", 400).WithCode(errors.CodeSeatUnavailable).WithDetail("seat_id", 11), err, "expected bad request error")
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
+
+func TestPurchaseTicket_SeatsRequired(t *testing.T) {
+	ticketService, mock := setupTest(t)
+
+	mock.ExpectBegin()
+
+	mock.ExpectQuery("SELECT (.+) FROM ticket WHERE id = (.+) FOR UPDATE").
+		WithArgs(1, testOrganizerID).
+		WillReturnRows(newTicketRows(&models.Ticket{ID: 1, Name: "test", Allocation: 3, Seated: true}))
+
+	mock.ExpectRollback()
+
+	_, err := ticketService.PurchaseTicket(testOrganizerID, 1, models.PurchaseRequest{BuyerID: "buyer", Quantity: 2})
+	assert.Equal(t, errors.NewFieldError("seat_ids", "Field 'seat_ids' is required for seated tickets"), err, "expected bad request error")
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
+
+func TestRefundPurchase_Seats(t *testing.T) {
+	purchaseService, mock := setupPurchaseTest(t)
+
+	purchase := &models.Purchase{ID: 7, TicketID: 1, BuyerID: "buyer", Quantity: 2, SeatIDs: []int{11, 12}}
+
+	mock.ExpectBegin()
+
+	mock.ExpectQuery("SELECT (.+) FROM purchase WHERE id = (.+) FOR UPDATE").
+		WithArgs(purchase.ID, testOrganizerID).
+		WillReturnRows(newPurchaseRows(purchase))
+
+	mock.ExpectQuery("SELECT (.+) FROM ticket WHERE id = (.+) FOR UPDATE").
+		WithArgs(1, testOrganizerID).
+		WillReturnRows(newTicketRows(&models.Ticket{ID: 1, Name: "test", Allocation: 1, Sold: 2, Seated: true}))
+
+	mock.ExpectQuery("SELECT id FROM seat").
+		WithArgs(purchase.ID, models.SeatStatusSold).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(11).AddRow(12))
+
+	mock.ExpectExec("UPDATE seat SET status").
+		WithArgs(models.SeatStatusAvailable, nil, nil, "{12}").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	mock.ExpectExec("UPDATE ticket_instance SET status").
+		WithArgs(models.TicketInstanceStatusVoid, purchase.ID, models.TicketInstanceStatusValid, "{12}").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	mock.ExpectExec("UPDATE ticket SET allocation").
+		WithArgs(2, 0, 1, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	mock.ExpectExec("UPDATE purchase SET refunded_quantity").
+		WithArgs(1, purchase.ID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	mock.ExpectQuery("INSERT INTO purchase_refund").
+		WithArgs(purchase.ID, 1, int64(0), "", "seat swap", "support").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(3, time.Now()))
+
+	mock.ExpectCommit()
+
+	refund, err := purchaseService.RefundPurchase(testOrganizerID, purchase.ID, models.RefundRequest{
+		SeatIDs:    []int{12},
+		Reason:     "seat swap",
+		RefundedBy: "support",
+	})
+	assert.NoError(t, err, "failed to refund seat")
+	assert.Equal(t, 1, refund.Quantity, "expected quantity from the seats")
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
+
+func TestRefundPurchase_PartialSeatsRequired(t *testing.T) {
+	purchaseService, mock := setupPurchaseTest(t)
+
+	purchase := &models.Purchase{ID: 7, TicketID: 1, BuyerID: "buyer", Quantity: 2, SeatIDs: []int{11, 12}}
+
+	mock.ExpectBegin()
+
+	mock.ExpectQuery("SELECT (.+) FROM purchase WHERE id = (.+) FOR UPDATE").
+		WithArgs(purchase.ID, testOrganizerID).
+		WillReturnRows(newPurchaseRows(purchase))
+
+	mock.ExpectQuery("SELECT (.+) FROM ticket WHERE id = (.+) FOR UPDATE").
+		WithArgs(1, testOrganizerID).
+		WillReturnRows(newTicketRows(&models.Ticket{ID: 1, Name: "test", Allocation: 1, Sold: 2, Seated: true}))
+
+	mock.ExpectQuery("SELECT id FROM seat").
+		WithArgs(purchase.ID, models.SeatStatusSold).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(11).AddRow(12))
+
+	mock.ExpectRollback()
+
+	_, err := purchaseService.RefundPurchase(testOrganizerID, purchase.ID, models.RefundRequest{Quantity: 1, Reason: "seat swap", RefundedBy: "support"})
+	assert.Equal(t, errors.NewFieldError("seat_ids", "Field 'seat_ids' is required to refund part of a seated purchase"), err, "expected bad request error")
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
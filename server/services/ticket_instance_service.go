@@ -0,0 +1,217 @@
+package services
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"fmt"
+	"gowitcase/db"
+	"gowitcase/errors"
+	"gowitcase/models"
+
+	"github.com/lib/pq"
+	"github.com/skip2/go-qrcode"
+)
+
+const ticketInstanceColumns = "id, ticket_id, purchase_id, seat_id, code, status, created_at, voided_at"
+
+// TicketInstanceService serves the ticket instances of an organizer's purchases
+// with their signed tokens. Tokens are signed when they are read, so after a key
+// rotation they are handed out signed with the new key. Only valid instances get a
+// token, and only the organizer of the ticket reads them.
+type TicketInstanceService struct {
+	DB     db.DatabaseInterface
+	Cache  db.RedisInterface
+	Signer *TicketSigner
+}
+
+func NewTicketInstanceService(db db.DatabaseInterface, cache db.RedisInterface, signer *TicketSigner) *TicketInstanceService {
+	return &TicketInstanceService{DB: db, Cache: cache, Signer: signer}
+}
+
+// ListPurchaseInstances returns every ticket instance of a purchase, void ones included.
+func (s *TicketInstanceService) ListPurchaseInstances(organizerID int, purchaseID int) (*models.TicketInstanceList, error) {
+	var id int
+	err := s.DB.QueryRow(
+		"SELECT id FROM purchase WHERE id = $1 AND ticket_id IN (SELECT id FROM ticket WHERE organizer_id = $2)",
+		purchaseID, organizerID,
+	).Scan(&id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NewRestError(fmt.Sprintf("Purchase %d not found", purchaseID), 404)
+		}
+		return nil, fmt.Errorf("failed to get purchase: %v", err)
+	}
+
+	rows, err := s.DB.Query(
+		"SELECT "+ticketInstanceColumns+" FROM ticket_instance WHERE purchase_id = $1 ORDER BY id",
+		purchaseID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ticket instances: %v", err)
+	}
+	defer rows.Close()
+
+	list := &models.TicketInstanceList{TicketInstances: []models.TicketInstance{}}
+	for rows.Next() {
+		instance := models.TicketInstance{}
+		code, err := scanTicketInstance(rows, &instance)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan ticket instance: %v", err)
+		}
+		s.signInstance(&instance, code)
+		list.TicketInstances = append(list.TicketInstances, instance)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list ticket instances: %v", err)
+	}
+
+	return list, nil
+}
+
+func (s *TicketInstanceService) GetInstance(organizerID int, id int) (*models.TicketInstance, error) {
+	instance := &models.TicketInstance{}
+
+	code, err := scanTicketInstance(s.DB.QueryRow(
+		"SELECT "+ticketInstanceColumns+" FROM ticket_instance "+
+			"WHERE id = $1 AND ticket_id IN (SELECT id FROM ticket WHERE organizer_id = $2)",
+		id, organizerID,
+	), instance)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NewRestError(fmt.Sprintf("Ticket instance %d not found", id), 404)
+		}
+		return nil, fmt.Errorf("failed to get ticket instance: %v", err)
+	}
+
+	s.signInstance(instance, code)
+	return instance, nil
+}
+
+// QRCode renders the token of a valid ticket instance as a size by size PNG. A size
+// of 0 stands for the default size.
+func (s *TicketInstanceService) QRCode(organizerID int, id int, size int) ([]byte, error) {
+	if size == 0 {
+		size = models.TicketQRCodeDefaultSize
+	}
+	if size < models.TicketQRCodeMinSize || size > models.TicketQRCodeMaxSize {
+		return nil, errors.NewRestError(
+			fmt.Sprintf("Size must be between %d and %d", models.TicketQRCodeMinSize, models.TicketQRCodeMaxSize), 400,
+		)
+	}
+
+	instance, err := s.GetInstance(organizerID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if instance.Status != models.TicketInstanceStatusValid {
+		return nil, errors.NewRestError(fmt.Sprintf("Ticket instance %d is %s", id, instance.Status), 400).WithCode(errors.CodeTicketNotValid)
+	}
+
+	png, err := qrcode.Encode(instance.Token, qrcode.Medium, size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode QR code: %v", err)
+	}
+
+	return png, nil
+}
+
+// signInstance hands out the token of a valid instance. Void instances can't be
+// checked in, so they don't get one.
+func (s *TicketInstanceService) signInstance(instance *models.TicketInstance, code string) {
+	if instance.Status == models.TicketInstanceStatusValid {
+		instance.Token = s.Signer.Sign(code)
+	}
+}
+
+// scanTicketInstance scans an instance and returns its code, which stays unsigned.
+func scanTicketInstance(row rowScanner, instance *models.TicketInstance) (string, error) {
+	var code string
+	err := row.Scan(
+		&instance.ID, &instance.TicketID, &instance.PurchaseID, &instance.SeatID, &code, &instance.Status,
+		&instance.CreatedAt, &instance.VoidedAt,
+	)
+	return code, err
+}
+
+// issueTicketInstances creates a ticket instance with a fresh code for every ticket
+// of the purchase, one per seat for seated purchases.
+func issueTicketInstances(tx *sql.Tx, purchase *models.Purchase) error {
+	codes := make([]string, purchase.Quantity)
+	for i := range codes {
+		code, err := newTicketCode()
+		if err != nil {
+			return err
+		}
+		codes[i] = code
+	}
+
+	var err error
+	if len(purchase.SeatIDs) > 0 {
+		_, err = tx.Exec(
+			"INSERT INTO ticket_instance (ticket_id, purchase_id, seat_id, code) SELECT $1, $2, * FROM unnest($3::int[], $4::text[])",
+			purchase.TicketID, purchase.ID, pq.Array(purchase.SeatIDs), pq.Array(codes),
+		)
+	} else {
+		_, err = tx.Exec(
+			"INSERT INTO ticket_instance (ticket_id, purchase_id, code) SELECT $1, $2, unnest($3::text[])",
+			purchase.TicketID, purchase.ID, pq.Array(codes),
+		)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create ticket instances: %v", err)
+	}
+	return nil
+}
+
+// voidTicketInstances voids the instances of refunded tickets: those of the refunded
+// seats, or the most recently issued ones of a purchase without seats. Instances
+// that were checked in have been used and are never voided, so the refund fails
+// when fewer than quantity tickets are left unused.
+func voidTicketInstances(tx *sql.Tx, purchaseID int, quantity int, seatIDs []int) error {
+	var result sql.Result
+	var err error
+	if len(seatIDs) > 0 {
+		result, err = tx.Exec(
+			"UPDATE ticket_instance SET status = $1, voided_at = CURRENT_TIMESTAMP "+
+				"WHERE purchase_id = $2 AND status = $3 AND seat_id = ANY($4) "+
+				"AND NOT EXISTS (SELECT 1 FROM checkin WHERE checkin.ticket_instance_id = ticket_instance.id)",
+			models.TicketInstanceStatusVoid, purchaseID, models.TicketInstanceStatusValid, pq.Array(seatIDs),
+		)
+	} else {
+		result, err = tx.Exec(
+			"UPDATE ticket_instance SET status = $1, voided_at = CURRENT_TIMESTAMP WHERE id IN "+
+				"(SELECT id FROM ticket_instance WHERE purchase_id = $2 AND status = $3 "+
+				"AND NOT EXISTS (SELECT 1 FROM checkin WHERE checkin.ticket_instance_id = ticket_instance.id) "+
+				"ORDER BY id DESC LIMIT $4)",
+			models.TicketInstanceStatusVoid, purchaseID, models.TicketInstanceStatusValid, quantity,
+		)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to void ticket instances: %v", err)
+	}
+
+	voided, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to void ticket instances: %v", err)
+	}
+	if int(voided) < quantity {
+		return errors.NewRestError(
+			fmt.Sprintf("Only %d of the tickets to refund aren't checked in yet, checked in tickets can't be refunded", voided), 400,
+		).WithCode(errors.CodeAlreadyCheckedIn).WithDetail("refundable", int(voided))
+	}
+	return nil
+}
+
+// newTicketCode returns a random, URL-safe code that doesn't contain the dots
+// separating the parts of a token.
+func newTicketCode() (string, error) {
+	code := make([]byte, 16)
+	_, err := rand.Read(code)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate ticket code: %v", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(code), nil
+}
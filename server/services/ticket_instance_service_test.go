@@ -0,0 +1,227 @@
+package services_test
+
+import (
+	"bytes"
+	"gowitcase/errors"
+	"gowitcase/mocks"
+	"gowitcase/models"
+	"gowitcase/services"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+var testSigningKey = []byte("0123456789abcdef0123456789abcdef")
+
+func newTestSigner(t *testing.T) *services.TicketSigner {
+	signer, err := services.NewTicketSigner(map[string][]byte{"k1": testSigningKey}, "k1")
+	assert.NoError(t, err)
+	return signer
+}
+
+func setupTicketInstanceTest(t *testing.T) (*services.TicketInstanceService, sqlmock.Sqlmock) {
+	mockDB, mock, err := mocks.NewMockDatabase()
+	assert.NoError(t, err)
+
+	ticketInstanceService := services.NewTicketInstanceService(mockDB, mocks.NewMockRedis(), newTestSigner(t))
+
+	return ticketInstanceService, mock
+}
+
+// expectTicketInstances expects the ticket instances of a purchase without seats to be issued.
+func expectTicketInstances(mock sqlmock.Sqlmock, purchaseID int) {
+	mock.ExpectExec("INSERT INTO ticket_instance").
+		WithArgs(sqlmock.AnyArg(), purchaseID, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+}
+
+func newTicketInstanceRows(instances ...*models.TicketInstance) *sqlmock.Rows {
+	rows := sqlmock.NewRows([]string{
+		"id", "ticket_id", "purchase_id", "seat_id", "code", "status", "created_at", "voided_at",
+	})
+	for _, instance := range instances {
+		rows.AddRow(
+			instance.ID, instance.TicketID, instance.PurchaseID, instance.SeatID, "code", instance.Status,
+			instance.CreatedAt, instance.VoidedAt,
+		)
+	}
+	return rows
+}
+
+func TestListPurchaseInstances_Success(t *testing.T) {
+	ticketInstanceService, mock := setupTicketInstanceTest(t)
+
+	mock.ExpectQuery("SELECT id FROM purchase").
+		WithArgs(7, testOrganizerID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+
+	mock.ExpectQuery("SELECT (.+) FROM ticket_instance WHERE purchase_id = (.+)").
+		WithArgs(7).
+		WillReturnRows(newTicketInstanceRows(
+			&models.TicketInstance{ID: 1, TicketID: 1, PurchaseID: 7, Status: models.TicketInstanceStatusValid, CreatedAt: time.Now()},
+			&models.TicketInstance{ID: 2, TicketID: 1, PurchaseID: 7, Status: models.TicketInstanceStatusValid, CreatedAt: time.Now()},
+		))
+
+	list, err := ticketInstanceService.ListPurchaseInstances(testOrganizerID, 7)
+	assert.NoError(t, err, "failed to list ticket instances")
+	assert.Len(t, list.TicketInstances, 2, "expected an instance per ticket")
+
+	code, err := newTestSigner(t).Verify(list.TicketInstances[0].Token)
+	assert.NoError(t, err, "expected the token to verify")
+	assert.Equal(t, "code", code, "expected the token to carry the instance code")
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestListPurchaseInstances_PurchaseNotFound(t *testing.T) {
+	ticketInstanceService, mock := setupTicketInstanceTest(t)
+
+	mock.ExpectQuery("SELECT id FROM purchase").
+		WithArgs(7, testOrganizerID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	_, err := ticketInstanceService.ListPurchaseInstances(testOrganizerID, 7)
+	assert.Error(t, err, "expected error for an unknown purchase")
+	restErr, ok := err.(errors.RestError)
+	assert.True(t, ok, "expected a RestError")
+	assert.Equal(t, 404, restErr.Status)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestQRCode_Success(t *testing.T) {
+	ticketInstanceService, mock := setupTicketInstanceTest(t)
+
+	mock.ExpectQuery("SELECT (.+) FROM ticket_instance WHERE id = (.+)").
+		WithArgs(1, testOrganizerID).
+		WillReturnRows(newTicketInstanceRows(
+			&models.TicketInstance{ID: 1, TicketID: 1, PurchaseID: 7, Status: models.TicketInstanceStatusValid, CreatedAt: time.Now()},
+		))
+
+	png, err := ticketInstanceService.QRCode(testOrganizerID, 1, 0)
+	assert.NoError(t, err, "failed to render QR code")
+	assert.True(t, bytes.HasPrefix(png, []byte("\x89PNG")), "expected a PNG image")
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestQRCode_VoidInstance(t *testing.T) {
+	ticketInstanceService, mock := setupTicketInstanceTest(t)
+
+	voidedAt := time.Now()
+	mock.ExpectQuery("SELECT (.+) FROM ticket_instance WHERE id = (.+)").
+		WithArgs(1, testOrganizerID).
+		WillReturnRows(newTicketInstanceRows(
+			&models.TicketInstance{
+				ID: 1, TicketID: 1, PurchaseID: 7, Status: models.TicketInstanceStatusVoid, CreatedAt: time.Now(),
+				VoidedAt: &voidedAt,
+			},
+		))
+
+	_, err := ticketInstanceService.QRCode(testOrganizerID, 1, 0)
+	assert.Error(t, err, "expected error for a void instance")
+	restErr, ok := err.(errors.RestError)
+	assert.True(t, ok, "expected a RestError")
+	assert.Equal(t, 400, restErr.Status)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestQRCode_InvalidSize(t *testing.T) {
+	ticketInstanceService, mock := setupTicketInstanceTest(t)
+
+	_, err := ticketInstanceService.QRCode(testOrganizerID, 1, models.TicketQRCodeMaxSize+1)
+	assert.Error(t, err, "expected error for an oversized QR code")
+	restErr, ok := err.(errors.RestError)
+	assert.True(t, ok, "expected a RestError")
+	assert.Equal(t, 400, restErr.Status)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTicketSigner_Rotation(t *testing.T) {
+	oldKey := []byte("fedcba9876543210fedcba9876543210")
+	oldSigner, err := services.NewTicketSigner(map[string][]byte{"k0": oldKey}, "")
+	assert.NoError(t, err)
+
+	signer, err := services.NewTicketSigner(map[string][]byte{"k0": oldKey, "k1": testSigningKey}, "k1")
+	assert.NoError(t, err)
+
+	code, err := signer.Verify(oldSigner.Sign("code"))
+	assert.NoError(t, err, "expected a token of the previous key to verify")
+	assert.Equal(t, "code", code)
+
+	_, err = oldSigner.Verify(signer.Sign("code"))
+	assert.Error(t, err, "expected a token of an unknown key to be rejected")
+}
+
+func TestTicketSigner_TamperedToken(t *testing.T) {
+	signer := newTestSigner(t)
+
+	token := signer.Sign("code")
+	_, err := signer.Verify("other" + token[len("code"):])
+	assert.Error(t, err, "expected a tampered token to be rejected")
+
+	_, err = signer.Verify("code")
+	assert.Error(t, err, "expected a malformed token to be rejected")
+}
+
+func TestParseSigningKeys(t *testing.T) {
+	keys, err := services.ParseSigningKeys("k1:secret, k2:other")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"k1": []byte("secret"), "k2": []byte("other")}, keys)
+
+	_, err = services.ParseSigningKeys("k1")
+	assert.Error(t, err, "expected error for a key without a secret")
+
+	_, err = services.ParseSigningKeys("k1:a,k1:b")
+	assert.Error(t, err, "expected error for a duplicate key")
+
+	keys, err = services.ParseSigningKeys(`{"k1": "secret", "k2": "other"}`)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"k1": []byte("secret"), "k2": []byte("other")}, keys, "expected keys from a JSON secret")
+
+	_, err = services.ParseSigningKeys(`{"k.1": "secret"}`)
+	assert.Error(t, err, "expected error for a key id with a dot")
+
+	_, err = services.NewTicketSigner(map[string][]byte{"k1": []byte("short")}, "k1")
+	assert.Error(t, err, "expected error for a short key")
+}
+
+func TestGetInstance_OtherOrganizer(t *testing.T) {
+	ticketInstanceService, mock := setupTicketInstanceTest(t)
+
+	mock.ExpectQuery("SELECT (.+) FROM ticket_instance WHERE id = (.+) AND ticket_id IN \\(SELECT id FROM ticket WHERE organizer_id = (.+)\\)").
+		WithArgs(1, testOrganizerID).
+		WillReturnRows(newTicketInstanceRows())
+
+	_, err := ticketInstanceService.GetInstance(testOrganizerID, 1)
+	assert.Error(t, err, "expected error for an instance of another organizer")
+	restErr, ok := err.(errors.RestError)
+	assert.True(t, ok, "expected a RestError")
+	assert.Equal(t, 404, restErr.Status)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetInstance_VoidHasNoToken(t *testing.T) {
+	ticketInstanceService, mock := setupTicketInstanceTest(t)
+
+	voidedAt := time.Now()
+	mock.ExpectQuery("SELECT (.+) FROM ticket_instance WHERE id = (.+)").
+		WithArgs(1, testOrganizerID).
+		WillReturnRows(newTicketInstanceRows(
+			&models.TicketInstance{
+				ID: 1, TicketID: 1, PurchaseID: 7, Status: models.TicketInstanceStatusVoid, CreatedAt: time.Now(),
+				VoidedAt: &voidedAt,
+			},
+		))
+
+	instance, err := ticketInstanceService.GetInstance(testOrganizerID, 1)
+	assert.NoError(t, err, "failed to get ticket instance")
+	assert.Empty(t, instance.Token, "expected no token for a void instance")
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
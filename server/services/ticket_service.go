@@ -1,7 +1,10 @@
 package services
 
 import (
+	"crypto/sha256"
 	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"gowitcase/db"
@@ -10,9 +13,36 @@ import (
 	"log"
 	"math"
 	"strconv"
+	"strings"
 	"time"
 )
 
+const ticketColumns = "id, organizer_id, event_id, capacity_pool_id, name, description, allocation, held, sold, max_per_buyer, min_per_order, max_per_order, quantity_step, seated, price, currency, " +
+	"sale_starts_at, sale_ends_at, created_at, updated_at, archived_at"
+
+// ticketSortColumns maps the sort keys accepted by ListTickets to their columns.
+var ticketSortColumns = map[string]string{
+	"id":         "id",
+	"name":       "name",
+	"allocation": "allocation",
+	"created_at": "created_at",
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// ticketCursor is the decoded form of the opaque next_cursor returned by ListTickets.
+// It remembers the sort it was issued for, so it can't be replayed against another ordering.
+type ticketCursor struct {
+	Sort  string `json:"s"`
+	Order string `json:"o"`
+	Value string `json:"v"`
+	ID    int    `json:"id"`
+}
+
+// TicketService manages the tickets of an organizer. Every method is scoped to the
+// organizer given as its first argument, tickets of other organizers are not found.
 type TicketService struct {
 	DB    db.DatabaseInterface
 	Cache db.RedisInterface
@@ -22,32 +52,102 @@ func NewTicketService(db db.DatabaseInterface, cache db.RedisInterface) *TicketS
 	return &TicketService{DB: db, Cache: cache}
 }
 
-func (s *TicketService) CreateTicket(ticket *models.Ticket) error {
+func (s *TicketService) CreateTicket(organizerID int, ticket *models.Ticket) error {
 	if ticket == nil {
 		return fmt.Errorf("ticket is nil")
 	}
 
-	err := s.ValidateTicket(*ticket)
+	ticket.OrganizerID = organizerID
+	if ticket.Price.Currency == "" {
+		ticket.Price.Currency = models.DefaultCurrency
+	}
+	setOrderQuantityDefaults(ticket)
+
+	var fields errors.FieldErrors
+	if ticket.TotalAllocation != 0 {
+		if ticket.Allocation == 0 {
+			ticket.Allocation = ticket.TotalAllocation
+		} else if ticket.Allocation != ticket.TotalAllocation {
+			fields.Add("allocation", "Field 'allocation' must equal 'total_allocation' on a new ticket")
+		}
+	}
+
+	validateTicket(*ticket, &fields)
+	err := fields.Err()
 	if err != nil {
 		return err
 	}
 
+	if ticket.EventID != nil {
+		err = s.checkEvent(organizerID, *ticket.EventID)
+		if err != nil {
+			return err
+		}
+	}
+
+	if ticket.CapacityPoolID != nil {
+		err = checkCapacityPool(s.DB, organizerID, *ticket.CapacityPoolID)
+		if err != nil {
+			return err
+		}
+	}
+
 	err = s.DB.QueryRow(
-		"INSERT INTO ticket (name, description, allocation) VALUES ($1, $2, $3) RETURNING id",
-		ticket.Name, ticket.Description, ticket.Allocation,
-	).Scan(&ticket.ID)
+		"INSERT INTO ticket (organizer_id, event_id, capacity_pool_id, name, description, allocation, max_per_buyer, min_per_order, "+
+			"max_per_order, quantity_step, price, currency, sale_starts_at, sale_ends_at) "+
+			"VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14) RETURNING id, created_at, updated_at",
+		ticket.OrganizerID, ticket.EventID, ticket.CapacityPoolID, ticket.Name, ticket.Description, ticket.Allocation, ticket.MaxPerBuyer, ticket.MinPerOrder, ticket.MaxPerOrder,
+		ticket.QuantityStep, ticket.Price.Amount, ticket.Price.Currency, ticket.SaleStartsAt, ticket.SaleEndsAt,
+	).Scan(&ticket.ID, &ticket.CreatedAt, &ticket.UpdatedAt)
 
 	if err != nil {
 		return err
 	}
 
+	ticket.TotalAllocation = ticket.Allocation
+	ticket.SaleStatus = ticket.CurrentSaleStatus(time.Now())
+
+	err = invalidateTicketListCache(s.Cache, organizerID)
+	if err != nil {
+		log.Printf("Failed to invalidate ticket list cache: %v", err)
+	}
+
+	if ticket.EventID != nil {
+		err = invalidateEventCache(s.Cache, organizerID, *ticket.EventID)
+		if err != nil {
+			log.Printf("Failed to invalidate cache: %v for event: %d", err, *ticket.EventID)
+		}
+	}
+
 	return nil
 }
 
-func (s *TicketService) GetTicket(id int) (*models.Ticket, error) {
-	ticket, err := s.getCacheTicket(id)
+// checkEvent makes sure a new ticket type is added to an event of the organizer
+// that still sells tickets.
+func (s *TicketService) checkEvent(organizerID int, eventID int) error {
+	var archivedAt *time.Time
+	err := s.DB.QueryRow(
+		"SELECT archived_at FROM event WHERE id = $1 AND organizer_id = $2", eventID, organizerID,
+	).Scan(&archivedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errors.NewRestError(fmt.Sprintf("Event %d does not exist", eventID), 400)
+		}
+		return fmt.Errorf("failed to get event: %v", err)
+	}
+
+	if archivedAt != nil {
+		return errors.NewRestError(fmt.Sprintf("Event %d is archived", eventID), 400).WithCode(errors.CodeEventArchived)
+	}
+
+	return nil
+}
+
+func (s *TicketService) GetTicket(organizerID int, id int) (*models.Ticket, error) {
+	ticket, err := s.getCacheTicket(organizerID, id)
 	if err == nil && ticket != nil {
 		log.Printf("Cache hit for ticket: %d", id)
+		ticket.SaleStatus = ticket.CurrentSaleStatus(time.Now())
 		return ticket, nil
 	}
 
@@ -57,10 +157,10 @@ func (s *TicketService) GetTicket(id int) (*models.Ticket, error) {
 
 	ticket = &models.Ticket{}
 
-	err = s.DB.QueryRow(
-		"SELECT id, name, description, allocation FROM ticket WHERE id = $1",
-		id,
-	).Scan(&ticket.ID, &ticket.Name, &ticket.Description, &ticket.Allocation)
+	err = scanTicket(s.DB.QueryRow(
+		"SELECT "+ticketColumns+" FROM ticket WHERE id = $1 AND organizer_id = $2",
+		id, organizerID,
+	), ticket)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, errors.NewRestError(fmt.Sprintf("Ticket %d not found", id), 404)
@@ -73,127 +173,705 @@ func (s *TicketService) GetTicket(id int) (*models.Ticket, error) {
 		log.Printf("Failed to cache ticket: %v", err)
 	}
 
+	ticket.SaleStatus = ticket.CurrentSaleStatus(time.Now())
+
 	return ticket, nil
 }
 
-func (s *TicketService) PurchaseTicket(ticketID int, quantity int) error {
-
-	if quantity <= 0 || quantity > math.MaxInt32 {
-		return errors.NewRestError("Quantity must be a positive number within the valid range", 400)
-	}
+// GetTickets returns the organizer's tickets among ids, keyed by ID. It reads the
+// ticket cache first and loads all misses with one query. IDs that aren't found
+// are left out.
+func (s *TicketService) GetTickets(organizerID int, ids []int) (map[int]*models.Ticket, error) {
+	return getTicketsByID(s.DB, s.Cache, organizerID, ids)
+}
 
+// UpdateTicket applies the non-nil fields of update to the ticket. The total
+// allocation can't go below what has already been sold or held. Tickets a top-up
+// makes available are offered to the waitlist first.
+func (s *TicketService) UpdateTicket(organizerID int, id int, update models.TicketUpdate) (*models.Ticket, error) {
 	tx, err := s.DB.BeginTransaction()
 	if err != nil {
 		log.Printf("Failed to start transaction: %v", err)
-		return fmt.Errorf("failed to start transaction: %v", err)
+		return nil, fmt.Errorf("failed to start transaction: %v", err)
 	}
+	defer tx.Rollback()
 
-	defer func() {
-		if p := recover(); p != nil {
-			tx.Rollback()
-			panic(p)
-		} else if err != nil {
-			tx.Rollback()
-		} else {
-			err = tx.Commit()
+	ticket, err := lockOrganizerTicket(tx, organizerID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if ticket.ArchivedAt != nil {
+		return nil, errors.NewRestError(fmt.Sprintf("Ticket %d is archived", id), 400).WithCode(errors.CodeTicketArchived)
+	}
+
+	if update.Name != nil {
+		ticket.Name = *update.Name
+	}
+	if update.Description != nil {
+		ticket.Description = *update.Description
+	}
+	if update.MaxPerBuyer != nil {
+		ticket.MaxPerBuyer = *update.MaxPerBuyer
+	}
+	if update.MinPerOrder != nil {
+		ticket.MinPerOrder = *update.MinPerOrder
+	}
+	if update.MaxPerOrder != nil {
+		ticket.MaxPerOrder = *update.MaxPerOrder
+	}
+	if update.QuantityStep != nil {
+		ticket.QuantityStep = *update.QuantityStep
+	}
+	setOrderQuantityDefaults(ticket)
+	if update.Price != nil {
+		currency := ticket.Price.Currency
+		ticket.Price = *update.Price
+		if ticket.Price.Currency == "" {
+			ticket.Price.Currency = currency
 		}
-	}()
+	}
+	if update.SaleStartsAt.Set {
+		ticket.SaleStartsAt = update.SaleStartsAt.Value
+	}
+	if update.SaleEndsAt.Set {
+		ticket.SaleEndsAt = update.SaleEndsAt.Value
+	}
+
+	var fields errors.FieldErrors
+	totalAllocation := requestedTotalAllocation(ticket, update, &fields)
+	if totalAllocation != ticket.TotalAllocation && ticket.Seated {
+		field := allocationField(update)
+		fields.Add(field, fmt.Sprintf("Field '%s' of ticket %d follows its seat map", field, id))
+		totalAllocation = ticket.TotalAllocation
+	}
+
+	validateTicket(models.Ticket{
+		Name:         ticket.Name,
+		Description:  ticket.Description,
+		Allocation:   totalAllocation,
+		MaxPerBuyer:  ticket.MaxPerBuyer,
+		MinPerOrder:  ticket.MinPerOrder,
+		MaxPerOrder:  ticket.MaxPerOrder,
+		QuantityStep: ticket.QuantityStep,
+		Price:        ticket.Price,
+		SaleStartsAt: ticket.SaleStartsAt,
+		SaleEndsAt:   ticket.SaleEndsAt,
+	}, &fields)
+
+	if !fields.Has("allocation") && totalAllocation < ticket.Sold+ticket.Held {
+		fields.Add(
+			"total_allocation", fmt.Sprintf("Field 'total_allocation' can't be less than the %d tickets already sold or held", ticket.Sold+ticket.Held),
+		)
+	}
+
+	err = fields.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	ticket.Allocation = totalAllocation - ticket.Sold - ticket.Held
+	ticket.TotalAllocation = totalAllocation
+
+	if update.CapacityPoolID.Set && !sameID(update.CapacityPoolID.Value, ticket.CapacityPoolID) {
+		err = moveToCapacityPool(tx, ticket, update.CapacityPoolID.Value)
+		if err != nil {
+			return nil, err
+		}
+	}
 
-	ticket := &models.Ticket{}
 	err = tx.QueryRow(
-		"SELECT id, name, description, allocation FROM ticket WHERE id = $1 FOR UPDATE",
-		ticketID,
-	).Scan(&ticket.ID, &ticket.Name, &ticket.Description, &ticket.Allocation)
+		"UPDATE ticket SET name = $1, description = $2, allocation = $3, max_per_buyer = $4, min_per_order = $5, "+
+			"max_per_order = $6, quantity_step = $7, price = $8, currency = $9, sale_starts_at = $10, sale_ends_at = $11, "+
+			"capacity_pool_id = $12, updated_at = CURRENT_TIMESTAMP WHERE id = $13 RETURNING updated_at",
+		ticket.Name, ticket.Description, ticket.Allocation, ticket.MaxPerBuyer, ticket.MinPerOrder, ticket.MaxPerOrder,
+		ticket.QuantityStep, ticket.Price.Amount, ticket.Price.Currency, ticket.SaleStartsAt, ticket.SaleEndsAt,
+		ticket.CapacityPoolID, ticket.ID,
+	).Scan(&ticket.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update ticket: %v", err)
+	}
 
+	err = offerToWaitlist(tx, ticket)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	err = s.invalidateCache(organizerID, id)
+	if err != nil {
+		log.Printf("Failed to invalidate cache: %v for ticket: %d", err, id)
+	}
+
+	ticket.SaleStatus = ticket.CurrentSaleStatus(time.Now())
+
+	return ticket, nil
+}
+
+// ArchiveTicket hides the ticket from listings and stops its sales. Archiving is
+// idempotent and keeps the row, so existing references keep resolving.
+func (s *TicketService) ArchiveTicket(organizerID int, id int) error {
+	var archivedAt time.Time
+	var eventID *int
+	err := s.DB.QueryRow(
+		"UPDATE ticket SET archived_at = COALESCE(archived_at, CURRENT_TIMESTAMP), updated_at = CURRENT_TIMESTAMP "+
+			"WHERE id = $1 AND organizer_id = $2 RETURNING archived_at, event_id",
+		id, organizerID,
+	).Scan(&archivedAt, &eventID)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			err = errors.NewRestError(fmt.Sprintf("Ticket %d not found", ticketID), 404)
-			return err
+			return errors.NewRestError(fmt.Sprintf("Ticket %d not found", id), 404)
 		}
+		return fmt.Errorf("failed to archive ticket: %v", err)
+	}
 
-		return fmt.Errorf("failed to get ticket: %v", err)
+	err = s.invalidateCache(organizerID, id)
+	if err != nil {
+		log.Printf("Failed to invalidate cache: %v for ticket: %d", err, id)
 	}
 
-	if ticket.Allocation == 0 {
-		err = errors.NewRestError("Ticket is sold out", 400)
-		return err
+	if eventID != nil {
+		err = invalidateEventCache(s.Cache, organizerID, *eventID)
+		if err != nil {
+			log.Printf("Failed to invalidate cache: %v for event: %d", err, *eventID)
+		}
 	}
 
-	if ticket.Allocation < quantity {
-		err = errors.NewRestError("Not enough tickets available", 400)
-		return err
+	return nil
+}
+
+func (s *TicketService) ListTickets(organizerID int, params models.TicketListParams) (*models.TicketList, error) {
+	err := s.normalizeListParams(&params)
+	if err != nil {
+		return nil, err
 	}
 
-	ticket.Allocation -= quantity
+	var cursor *ticketCursor
+	if params.Cursor != "" {
+		cursor, err = decodeTicketCursor(params.Cursor)
+		if err != nil || cursor.Sort != params.Sort || cursor.Order != params.Order {
+			return nil, errors.NewRestError("Invalid cursor", 400)
+		}
+	}
 
-	_, err = tx.Exec(
-		"UPDATE ticket SET allocation = $1 WHERE id = $2",
-		ticket.Allocation, ticket.ID,
-	)
+	cacheKey, err := s.getListCacheKey(organizerID, params)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := s.getCacheTicketList(cacheKey)
+	if err == nil && list != nil {
+		setSaleStatuses(list.Tickets)
+		return list, nil
+	}
+
+	column := ticketSortColumns[params.Sort]
+	direction := strings.ToUpper(params.Order)
+
+	var args []interface{}
+	addArg := func(value interface{}) string {
+		args = append(args, value)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	conditions := []string{"organizer_id = " + addArg(organizerID), "archived_at IS NULL"}
+
+	if params.Name != "" {
+		conditions = append(conditions, "name ILIKE "+addArg("%"+escapeLike(params.Name)+"%"))
+	}
+	if params.EventID > 0 {
+		conditions = append(conditions, "event_id = "+addArg(params.EventID))
+	}
+	if params.MinAllocation > 0 {
+		conditions = append(conditions, "allocation >= "+addArg(params.MinAllocation))
+	}
+	if params.CreatedFrom != nil {
+		conditions = append(conditions, "created_at >= "+addArg(*params.CreatedFrom))
+	}
+	if params.CreatedTo != nil {
+		conditions = append(conditions, "created_at <= "+addArg(*params.CreatedTo))
+	}
+	if cursor != nil {
+		value, err := cursorValue(cursor)
+		if err != nil {
+			return nil, errors.NewRestError("Invalid cursor", 400)
+		}
+
+		operator := ">"
+		if direction == "DESC" {
+			operator = "<"
+		}
+
+		if column == "id" {
+			conditions = append(conditions, "id "+operator+" "+addArg(cursor.ID))
+		} else {
+			conditions = append(conditions, fmt.Sprintf("(%s, id) %s (%s, %s)", column, operator, addArg(value), addArg(cursor.ID)))
+		}
+	}
+
+	query := "SELECT " + ticketColumns + " FROM ticket WHERE " + strings.Join(conditions, " AND ")
+	query += fmt.Sprintf(" ORDER BY %s %s", column, direction)
+	if column != "id" {
+		query += ", id " + direction
+	}
+	// One extra row tells whether there is a next page.
+	query += " LIMIT " + addArg(params.Limit+1)
+
+	rows, err := s.DB.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tickets: %v", err)
+	}
+	defer rows.Close()
+
+	list = &models.TicketList{Tickets: []models.Ticket{}}
+	for rows.Next() {
+		ticket := models.Ticket{}
+		if err := scanTicket(rows, &ticket); err != nil {
+			return nil, fmt.Errorf("failed to scan ticket: %v", err)
+		}
+		list.Tickets = append(list.Tickets, ticket)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list tickets: %v", err)
+	}
+
+	if len(list.Tickets) > params.Limit {
+		list.Tickets = list.Tickets[:params.Limit]
+		list.NextCursor, err = encodeTicketCursor(params, list.Tickets[params.Limit-1])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = s.cacheTicketList(cacheKey, list)
+	if err != nil {
+		log.Printf("Failed to cache ticket list: %v", err)
+	}
+
+	setSaleStatuses(list.Tickets)
+
+	return list, nil
+}
+
+func (s *TicketService) PurchaseTicket(organizerID int, ticketID int, request models.PurchaseRequest) (*models.Purchase, error) {
+	quantity, err := validatePurchaseRequest(request.BuyerID, request.Quantity, request.SeatIDs)
+	if err != nil {
+		return nil, err
+	}
+	request.Quantity = quantity
+
+	tx, err := s.DB.BeginTransaction()
+	if err != nil {
+		log.Printf("Failed to start transaction: %v", err)
+		return nil, fmt.Errorf("failed to start transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	ticket, err := lockOrganizerTicket(tx, organizerID, ticketID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkAvailability(tx, ticket, request.BuyerID, request.Quantity)
+	if err != nil {
+		return nil, err
+	}
+
+	err = lockSeats(tx, ticket, request.SeatIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	err = takePoolCapacity(tx, ticket.CapacityPoolID, request.Quantity)
+	if err != nil {
+		return nil, err
+	}
+
+	unitPrice, _, err := currentPrice(tx, ticket)
 	if err != nil {
-		return fmt.Errorf("failed to update ticket: %v", err)
+		return nil, err
+	}
+
+	purchase := &models.Purchase{
+		TicketID: ticket.ID, BuyerID: request.BuyerID, Quantity: request.Quantity, UnitPrice: unitPrice, SeatIDs: request.SeatIDs,
+	}
+
+	if request.PromoCode != "" {
+		subtotal, err := unitPrice.Mul(int64(request.Quantity))
+		if err != nil {
+			return nil, errors.NewRestError("Purchase total is too large", 400)
+		}
+
+		promoCode, discount, err := redeemPromoCode(tx, request.PromoCode, ticket, subtotal)
+		if err != nil {
+			return nil, err
+		}
+		purchase.PromoCodeID = &promoCode.ID
+		purchase.Discount = discount
+	}
+
+	ticket.Allocation -= request.Quantity
+	ticket.Sold += request.Quantity
+
+	err = updateTicketInventory(tx, ticket)
+	if err != nil {
+		return nil, err
+	}
+
+	err = insertPurchase(tx, purchase)
+	if err != nil {
+		return nil, err
+	}
+
+	if ticket.Seated {
+		err = assignSeats(tx, purchase.SeatIDs, models.SeatStatusSold, nil, &purchase.ID)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	err = tx.Commit()
 	if err != nil {
-		return fmt.Errorf("failed to commit transaction: %v", err)
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
 	}
 
-	err = s.invalidateCache(ticketID)
+	err = s.invalidateCache(organizerID, ticketID)
 	if err != nil {
-		log.Printf("Failed to invalidate cache: %v for ticker: %d", err, ticketID)
+		log.Printf("Failed to invalidate cache: %v for ticket: %d", err, ticketID)
 	}
 
-	return nil
+	return purchase, nil
+}
+
+// requestedTotalAllocation is the total allocation an update asks for. The
+// allocation of an update is what is left to sell, the same as on a read ticket,
+// so it is added to the tickets already sold or held. An invalid request is added
+// to fields and leaves the total as it is.
+func requestedTotalAllocation(ticket *models.Ticket, update models.TicketUpdate, fields *errors.FieldErrors) int {
+	soldOrHeld := ticket.Sold + ticket.Held
+
+	totalAllocation := ticket.TotalAllocation
+	if update.TotalAllocation != nil {
+		totalAllocation = *update.TotalAllocation
+	}
+	if update.Allocation == nil {
+		return totalAllocation
+	}
+
+	if *update.Allocation < 0 {
+		fields.Add("allocation", "Field 'allocation' must not be negative")
+		return ticket.TotalAllocation
+	}
+	if update.TotalAllocation != nil && *update.Allocation+soldOrHeld != totalAllocation {
+		fields.Add(
+			"allocation", fmt.Sprintf("Field 'allocation' must be 'total_allocation' less the %d tickets already sold or held", soldOrHeld),
+		)
+		return ticket.TotalAllocation
+	}
+
+	return *update.Allocation + soldOrHeld
+}
+
+// allocationField is the field an update sets the size of the ticket with.
+func allocationField(update models.TicketUpdate) string {
+	if update.Allocation != nil {
+		return "allocation"
+	}
+	return "total_allocation"
 }
 
+// ValidateTicket checks every field of the ticket and reports all the invalid ones.
 func (s *TicketService) ValidateTicket(ticket models.Ticket) error {
+	var fields errors.FieldErrors
+	validateTicket(ticket, &fields)
+	return fields.Err()
+}
+
+// validateTicket adds the invalid fields of the ticket to fields, so the checks
+// of a request that come before can be reported with them.
+func validateTicket(ticket models.Ticket, fields *errors.FieldErrors) {
 	if ticket.Name == "" {
-		return errors.NewRestError("Field 'name' is required", 400)
+		fields.Add("name", "Field 'name' is required")
+	} else if len(ticket.Name) > 255 {
+		fields.Add("name", "Field 'name' must be less than 255 characters")
+	}
+
+	switch {
+	case fields.Has("allocation"):
+		// Already reported, such as when it disagrees with 'total_allocation'.
+	case ticket.Allocation <= 0:
+		fields.Add("allocation", "Field 'allocation' must be greater than 0")
+	case ticket.Allocation > math.MaxInt32:
+		fields.Add("allocation", "Field 'allocation' is too large")
+	}
+
+	if ticket.MaxPerBuyer < 0 {
+		fields.Add("max_per_buyer", "Field 'max_per_buyer' must not be negative")
+	} else if ticket.MaxPerBuyer > math.MaxInt32 {
+		fields.Add("max_per_buyer", "Field 'max_per_buyer' is too large")
+	}
+
+	validateOrderQuantities(ticket, fields)
+
+	if !models.IsKnownCurrency(ticket.Price.Currency) {
+		fields.Add("price.currency", fmt.Sprintf("Currency '%s' is not supported", ticket.Price.Currency))
+	}
+
+	if ticket.Price.Amount < 0 {
+		fields.Add("price.amount", "Field 'price.amount' must not be negative")
 	}
 
-	if len(ticket.Name) > 255 {
-		return errors.NewRestError("Field 'name' must be less than 255 characters", 400)
+	if ticket.SaleStartsAt != nil && ticket.SaleEndsAt != nil && !ticket.SaleEndsAt.After(*ticket.SaleStartsAt) {
+		fields.Add("sale_ends_at", "Field 'sale_ends_at' must be after 'sale_starts_at'")
 	}
+}
 
-	if ticket.Allocation <= 0 {
-		return errors.NewRestError("Field 'allocation' must be greater than 0", 400)
+func (s *TicketService) normalizeListParams(params *models.TicketListParams) error {
+	if params.Sort == "" {
+		params.Sort = "id"
+	}
+	if _, ok := ticketSortColumns[params.Sort]; !ok {
+		return errors.NewRestError(fmt.Sprintf("Unsupported sort field '%s'", params.Sort), 400)
 	}
 
-	if ticket.Allocation > math.MaxInt32 {
-		return errors.NewRestError("Allocation is too large", 400)
+	params.Order = strings.ToLower(params.Order)
+	if params.Order == "" {
+		params.Order = "asc"
+	}
+	if params.Order != "asc" && params.Order != "desc" {
+		return errors.NewRestError("Order must be either 'asc' or 'desc'", 400)
+	}
+
+	if params.Limit < 0 {
+		return errors.NewRestError("Limit must be a positive number", 400)
+	}
+	if params.Limit == 0 {
+		params.Limit = models.TicketListDefaultLimit
+	}
+	if params.Limit > models.TicketListMaxLimit {
+		params.Limit = models.TicketListMaxLimit
+	}
+
+	if params.MinAllocation < 0 {
+		return errors.NewRestError("Min allocation must not be negative", 400)
+	}
+
+	if params.EventID < 0 {
+		return errors.NewRestError("Event ID must not be negative", 400)
+	}
+
+	if params.CreatedFrom != nil && params.CreatedTo != nil && params.CreatedFrom.After(*params.CreatedTo) {
+		return errors.NewRestError("Created from must be before created to", 400)
 	}
 
 	return nil
 }
 
-func (s *TicketService) cacheTicket(ticket *models.Ticket) error {
-	ticketBytes, err := json.Marshal(ticket)
+// moveToCapacityPool moves the sold and held tickets of the locked ticket from its
+// pool to poolID, which has to belong to the ticket's organizer and fit them. Both
+// pools are locked before either changes, so opposite moves can't deadlock.
+func moveToCapacityPool(tx *sql.Tx, ticket *models.Ticket, poolID *int) error {
+	if poolID != nil {
+		err := checkCapacityPool(tx, ticket.OrganizerID, *poolID)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := lockCapacityPools(tx, ticket.CapacityPoolID, poolID)
 	if err != nil {
-		return fmt.Errorf("failed to marshal ticket: %v", err)
+		return err
+	}
+
+	err = returnPoolCapacity(tx, ticket.CapacityPoolID, ticket.Sold+ticket.Held)
+	if err != nil {
+		return err
 	}
-	return s.Cache.Set(s.getCacheKey(ticket.ID), string(ticketBytes), 5*time.Minute)
+
+	err = takePoolCapacity(tx, poolID, ticket.Sold+ticket.Held)
+	if err != nil {
+		return err
+	}
+
+	ticket.CapacityPoolID = poolID
+	return nil
 }
 
-func (s *TicketService) invalidateCache(ticketID int) error {
-	return s.Cache.Del(s.getCacheKey(ticketID))
+func sameID(a *int, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// setOrderQuantityDefaults lets a ticket be bought one at a time when its order
+// quantities are left out.
+func setOrderQuantityDefaults(ticket *models.Ticket) {
+	if ticket.MinPerOrder == 0 {
+		ticket.MinPerOrder = 1
+	}
+	if ticket.QuantityStep == 0 {
+		ticket.QuantityStep = 1
+	}
 }
 
-func (s *TicketService) getCacheTicket(ticketID int) (*models.Ticket, error) {
-	ticket := &models.Ticket{}
-	ticketJSON, err := s.Cache.Get(s.getCacheKey(ticketID))
+// validateOrderQuantities checks that some order quantity satisfies the minimum,
+// maximum and step of the ticket together. The checks combining them are skipped
+// when one of them is invalid on its own.
+func validateOrderQuantities(ticket models.Ticket, fields *errors.FieldErrors) {
+	if ticket.MinPerOrder < 1 || ticket.MinPerOrder > math.MaxInt32 {
+		fields.Add("min_per_order", "Field 'min_per_order' must be a positive number within the valid range")
+	}
+
+	if ticket.MaxPerOrder < 0 || ticket.MaxPerOrder > math.MaxInt32 {
+		fields.Add("max_per_order", "Field 'max_per_order' must not be negative and within the valid range")
+	}
+
+	if ticket.QuantityStep < 1 || ticket.QuantityStep > math.MaxInt32 {
+		fields.Add("quantity_step", "Field 'quantity_step' must be a positive number within the valid range")
+		return
+	}
+
+	if !fields.Has("min_per_order") && ticket.MinPerOrder%ticket.QuantityStep != 0 {
+		fields.Add("min_per_order", fmt.Sprintf("Field 'min_per_order' must be a multiple of 'quantity_step' (%d)", ticket.QuantityStep))
+	}
+
+	if ticket.MaxPerOrder > 0 && !fields.Has("max_per_order") {
+		if !fields.Has("min_per_order") && ticket.MaxPerOrder < ticket.MinPerOrder {
+			fields.Add("max_per_order", "Field 'max_per_order' must not be less than 'min_per_order'")
+		} else if ticket.MaxPerOrder%ticket.QuantityStep != 0 {
+			fields.Add("max_per_order", fmt.Sprintf("Field 'max_per_order' must be a multiple of 'quantity_step' (%d)", ticket.QuantityStep))
+		}
+	}
+}
+
+func encodeTicketCursor(params models.TicketListParams, last models.Ticket) (string, error) {
+	cursor := ticketCursor{Sort: params.Sort, Order: params.Order, ID: last.ID}
+
+	switch params.Sort {
+	case "name":
+		cursor.Value = last.Name
+	case "allocation":
+		cursor.Value = strconv.Itoa(last.Allocation)
+	case "created_at":
+		cursor.Value = last.CreatedAt.Format(time.RFC3339Nano)
+	}
+
+	cursorBytes, err := json.Marshal(cursor)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal cursor: %v", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(cursorBytes), nil
+}
+
+func decodeTicketCursor(encoded string) (*ticketCursor, error) {
+	cursorBytes, err := base64.RawURLEncoding.DecodeString(encoded)
 	if err != nil {
 		return nil, err
 	}
 
-	err = json.Unmarshal([]byte(ticketJSON), &ticket)
+	cursor := &ticketCursor{}
+	err = json.Unmarshal(cursorBytes, cursor)
 	if err != nil {
 		return nil, err
 	}
 
-	return ticket, nil
+	return cursor, nil
+}
+
+// cursorValue converts the cursor value back to the type of its sort column.
+func cursorValue(cursor *ticketCursor) (interface{}, error) {
+	switch cursor.Sort {
+	case "name":
+		return cursor.Value, nil
+	case "allocation":
+		return strconv.Atoi(cursor.Value)
+	case "created_at":
+		return time.Parse(time.RFC3339Nano, cursor.Value)
+	}
+	return cursor.ID, nil
 }
 
-func (s *TicketService) getCacheKey(ticketID int) string {
-	return models.TicketCachePrefix + strconv.Itoa(ticketID)
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
+
+func setSaleStatuses(tickets []models.Ticket) {
+	now := time.Now()
+	for i := range tickets {
+		tickets[i].SaleStatus = tickets[i].CurrentSaleStatus(now)
+	}
+}
+
+func scanTicket(row rowScanner, ticket *models.Ticket) error {
+	err := row.Scan(
+		&ticket.ID, &ticket.OrganizerID, &ticket.EventID, &ticket.CapacityPoolID, &ticket.Name, &ticket.Description, &ticket.Allocation, &ticket.Held, &ticket.Sold, &ticket.MaxPerBuyer,
+		&ticket.MinPerOrder, &ticket.MaxPerOrder, &ticket.QuantityStep, &ticket.Seated, &ticket.Price.Amount, &ticket.Price.Currency, &ticket.SaleStartsAt, &ticket.SaleEndsAt, &ticket.CreatedAt, &ticket.UpdatedAt, &ticket.ArchivedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	ticket.TotalAllocation = ticket.Allocation + ticket.Held + ticket.Sold
+	return nil
+}
+
+func (s *TicketService) cacheTicket(ticket *models.Ticket) error {
+	return cacheTicket(s.Cache, ticket)
+}
+
+func (s *TicketService) invalidateCache(organizerID int, ticketID int) error {
+	return invalidateTicketCache(s.Cache, organizerID, ticketID)
+}
+
+func (s *TicketService) getCacheTicket(organizerID int, ticketID int) (*models.Ticket, error) {
+	return getCachedTicket(s.Cache, organizerID, ticketID)
+}
+
+func (s *TicketService) getCacheKey(organizerID int, ticketID int) string {
+	return ticketCacheKey(organizerID, ticketID)
+}
+
+// getListCacheKey keys a list page by organizer, list version and parameters.
+func (s *TicketService) getListCacheKey(organizerID int, params models.TicketListParams) (string, error) {
+	version, err := s.Cache.Get(ticketListVersionKey(organizerID))
+	if err != nil {
+		version = "0"
+	}
+
+	paramsBytes, err := json.Marshal(params)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal list params: %v", err)
+	}
+	hash := sha256.Sum256(paramsBytes)
+
+	return models.TicketListCachePrefix + strconv.Itoa(organizerID) + ":" + version + ":" + hex.EncodeToString(hash[:]), nil
+}
+
+func (s *TicketService) cacheTicketList(key string, list *models.TicketList) error {
+	listBytes, err := json.Marshal(list)
+	if err != nil {
+		return fmt.Errorf("failed to marshal ticket list: %v", err)
+	}
+	return s.Cache.Set(key, string(listBytes), 30*time.Second)
+}
+
+func (s *TicketService) getCacheTicketList(key string) (*models.TicketList, error) {
+	list := &models.TicketList{}
+	listJSON, err := s.Cache.Get(key)
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal([]byte(listJSON), list)
+	if err != nil {
+		return nil, err
+	}
+
+	return list, nil
 }
@@ -2,16 +2,22 @@ package services_test
 
 import (
 	"database/sql"
+	"gowitcase/errors"
 	"gowitcase/mocks"
 	"gowitcase/models"
 	"gowitcase/services"
 	"math"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 )
 
+// testOrganizerID is the organizer the tests call the ticket service as.
+const testOrganizerID = 9
+
 func setupTest(t *testing.T) (*services.TicketService, sqlmock.Sqlmock) {
 	mockDB, mock, err := mocks.NewMockDatabase()
 	assert.NoError(t, err)
@@ -23,12 +29,28 @@ func setupTest(t *testing.T) (*services.TicketService, sqlmock.Sqlmock) {
 	return ticketService, mock
 }
 
+func newTicketRows(tickets ...*models.Ticket) *sqlmock.Rows {
+	rows := sqlmock.NewRows([]string{
+		"id", "organizer_id", "event_id", "capacity_pool_id", "name", "description", "allocation", "held", "sold", "max_per_buyer", "min_per_order", "max_per_order", "quantity_step", "seated", "price", "currency",
+		"sale_starts_at", "sale_ends_at", "created_at", "updated_at", "archived_at",
+	})
+	for _, ticket := range tickets {
+		// The total isn't stored, the fixture gets the one reading the row computes.
+		ticket.TotalAllocation = ticket.Allocation + ticket.Held + ticket.Sold
+		rows.AddRow(
+			ticket.ID, ticket.OrganizerID, ticket.EventID, ticket.CapacityPoolID, ticket.Name, ticket.Description, ticket.Allocation, ticket.Held, ticket.Sold, ticket.MaxPerBuyer,
+			ticket.MinPerOrder, ticket.MaxPerOrder, ticket.QuantityStep, ticket.Seated, ticket.Price.Amount, ticket.Price.Currency, ticket.SaleStartsAt, ticket.SaleEndsAt, ticket.CreatedAt, ticket.UpdatedAt, ticket.ArchivedAt,
+		)
+	}
+	return rows
+}
+
 func TestCreateTicket_Success(t *testing.T) {
 	ticketService, mock := setupTest(t)
 
 	mock.ExpectQuery("INSERT INTO ticket").
-		WithArgs("test", "test", 100).
-		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		WithArgs(testOrganizerID, nil, nil, "test", "test", 100, 0, 1, 0, 1, int64(0), models.DefaultCurrency, nil, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow(1, time.Now(), time.Now()))
 
 	ticket := &models.Ticket{
 		Name:        "test",
@@ -36,7 +58,7 @@ func TestCreateTicket_Success(t *testing.T) {
 		Allocation:  100,
 	}
 
-	err := ticketService.CreateTicket(ticket)
+	err := ticketService.CreateTicket(testOrganizerID, ticket)
 	assert.NoError(t, err, "failed to create ticket")
 
 	assert.Equal(t, 1, ticket.ID, "expected ticket ID 1")
@@ -54,7 +76,7 @@ func TestCreateTicket_AllocationZero(t *testing.T) {
 		Allocation:  0,
 	}
 
-	err := ticketService.CreateTicket(ticket)
+	err := ticketService.CreateTicket(testOrganizerID, ticket)
 	assert.Error(t, err, "expected error when allocation is zero")
 }
 
@@ -66,7 +88,7 @@ func TestCreateTicket_MissingName(t *testing.T) {
 		Allocation:  50,
 	}
 
-	err := ticketService.CreateTicket(ticket)
+	err := ticketService.CreateTicket(testOrganizerID, ticket)
 	assert.Error(t, err, "expected error when name is missing")
 }
 
@@ -78,7 +100,7 @@ func TestCreateTicket_MissingAllocation(t *testing.T) {
 		Description: "Missing allocation field",
 	}
 
-	err := ticketService.CreateTicket(ticket)
+	err := ticketService.CreateTicket(testOrganizerID, ticket)
 	assert.Error(t, err, "expected error when allocation is missing")
 }
 
@@ -91,10 +113,71 @@ func TestCreateTicket_NegativeAllocation(t *testing.T) {
 		Allocation:  -10,
 	}
 
-	err := ticketService.CreateTicket(ticket)
+	err := ticketService.CreateTicket(testOrganizerID, ticket)
 	assert.Error(t, err, "expected error when allocation is negative")
 }
 
+func TestCreateTicket_UnsupportedCurrency(t *testing.T) {
+	ticketService, _ := setupTest(t)
+
+	ticket := &models.Ticket{
+		Name:        "Ticket with unknown currency",
+		Description: "Unknown currency",
+		Allocation:  100,
+		Price:       models.NewMoney(1000, "XXX"),
+	}
+
+	err := ticketService.CreateTicket(testOrganizerID, ticket)
+	assert.Error(t, err, "expected error when currency is not supported")
+}
+
+func TestCreateTicket_NegativePrice(t *testing.T) {
+	ticketService, _ := setupTest(t)
+
+	ticket := &models.Ticket{
+		Name:        "Ticket with negative price",
+		Description: "Negative price",
+		Allocation:  100,
+		Price:       models.NewMoney(-1, "EUR"),
+	}
+
+	err := ticketService.CreateTicket(testOrganizerID, ticket)
+	assert.Error(t, err, "expected error when price is negative")
+}
+
+func TestCreateTicket_UnknownEvent(t *testing.T) {
+	ticketService, mock := setupTest(t)
+
+	eventID := 9
+	mock.ExpectQuery("SELECT archived_at FROM event").
+		WithArgs(eventID, testOrganizerID).
+		WillReturnError(sql.ErrNoRows)
+
+	ticket := &models.Ticket{EventID: &eventID, Name: "VIP", Allocation: 10}
+
+	err := ticketService.CreateTicket(testOrganizerID, ticket)
+	assert.EqualError(t, err, "Event 9 does not exist", "expected error when event does not exist")
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
+
+func TestCreateTicket_OtherOrganizerEvent(t *testing.T) {
+	ticketService, mock := setupTest(t)
+
+	// The event lookup is scoped to the organizer, so their events look missing.
+	eventID := 4
+	mock.ExpectQuery("SELECT archived_at FROM event WHERE id = (.+) AND organizer_id = ").
+		WithArgs(eventID, testOrganizerID).
+		WillReturnRows(sqlmock.NewRows([]string{"archived_at"}))
+
+	err := ticketService.CreateTicket(testOrganizerID, &models.Ticket{EventID: &eventID, Name: "VIP", Allocation: 10})
+	assert.EqualError(t, err, "Event 4 does not exist", "expected the event of another organizer to be rejected")
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
+
 func TestCreateTicket_NameTooLong(t *testing.T) {
 	ticketService, _ := setupTest(t)
 
@@ -109,7 +192,7 @@ func TestCreateTicket_NameTooLong(t *testing.T) {
 		Allocation:  100,
 	}
 
-	err := ticketService.CreateTicket(ticket)
+	err := ticketService.CreateTicket(testOrganizerID, ticket)
 	assert.Error(t, err, "expected error when name exceeds maximum length")
 }
 
@@ -119,8 +202,8 @@ func TestCreateTicket_AllocationMaxInt(t *testing.T) {
 	maxInt := math.MaxInt32
 
 	mock.ExpectQuery("INSERT INTO ticket").
-		WithArgs("ticket max allocation", "ticket with max allocation", maxInt).
-		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		WithArgs(testOrganizerID, nil, nil, "ticket max allocation", "ticket with max allocation", maxInt, 0, 1, 0, 1, int64(0), models.DefaultCurrency, nil, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow(1, time.Now(), time.Now()))
 
 	ticket := &models.Ticket{
 		Name:        "ticket max allocation",
@@ -128,7 +211,7 @@ func TestCreateTicket_AllocationMaxInt(t *testing.T) {
 		Allocation:  maxInt,
 	}
 
-	err := ticketService.CreateTicket(ticket)
+	err := ticketService.CreateTicket(testOrganizerID, ticket)
 	assert.NoError(t, err, "failed to create ticket with maximum allocation")
 
 	assert.Equal(t, 1, ticket.ID, "expected ticket ID 1")
@@ -148,7 +231,7 @@ func TestCreateTicket_ExcessivelyLargeAllocation(t *testing.T) {
 		Allocation:  int(excessiveAllocation),
 	}
 
-	err := ticketService.CreateTicket(ticket)
+	err := ticketService.CreateTicket(testOrganizerID, ticket)
 	assert.Error(t, err, "expected error when allocation is excessively large")
 }
 
@@ -157,7 +240,7 @@ func TestCreateTicket_EmptyRequestBody(t *testing.T) {
 
 	var ticket *models.Ticket
 
-	err := ticketService.CreateTicket(ticket)
+	err := ticketService.CreateTicket(testOrganizerID, ticket)
 	assert.Error(t, err, "expected error when ticket is nil")
 }
 
@@ -167,16 +250,18 @@ func TestGetTicket_Success(t *testing.T) {
 	ticketID := 1
 	ticket := &models.Ticket{
 		ID:          ticketID,
+		OrganizerID: testOrganizerID,
 		Name:        "test",
 		Description: "test",
 		Allocation:  100,
+		SaleStatus:  models.SaleStatusOnSale,
 	}
 
 	mock.ExpectQuery("SELECT").
-		WithArgs(ticketID).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description", "allocation"}).AddRow(ticketID, "test", "test", 100))
+		WithArgs(ticketID, testOrganizerID).
+		WillReturnRows(newTicketRows(ticket))
 
-	returnedTicket, err := ticketService.GetTicket(ticketID)
+	returnedTicket, err := ticketService.GetTicket(testOrganizerID, ticketID)
 	assert.NoError(t, err, "failed to get ticket")
 
 	assert.Equal(t, ticket, returnedTicket, "expected ticket to match")
@@ -190,21 +275,22 @@ func TestGetTicket_CacheHit(t *testing.T) {
 
 	ticket := &models.Ticket{
 		ID:          1,
+		OrganizerID: testOrganizerID,
 		Name:        "test",
 		Description: "test",
 		Allocation:  100,
+		SaleStatus:  models.SaleStatusOnSale,
 	}
 
 	mock.ExpectQuery("SELECT").
-		WithArgs(ticket.ID).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description", "allocation"}).
-			AddRow(ticket.ID, "test", "test", 100))
+		WithArgs(ticket.ID, testOrganizerID).
+		WillReturnRows(newTicketRows(ticket))
 
-	returnedTicket, err := ticketService.GetTicket(ticket.ID)
+	returnedTicket, err := ticketService.GetTicket(testOrganizerID, ticket.ID)
 	assert.NoError(t, err, "failed to get ticket")
 	assert.Equal(t, ticket, returnedTicket, "expected ticket to match")
 
-	cachedTicket, err := ticketService.GetTicket(ticket.ID)
+	cachedTicket, err := ticketService.GetTicket(testOrganizerID, ticket.ID)
 	assert.NoError(t, err, "failed to get ticket from cache")
 	assert.Equal(t, ticket, cachedTicket, "expected ticket to match")
 
@@ -218,13 +304,74 @@ func TestGetTicket_NotFound(t *testing.T) {
 	ticketID := 1
 
 	mock.ExpectQuery("SELECT").
-		WithArgs(ticketID).
+		WithArgs(ticketID, testOrganizerID).
 		WillReturnError(sql.ErrNoRows)
 
-	_, err := ticketService.GetTicket(ticketID)
+	_, err := ticketService.GetTicket(testOrganizerID, ticketID)
 	assert.Error(t, err, "expected error when ticket not found")
 }
 
+func TestGetTicket_OtherOrganizer(t *testing.T) {
+	ticketService, mock := setupTest(t)
+
+	ticket := &models.Ticket{ID: 1, OrganizerID: testOrganizerID, Name: "test", Allocation: 100}
+
+	mock.ExpectQuery("SELECT (.+) FROM ticket WHERE id = (.+) AND organizer_id = ").
+		WithArgs(ticket.ID, testOrganizerID).
+		WillReturnRows(newTicketRows(ticket))
+
+	_, err := ticketService.GetTicket(testOrganizerID, ticket.ID)
+	assert.NoError(t, err, "failed to get ticket")
+
+	// The ticket cached for its organizer isn't served to another one.
+	mock.ExpectQuery("SELECT (.+) FROM ticket WHERE id = (.+) AND organizer_id = ").
+		WithArgs(ticket.ID, testOrganizerID+1).
+		WillReturnError(sql.ErrNoRows)
+
+	_, err = ticketService.GetTicket(testOrganizerID+1, ticket.ID)
+	assert.Error(t, err, "expected error for a ticket of another organizer")
+	assert.Contains(t, err.Error(), "not found")
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
+
+func TestGetTickets_LoadsMissesInOneQuery(t *testing.T) {
+	ticketService, mock := setupTest(t)
+
+	cached := &models.Ticket{ID: 1, OrganizerID: testOrganizerID, Name: "cached", Allocation: 100}
+	mock.ExpectQuery("SELECT").
+		WithArgs(cached.ID, testOrganizerID).
+		WillReturnRows(newTicketRows(cached))
+
+	_, err := ticketService.GetTicket(testOrganizerID, cached.ID)
+	assert.NoError(t, err, "failed to get ticket")
+
+	// Only the tickets missing from the cache are queried, all at once.
+	mock.ExpectQuery("SELECT (.+) FROM ticket WHERE id = ANY(.+) AND organizer_id = ").
+		WithArgs(pq.Array([]int64{2, 3, 4}), testOrganizerID).
+		WillReturnRows(newTicketRows(
+			&models.Ticket{ID: 2, OrganizerID: testOrganizerID, Name: "second", Allocation: 50},
+			&models.Ticket{ID: 3, OrganizerID: testOrganizerID, Name: "third", Allocation: 0},
+		))
+
+	tickets, err := ticketService.GetTickets(testOrganizerID, []int{1, 2, 3, 4, 2})
+	assert.NoError(t, err, "failed to get tickets")
+	assert.Len(t, tickets, 3)
+	assert.Equal(t, "cached", tickets[1].Name)
+	assert.Equal(t, models.SaleStatusOnSale, tickets[2].SaleStatus)
+	assert.Equal(t, models.SaleStatusSoldOut, tickets[3].SaleStatus)
+	assert.NotContains(t, tickets, 4, "expected a missing ticket to be left out")
+
+	// The loaded tickets are cached for the next call.
+	tickets, err = ticketService.GetTickets(testOrganizerID, []int{2, 3})
+	assert.NoError(t, err, "failed to get tickets from cache")
+	assert.Len(t, tickets, 2)
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
+
 func TestPurchaseTicket_Success(t *testing.T) {
 	ticketService, mock := setupTest(t)
 
@@ -235,19 +382,31 @@ func TestPurchaseTicket_Success(t *testing.T) {
 	mock.ExpectBegin()
 
 	mock.ExpectQuery("SELECT").
-		WithArgs(ticketID).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description", "allocation"}).
-			AddRow(ticketID, "test", "test", initialAllocation))
+		WithArgs(ticketID, testOrganizerID).
+		WillReturnRows(newTicketRows(&models.Ticket{ID: ticketID, Name: "test", Description: "test", Allocation: initialAllocation}))
+
+	expectWaitlistDemand(mock, ticketID, 0)
 
 	mock.ExpectExec("UPDATE").
-		WithArgs(initialAllocation-quantity, ticketID).
+		WithArgs(initialAllocation-quantity, 0, quantity, ticketID).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 
+	mock.ExpectQuery("INSERT INTO purchase").
+		WithArgs(ticketID, "buyer", quantity, int64(0), int64(0), int64(0), "", nil, nil, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(7, time.Now()))
+
+	expectTicketInstances(mock, 7)
+
 	mock.ExpectCommit()
 
-	err := ticketService.PurchaseTicket(ticketID, quantity)
+	purchase, err := ticketService.PurchaseTicket(testOrganizerID, ticketID, models.PurchaseRequest{BuyerID: "buyer", Quantity: quantity})
 	assert.NoError(t, err, "failed to purchase ticket")
 
+	assert.Equal(t, 7, purchase.ID, "expected purchase ID 7")
+	assert.Equal(t, ticketID, purchase.TicketID, "expected purchase ticket ID to match")
+	assert.Equal(t, quantity, purchase.Quantity, "expected purchase quantity to match")
+	assert.Equal(t, "buyer", purchase.BuyerID, "expected purchase buyer to match")
+
 	err = mock.ExpectationsWereMet()
 	assert.NoError(t, err, "unfulfilled expectations")
 }
@@ -262,13 +421,14 @@ func TestPurchaseTicket_NotEnoughRemaining(t *testing.T) {
 	mock.ExpectBegin()
 
 	mock.ExpectQuery("SELECT").
-		WithArgs(ticketID).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description", "allocation"}).
-			AddRow(ticketID, "test", "test", initialAllocation))
+		WithArgs(ticketID, testOrganizerID).
+		WillReturnRows(newTicketRows(&models.Ticket{ID: ticketID, Name: "test", Description: "test", Allocation: initialAllocation}))
+
+	expectWaitlistDemand(mock, ticketID, 0)
 
 	mock.ExpectRollback()
 
-	err := ticketService.PurchaseTicket(ticketID, quantity)
+	_, err := ticketService.PurchaseTicket(testOrganizerID, ticketID, models.PurchaseRequest{BuyerID: "buyer", Quantity: quantity})
 	assert.Error(t, err, "expected error when not enough tickets remaining")
 	assert.Equal(t, "Not enough tickets available", err.Error(), "expected error message to match")
 
@@ -285,12 +445,12 @@ func TestPurchaseTicket_NotFound(t *testing.T) {
 	mock.ExpectBegin()
 
 	mock.ExpectQuery("SELECT").
-		WithArgs(ticketID).
+		WithArgs(ticketID, testOrganizerID).
 		WillReturnError(sql.ErrNoRows)
 
 	mock.ExpectRollback()
 
-	err := ticketService.PurchaseTicket(ticketID, quantity)
+	_, err := ticketService.PurchaseTicket(testOrganizerID, ticketID, models.PurchaseRequest{BuyerID: "buyer", Quantity: quantity})
 	assert.Error(t, err, "expected error when ticket not found")
 
 	err = mock.ExpectationsWereMet()
@@ -303,7 +463,7 @@ func TestPurchaseTicket_InvalidQuantity(t *testing.T) {
 	ticketID := 1
 	quantity := -5
 
-	err := ticketService.PurchaseTicket(ticketID, quantity)
+	_, err := ticketService.PurchaseTicket(testOrganizerID, ticketID, models.PurchaseRequest{BuyerID: "buyer", Quantity: quantity})
 	assert.Error(t, err, "expected error when quantity is negative")
 }
 
@@ -313,6 +473,780 @@ func TestPurchaseTicket_ZeroQuantity(t *testing.T) {
 	ticketID := 1
 	quantity := 0
 
-	err := ticketService.PurchaseTicket(ticketID, quantity)
+	_, err := ticketService.PurchaseTicket(testOrganizerID, ticketID, models.PurchaseRequest{BuyerID: "buyer", Quantity: quantity})
 	assert.Error(t, err, "expected error when quantity is zero")
 }
+
+func TestPurchaseTicket_MissingBuyer(t *testing.T) {
+	ticketService, _ := setupTest(t)
+
+	_, err := ticketService.PurchaseTicket(testOrganizerID, 1, models.PurchaseRequest{Quantity: 1})
+	assert.Error(t, err, "expected error when buyer is missing")
+}
+
+func TestPurchaseTicket_WithinBuyerLimit(t *testing.T) {
+	ticketService, mock := setupTest(t)
+
+	mock.ExpectBegin()
+
+	mock.ExpectQuery("SELECT").
+		WithArgs(1, testOrganizerID).
+		WillReturnRows(newTicketRows(&models.Ticket{ID: 1, Name: "test", Allocation: 100, MaxPerBuyer: 4}))
+
+	expectWaitlistDemand(mock, 1, 0)
+
+	mock.ExpectQuery("SELECT (.+) FROM purchase (.+) FROM ticket_hold").
+		WithArgs(1, "buyer", models.HoldStatusActive).
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(2))
+
+	mock.ExpectExec("UPDATE").
+		WithArgs(98, 0, 2, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	mock.ExpectQuery("INSERT INTO purchase").
+		WithArgs(1, "buyer", 2, int64(0), int64(0), int64(0), "", nil, nil, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(1, time.Now()))
+
+	expectTicketInstances(mock, 1)
+
+	mock.ExpectCommit()
+
+	_, err := ticketService.PurchaseTicket(testOrganizerID, 1, models.PurchaseRequest{BuyerID: "buyer", Quantity: 2})
+	assert.NoError(t, err, "failed to purchase ticket within buyer limit")
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
+
+func TestPurchaseTicket_ComputesTotal(t *testing.T) {
+	ticketService, mock := setupTest(t)
+
+	mock.ExpectBegin()
+
+	mock.ExpectQuery("SELECT").
+		WithArgs(1, testOrganizerID).
+		WillReturnRows(newTicketRows(&models.Ticket{ID: 1, Name: "test", Allocation: 10, Price: models.NewMoney(2550, "EUR")}))
+
+	expectWaitlistDemand(mock, 1, 0)
+
+	mock.ExpectExec("UPDATE").
+		WithArgs(7, 0, 3, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	mock.ExpectQuery("INSERT INTO purchase").
+		WithArgs(1, "buyer", 3, int64(2550), int64(0), int64(7650), "EUR", nil, nil, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(1, time.Now()))
+
+	expectTicketInstances(mock, 1)
+
+	mock.ExpectCommit()
+
+	purchase, err := ticketService.PurchaseTicket(testOrganizerID, 1, models.PurchaseRequest{BuyerID: "buyer", Quantity: 3})
+	assert.NoError(t, err, "failed to purchase ticket")
+
+	assert.Equal(t, models.NewMoney(2550, "EUR"), purchase.UnitPrice, "expected unit price of the ticket")
+	assert.Equal(t, models.NewMoney(7650, "EUR"), purchase.Total, "expected total of unit price times quantity")
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
+
+func TestPurchaseTicket_BuyerLimitExceeded(t *testing.T) {
+	ticketService, mock := setupTest(t)
+
+	mock.ExpectBegin()
+
+	mock.ExpectQuery("SELECT").
+		WithArgs(1, testOrganizerID).
+		WillReturnRows(newTicketRows(&models.Ticket{ID: 1, Name: "test", Allocation: 100, MaxPerBuyer: 4}))
+
+	expectWaitlistDemand(mock, 1, 0)
+
+	mock.ExpectQuery("SELECT (.+) FROM purchase (.+) FROM ticket_hold").
+		WithArgs(1, "buyer", models.HoldStatusActive).
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(3))
+
+	mock.ExpectRollback()
+
+	_, err := ticketService.PurchaseTicket(testOrganizerID, 1, models.PurchaseRequest{BuyerID: "buyer", Quantity: 2})
+	assert.Error(t, err, "expected error when buyer limit is exceeded")
+	assert.Equal(t, "Purchase limit is 4 tickets per buyer, 3 already purchased or held", err.Error(), "expected error message to match")
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
+
+func TestCreateTicket_NegativeMaxPerBuyer(t *testing.T) {
+	ticketService, _ := setupTest(t)
+
+	ticket := &models.Ticket{
+		Name:        "Ticket with negative limit",
+		Allocation:  100,
+		MaxPerBuyer: -1,
+	}
+
+	err := ticketService.CreateTicket(testOrganizerID, ticket)
+	assert.Error(t, err, "expected error when max per buyer is negative")
+}
+
+func TestCreateTicket_MinPerOrderNotMultipleOfStep(t *testing.T) {
+	ticketService, _ := setupTest(t)
+
+	ticket := &models.Ticket{
+		Name:         "Table of 10",
+		Allocation:   100,
+		MinPerOrder:  5,
+		QuantityStep: 10,
+	}
+
+	err := ticketService.CreateTicket(testOrganizerID, ticket)
+	assert.Error(t, err, "expected error when min per order is not a multiple of the step")
+	assert.Contains(t, err.Error(), "Field 'min_per_order' must be a multiple of 'quantity_step' (10)", "expected error message to match")
+}
+
+func TestCreateTicket_MaxPerOrderBelowMin(t *testing.T) {
+	ticketService, _ := setupTest(t)
+
+	ticket := &models.Ticket{
+		Name:        "Pairs",
+		Allocation:  100,
+		MinPerOrder: 4,
+		MaxPerOrder: 2,
+	}
+
+	err := ticketService.CreateTicket(testOrganizerID, ticket)
+	assert.Error(t, err, "expected error when max per order is less than min per order")
+}
+
+func TestPurchaseTicket_OrderQuantity(t *testing.T) {
+	tests := []struct {
+		quantity int
+		message  string
+	}{
+		{quantity: 1, message: "Ticket 1 is sold in orders of at least 2 tickets, got 1"},
+		{quantity: 3, message: "Ticket 1 is sold in multiples of 2 tickets, got 3"},
+		{quantity: 10, message: "Ticket 1 is sold in orders of at most 8 tickets, got 10"},
+	}
+
+	for _, tt := range tests {
+		ticketService, mock := setupTest(t)
+
+		mock.ExpectBegin()
+
+		mock.ExpectQuery("SELECT").
+			WithArgs(1, testOrganizerID).
+			WillReturnRows(newTicketRows(&models.Ticket{
+				ID: 1, Name: "Pairs", Allocation: 100, MinPerOrder: 2, MaxPerOrder: 8, QuantityStep: 2,
+			}))
+
+		mock.ExpectRollback()
+
+		_, err := ticketService.PurchaseTicket(testOrganizerID, 1, models.PurchaseRequest{BuyerID: "buyer", Quantity: tt.quantity})
+		assert.Error(t, err, "expected error for an order of %d tickets", tt.quantity)
+		assert.Contains(t, err.Error(), tt.message, "expected error message to match")
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err, "unexpected error")
+	}
+}
+
+func TestPurchaseTicket_SaleNotStarted(t *testing.T) {
+	ticketService, mock := setupTest(t)
+
+	saleStartsAt := time.Now().Add(time.Hour)
+
+	mock.ExpectBegin()
+
+	mock.ExpectQuery("SELECT").
+		WithArgs(1, testOrganizerID).
+		WillReturnRows(newTicketRows(&models.Ticket{ID: 1, Name: "test", Allocation: 100, SaleStartsAt: &saleStartsAt}))
+
+	mock.ExpectRollback()
+
+	_, err := ticketService.PurchaseTicket(testOrganizerID, 1, models.PurchaseRequest{BuyerID: "buyer", Quantity: 1})
+	assert.Error(t, err, "expected error when sale has not started")
+	assert.Contains(t, err.Error(), "Ticket sale has not started yet", "expected error message to match")
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
+
+func TestPurchaseTicket_SaleEnded(t *testing.T) {
+	ticketService, mock := setupTest(t)
+
+	saleEndsAt := time.Now().Add(-time.Hour)
+
+	mock.ExpectBegin()
+
+	mock.ExpectQuery("SELECT").
+		WithArgs(1, testOrganizerID).
+		WillReturnRows(newTicketRows(&models.Ticket{ID: 1, Name: "test", Allocation: 100, SaleEndsAt: &saleEndsAt}))
+
+	mock.ExpectRollback()
+
+	_, err := ticketService.PurchaseTicket(testOrganizerID, 1, models.PurchaseRequest{BuyerID: "buyer", Quantity: 1})
+	assert.Error(t, err, "expected error when sale has ended")
+	assert.Contains(t, err.Error(), "Ticket sale has ended", "expected error message to match")
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
+
+func TestCreateTicket_SaleEndsBeforeStart(t *testing.T) {
+	ticketService, _ := setupTest(t)
+
+	saleStartsAt := time.Now().Add(2 * time.Hour)
+	saleEndsAt := time.Now().Add(time.Hour)
+
+	ticket := &models.Ticket{
+		Name:         "Ticket with inverted sale window",
+		Allocation:   100,
+		SaleStartsAt: &saleStartsAt,
+		SaleEndsAt:   &saleEndsAt,
+	}
+
+	err := ticketService.CreateTicket(testOrganizerID, ticket)
+	assert.Error(t, err, "expected error when sale ends before it starts")
+}
+
+func TestCreateTicket_ReportsEveryInvalidField(t *testing.T) {
+	ticketService, _ := setupTest(t)
+
+	ticket := &models.Ticket{
+		Allocation:   -1,
+		MinPerOrder:  3,
+		QuantityStep: 2,
+		Price:        models.Money{Amount: -100, Currency: "XXX"},
+	}
+
+	err := ticketService.CreateTicket(testOrganizerID, ticket)
+	assert.Error(t, err, "expected error for an invalid ticket")
+
+	restErr, ok := err.(errors.RestError)
+	assert.True(t, ok, "expected a RestError")
+	assert.Equal(t, 400, restErr.Status)
+	assert.Equal(t, errors.CodeValidationFailed, restErr.Code)
+	assert.Equal(t, "Request has 5 invalid fields", restErr.Message)
+	assert.Equal(t, []string{"name", "allocation", "min_per_order", "price.currency", "price.amount"}, fieldPaths(restErr))
+}
+
+func TestPurchaseTicket_ReportsEveryInvalidField(t *testing.T) {
+	ticketService, _ := setupTest(t)
+
+	_, err := ticketService.PurchaseTicket(testOrganizerID, 1, models.PurchaseRequest{Quantity: 3, SeatIDs: []int{4, 5, 4}})
+	assert.Error(t, err, "expected error for an invalid purchase")
+
+	restErr, ok := err.(errors.RestError)
+	assert.True(t, ok, "expected a RestError")
+	assert.Equal(t, []string{"seat_ids[2]", "buyer_id"}, fieldPaths(restErr))
+}
+
+// fieldPaths lists the paths of the invalid fields of restErr in order.
+func fieldPaths(restErr errors.RestError) []string {
+	paths := make([]string, len(restErr.Fields))
+	for i, field := range restErr.Fields {
+		paths[i] = field.Field
+	}
+	return paths
+}
+
+func TestGetTicket_SaleStatus(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+
+	tests := []struct {
+		name     string
+		ticket   models.Ticket
+		expected string
+	}{
+		{"upcoming", models.Ticket{Allocation: 10, SaleStartsAt: &future}, models.SaleStatusUpcoming},
+		{"on sale", models.Ticket{Allocation: 10, SaleStartsAt: &past, SaleEndsAt: &future}, models.SaleStatusOnSale},
+		{"ended", models.Ticket{Allocation: 10, SaleEndsAt: &past}, models.SaleStatusEnded},
+		{"sold out", models.Ticket{Allocation: 0, SaleStartsAt: &past}, models.SaleStatusSoldOut},
+		{"archived", models.Ticket{Allocation: 10, ArchivedAt: &past}, models.SaleStatusEnded},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ticketService, mock := setupTest(t)
+
+			test.ticket.ID = 1
+			test.ticket.Name = "test"
+
+			mock.ExpectQuery("SELECT").
+				WithArgs(1, testOrganizerID).
+				WillReturnRows(newTicketRows(&test.ticket))
+
+			ticket, err := ticketService.GetTicket(testOrganizerID, 1)
+			assert.NoError(t, err, "failed to get ticket")
+			assert.Equal(t, test.expected, ticket.SaleStatus, "expected sale status to match")
+		})
+	}
+}
+
+func TestPurchaseTicket_Archived(t *testing.T) {
+	ticketService, mock := setupTest(t)
+
+	archivedAt := time.Now()
+
+	mock.ExpectBegin()
+
+	mock.ExpectQuery("SELECT").
+		WithArgs(1, testOrganizerID).
+		WillReturnRows(newTicketRows(&models.Ticket{ID: 1, Name: "test", Allocation: 100, ArchivedAt: &archivedAt}))
+
+	mock.ExpectRollback()
+
+	_, err := ticketService.PurchaseTicket(testOrganizerID, 1, models.PurchaseRequest{BuyerID: "buyer", Quantity: 1})
+	assert.Error(t, err, "expected error when ticket is archived")
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
+
+func TestUpdateTicket_Success(t *testing.T) {
+	ticketService, mock := setupTest(t)
+
+	name := "updated"
+	totalAllocation := 50
+
+	mock.ExpectBegin()
+
+	mock.ExpectQuery("SELECT (.+) FOR UPDATE").
+		WithArgs(1, testOrganizerID).
+		WillReturnRows(newTicketRows(&models.Ticket{
+			ID: 1, Name: "test", Description: "test", Allocation: 70, Sold: 30, Price: models.NewMoney(1500, "EUR"),
+		}))
+
+	mock.ExpectQuery("UPDATE ticket SET").
+		WithArgs(name, "test", totalAllocation-30, 0, 1, 0, 1, int64(1500), "EUR", nil, nil, nil, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"updated_at"}).AddRow(time.Now()))
+
+	expectEmptyWaitlist(mock, 1)
+
+	mock.ExpectCommit()
+
+	ticket, err := ticketService.UpdateTicket(testOrganizerID, 1, models.TicketUpdate{Name: &name, TotalAllocation: &totalAllocation})
+	assert.NoError(t, err, "failed to update ticket")
+
+	assert.Equal(t, name, ticket.Name, "expected name to be updated")
+	assert.Equal(t, "test", ticket.Description, "expected description to be unchanged")
+	assert.Equal(t, 20, ticket.Allocation, "expected remaining allocation to exclude sold tickets")
+	assert.Equal(t, totalAllocation, ticket.TotalAllocation, "expected total allocation to include sold tickets")
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
+
+func TestUpdateTicket_InvalidatesCache(t *testing.T) {
+	ticketService, mock := setupTest(t)
+
+	ticket := &models.Ticket{ID: 1, Name: "test", Description: "test", Allocation: 100, Price: models.NewMoney(0, "EUR")}
+	description := "updated"
+
+	mock.ExpectQuery("SELECT").
+		WithArgs(ticket.ID, testOrganizerID).
+		WillReturnRows(newTicketRows(ticket))
+
+	_, err := ticketService.GetTicket(testOrganizerID, ticket.ID)
+	assert.NoError(t, err, "failed to get ticket")
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT (.+) FOR UPDATE").
+		WithArgs(ticket.ID, testOrganizerID).
+		WillReturnRows(newTicketRows(ticket))
+	mock.ExpectQuery("UPDATE ticket SET").
+		WillReturnRows(sqlmock.NewRows([]string{"updated_at"}).AddRow(time.Now()))
+	expectEmptyWaitlist(mock, ticket.ID)
+	mock.ExpectCommit()
+
+	_, err = ticketService.UpdateTicket(testOrganizerID, ticket.ID, models.TicketUpdate{Description: &description})
+	assert.NoError(t, err, "failed to update ticket")
+
+	mock.ExpectQuery("SELECT").
+		WithArgs(ticket.ID, testOrganizerID).
+		WillReturnRows(newTicketRows(&models.Ticket{ID: 1, Name: "test", Description: description, Allocation: 100}))
+
+	returnedTicket, err := ticketService.GetTicket(testOrganizerID, ticket.ID)
+	assert.NoError(t, err, "failed to get ticket")
+	assert.Equal(t, description, returnedTicket.Description, "expected updated ticket after cache invalidation")
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
+
+func TestUpdateTicket_AllocationBelowSold(t *testing.T) {
+	ticketService, mock := setupTest(t)
+
+	totalAllocation := 10
+
+	mock.ExpectBegin()
+
+	mock.ExpectQuery("SELECT (.+) FOR UPDATE").
+		WithArgs(1, testOrganizerID).
+		WillReturnRows(newTicketRows(&models.Ticket{
+			ID: 1, Name: "test", Allocation: 70, Held: 10, Sold: 20, Price: models.NewMoney(0, "EUR"),
+		}))
+
+	mock.ExpectRollback()
+
+	_, err := ticketService.UpdateTicket(testOrganizerID, 1, models.TicketUpdate{TotalAllocation: &totalAllocation})
+	assert.Error(t, err, "expected error when allocation is less than sold tickets")
+	assert.Equal(t, "Field 'total_allocation' can't be less than the 30 tickets already sold or held", err.Error(), "expected error message to match")
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
+
+func TestUpdateTicket_RemainingAllocation(t *testing.T) {
+	ticketService, mock := setupTest(t)
+
+	allocation := 50
+
+	mock.ExpectBegin()
+
+	mock.ExpectQuery("SELECT (.+) FOR UPDATE").
+		WithArgs(1, testOrganizerID).
+		WillReturnRows(newTicketRows(&models.Ticket{
+			ID: 1, Name: "test", Allocation: 70, Held: 10, Sold: 20, Price: models.NewMoney(0, "EUR"),
+		}))
+
+	mock.ExpectQuery("UPDATE ticket SET").
+		WithArgs("test", "", allocation, 0, 1, 0, 1, int64(0), "EUR", nil, nil, nil, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"updated_at"}).AddRow(time.Now()))
+
+	expectEmptyWaitlist(mock, 1)
+
+	mock.ExpectCommit()
+
+	ticket, err := ticketService.UpdateTicket(testOrganizerID, 1, models.TicketUpdate{Allocation: &allocation})
+	assert.NoError(t, err, "failed to update ticket")
+	assert.Equal(t, allocation, ticket.Allocation, "expected allocation to be what is left to sell")
+	assert.Equal(t, 80, ticket.TotalAllocation, "expected total allocation to include held and sold tickets")
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
+
+func TestUpdateTicket_AllocationsDisagree(t *testing.T) {
+	ticketService, mock := setupTest(t)
+
+	allocation := 50
+	totalAllocation := 50
+
+	mock.ExpectBegin()
+
+	mock.ExpectQuery("SELECT (.+) FOR UPDATE").
+		WithArgs(1, testOrganizerID).
+		WillReturnRows(newTicketRows(&models.Ticket{
+			ID: 1, Name: "test", Allocation: 70, Sold: 30, Price: models.NewMoney(0, "EUR"),
+		}))
+
+	mock.ExpectRollback()
+
+	_, err := ticketService.UpdateTicket(testOrganizerID, 1, models.TicketUpdate{Allocation: &allocation, TotalAllocation: &totalAllocation})
+	assert.EqualError(t, err, "Field 'allocation' must be 'total_allocation' less the 30 tickets already sold or held")
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
+
+func TestUpdateTicket_ReportsEveryInvalidField(t *testing.T) {
+	ticketService, mock := setupTest(t)
+
+	allocation := -5
+	name := ""
+
+	mock.ExpectBegin()
+
+	mock.ExpectQuery("SELECT (.+) FOR UPDATE").
+		WithArgs(1, testOrganizerID).
+		WillReturnRows(newTicketRows(&models.Ticket{
+			ID: 1, Name: "test", Allocation: 70, Sold: 30, Price: models.NewMoney(0, "EUR"),
+		}))
+
+	mock.ExpectRollback()
+
+	_, err := ticketService.UpdateTicket(testOrganizerID, 1, models.TicketUpdate{Name: &name, Allocation: &allocation})
+	restErr, ok := err.(errors.RestError)
+	assert.True(t, ok, "expected a RestError")
+	assert.Equal(t, []errors.FieldError{
+		{Field: "allocation", Message: "Field 'allocation' must not be negative"},
+		{Field: "name", Message: "Field 'name' is required"},
+	}, restErr.Fields, "expected both invalid fields")
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
+
+func TestUpdateTicket_SeatedReportsEveryInvalidField(t *testing.T) {
+	ticketService, mock := setupTest(t)
+
+	totalAllocation := 120
+	name := ""
+
+	mock.ExpectBegin()
+
+	mock.ExpectQuery("SELECT (.+) FOR UPDATE").
+		WithArgs(1, testOrganizerID).
+		WillReturnRows(newTicketRows(&models.Ticket{
+			ID: 1, Name: "test", Allocation: 100, Seated: true, Price: models.NewMoney(0, "EUR"),
+		}))
+
+	mock.ExpectRollback()
+
+	_, err := ticketService.UpdateTicket(testOrganizerID, 1, models.TicketUpdate{Name: &name, TotalAllocation: &totalAllocation})
+	restErr, ok := err.(errors.RestError)
+	assert.True(t, ok, "expected a RestError")
+	assert.Equal(t, []errors.FieldError{
+		{Field: "total_allocation", Message: "Field 'total_allocation' of ticket 1 follows its seat map"},
+		{Field: "name", Message: "Field 'name' is required"},
+	}, restErr.Fields, "expected both invalid fields")
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
+
+func TestCreateTicket_AllocationsDisagreeWithOtherFields(t *testing.T) {
+	ticketService, _ := setupTest(t)
+
+	err := ticketService.CreateTicket(testOrganizerID, &models.Ticket{Allocation: 50, TotalAllocation: 80, MaxPerBuyer: -1})
+	restErr, ok := err.(errors.RestError)
+	assert.True(t, ok, "expected a RestError")
+	assert.Equal(t, []errors.FieldError{
+		{Field: "allocation", Message: "Field 'allocation' must equal 'total_allocation' on a new ticket"},
+		{Field: "name", Message: "Field 'name' is required"},
+		{Field: "max_per_buyer", Message: "Field 'max_per_buyer' must not be negative"},
+	}, restErr.Fields, "expected every invalid field")
+}
+
+func TestUpdateTicket_ReadWriteRoundTrip(t *testing.T) {
+	ticketService, mock := setupTest(t)
+
+	stored := &models.Ticket{
+		ID: 1, OrganizerID: testOrganizerID, Name: "test", Description: "test", Allocation: 70, Held: 10, Sold: 20,
+		Price: models.NewMoney(1500, "EUR"),
+	}
+
+	mock.ExpectQuery("SELECT (.+) FROM ticket WHERE id = ").
+		WithArgs(stored.ID, testOrganizerID).
+		WillReturnRows(newTicketRows(stored))
+
+	read, err := ticketService.GetTicket(testOrganizerID, stored.ID)
+	assert.NoError(t, err, "failed to get ticket")
+
+	// Write back every allocation field exactly as it was read, like a PUT of the
+	// body of a GET does.
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT (.+) FOR UPDATE").
+		WithArgs(stored.ID, testOrganizerID).
+		WillReturnRows(newTicketRows(stored))
+	mock.ExpectQuery("UPDATE ticket SET").
+		WithArgs("test", "test", stored.Allocation, 0, 1, 0, 1, int64(1500), "EUR", nil, nil, nil, stored.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"updated_at"}).AddRow(time.Now()))
+	expectEmptyWaitlist(mock, stored.ID)
+	mock.ExpectCommit()
+
+	written, err := ticketService.UpdateTicket(testOrganizerID, stored.ID, models.TicketUpdate{
+		Allocation:      &read.Allocation,
+		TotalAllocation: &read.TotalAllocation,
+	})
+	assert.NoError(t, err, "failed to update ticket")
+	assert.Equal(t, read.Allocation, written.Allocation, "expected allocation to survive the round trip")
+	assert.Equal(t, read.TotalAllocation, written.TotalAllocation, "expected total allocation to survive the round trip")
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
+
+func TestUpdateTicket_InvalidName(t *testing.T) {
+	ticketService, mock := setupTest(t)
+
+	name := ""
+
+	mock.ExpectBegin()
+
+	mock.ExpectQuery("SELECT (.+) FOR UPDATE").
+		WithArgs(1, testOrganizerID).
+		WillReturnRows(newTicketRows(&models.Ticket{ID: 1, Name: "test", Allocation: 70}))
+
+	mock.ExpectRollback()
+
+	_, err := ticketService.UpdateTicket(testOrganizerID, 1, models.TicketUpdate{Name: &name})
+	assert.Error(t, err, "expected error when name is emptied")
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
+
+func TestUpdateTicket_NotFound(t *testing.T) {
+	ticketService, mock := setupTest(t)
+
+	mock.ExpectBegin()
+
+	mock.ExpectQuery("SELECT (.+) FOR UPDATE").
+		WithArgs(1, testOrganizerID).
+		WillReturnError(sql.ErrNoRows)
+
+	mock.ExpectRollback()
+
+	_, err := ticketService.UpdateTicket(testOrganizerID, 1, models.TicketUpdate{})
+	assert.Error(t, err, "expected error when ticket not found")
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
+
+func TestArchiveTicket_Success(t *testing.T) {
+	ticketService, mock := setupTest(t)
+
+	mock.ExpectQuery("UPDATE ticket SET archived_at").
+		WithArgs(1, testOrganizerID).
+		WillReturnRows(sqlmock.NewRows([]string{"archived_at", "event_id"}).AddRow(time.Now(), nil))
+
+	err := ticketService.ArchiveTicket(testOrganizerID, 1)
+	assert.NoError(t, err, "failed to archive ticket")
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
+
+func TestArchiveTicket_NotFound(t *testing.T) {
+	ticketService, mock := setupTest(t)
+
+	mock.ExpectQuery("UPDATE ticket SET archived_at").
+		WithArgs(1, testOrganizerID).
+		WillReturnError(sql.ErrNoRows)
+
+	err := ticketService.ArchiveTicket(testOrganizerID, 1)
+	assert.Error(t, err, "expected error when ticket not found")
+}
+
+func TestListTickets_Success(t *testing.T) {
+	ticketService, mock := setupTest(t)
+
+	first := &models.Ticket{ID: 1, OrganizerID: testOrganizerID, Name: "first", Description: "first", Allocation: 10, SaleStatus: models.SaleStatusOnSale, CreatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	second := &models.Ticket{ID: 2, OrganizerID: testOrganizerID, Name: "second", Description: "second", Allocation: 20, SaleStatus: models.SaleStatusOnSale, CreatedAt: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)}
+	third := &models.Ticket{ID: 3, OrganizerID: testOrganizerID, Name: "third", Description: "third", Allocation: 30, SaleStatus: models.SaleStatusOnSale, CreatedAt: time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC)}
+
+	mock.ExpectQuery(`SELECT (.+) FROM ticket WHERE organizer_id = \$1 AND archived_at IS NULL AND allocation >= \$2 ORDER BY created_at DESC, id DESC LIMIT \$3`).
+		WithArgs(testOrganizerID, 5, 3).
+		WillReturnRows(newTicketRows(third, second, first))
+
+	list, err := ticketService.ListTickets(testOrganizerID, models.TicketListParams{
+		MinAllocation: 5,
+		Sort:          "created_at",
+		Order:         "desc",
+		Limit:         2,
+	})
+	assert.NoError(t, err, "failed to list tickets")
+
+	assert.Equal(t, []models.Ticket{*third, *second}, list.Tickets, "expected first page of tickets")
+	assert.NotEmpty(t, list.NextCursor, "expected next cursor")
+
+	mock.ExpectQuery(`SELECT (.+) FROM ticket WHERE organizer_id = \$1 AND archived_at IS NULL AND allocation >= \$2 AND \(created_at, id\) < \(\$3, \$4\) ORDER BY created_at DESC, id DESC LIMIT \$5`).
+		WithArgs(testOrganizerID, 5, second.CreatedAt, second.ID, 3).
+		WillReturnRows(newTicketRows(first))
+
+	list, err = ticketService.ListTickets(testOrganizerID, models.TicketListParams{
+		MinAllocation: 5,
+		Sort:          "created_at",
+		Order:         "desc",
+		Limit:         2,
+		Cursor:        list.NextCursor,
+	})
+	assert.NoError(t, err, "failed to list tickets")
+
+	assert.Equal(t, []models.Ticket{*first}, list.Tickets, "expected second page of tickets")
+	assert.Empty(t, list.NextCursor, "expected no next cursor on last page")
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
+
+func TestListTickets_NameFilter(t *testing.T) {
+	ticketService, mock := setupTest(t)
+
+	mock.ExpectQuery(`SELECT (.+) FROM ticket WHERE organizer_id = \$1 AND archived_at IS NULL AND name ILIKE \$2 ORDER BY id ASC LIMIT \$3`).
+		WithArgs(testOrganizerID, `%50\%%`, models.TicketListDefaultLimit+1).
+		WillReturnRows(newTicketRows())
+
+	list, err := ticketService.ListTickets(testOrganizerID, models.TicketListParams{Name: "50%"})
+	assert.NoError(t, err, "failed to list tickets")
+	assert.Empty(t, list.Tickets, "expected no tickets")
+	assert.NotNil(t, list.Tickets, "expected empty list instead of nil")
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
+
+func TestListTickets_CacheHit(t *testing.T) {
+	ticketService, mock := setupTest(t)
+
+	ticket := &models.Ticket{ID: 1, Name: "test", Description: "test", Allocation: 100}
+
+	mock.ExpectQuery("SELECT").
+		WillReturnRows(newTicketRows(ticket))
+
+	params := models.TicketListParams{Sort: "name"}
+
+	list, err := ticketService.ListTickets(testOrganizerID, params)
+	assert.NoError(t, err, "failed to list tickets")
+
+	cachedList, err := ticketService.ListTickets(testOrganizerID, params)
+	assert.NoError(t, err, "failed to list tickets from cache")
+	assert.Equal(t, list, cachedList, "expected cached list to match")
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
+
+func TestListTickets_CacheInvalidatedOnCreate(t *testing.T) {
+	ticketService, mock := setupTest(t)
+
+	mock.ExpectQuery("SELECT").
+		WillReturnRows(newTicketRows())
+
+	_, err := ticketService.ListTickets(testOrganizerID, models.TicketListParams{})
+	assert.NoError(t, err, "failed to list tickets")
+
+	mock.ExpectQuery("INSERT INTO ticket").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow(1, time.Now(), time.Now()))
+
+	err = ticketService.CreateTicket(testOrganizerID, &models.Ticket{Name: "test", Description: "test", Allocation: 100})
+	assert.NoError(t, err, "failed to create ticket")
+
+	mock.ExpectQuery("SELECT").
+		WillReturnRows(newTicketRows(&models.Ticket{ID: 1, Name: "test", Description: "test", Allocation: 100}))
+
+	list, err := ticketService.ListTickets(testOrganizerID, models.TicketListParams{})
+	assert.NoError(t, err, "failed to list tickets")
+	assert.Len(t, list.Tickets, 1, "expected list to include the new ticket")
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
+
+func TestListTickets_InvalidSort(t *testing.T) {
+	ticketService, _ := setupTest(t)
+
+	_, err := ticketService.ListTickets(testOrganizerID, models.TicketListParams{Sort: "description"})
+	assert.Error(t, err, "expected error when sort field is not supported")
+}
+
+func TestListTickets_CursorSortMismatch(t *testing.T) {
+	ticketService, mock := setupTest(t)
+
+	mock.ExpectQuery("SELECT").
+		WillReturnRows(newTicketRows(
+			&models.Ticket{ID: 1, Name: "a"},
+			&models.Ticket{ID: 2, Name: "b"},
+		))
+
+	list, err := ticketService.ListTickets(testOrganizerID, models.TicketListParams{Sort: "name", Limit: 1})
+	assert.NoError(t, err, "failed to list tickets")
+
+	_, err = ticketService.ListTickets(testOrganizerID, models.TicketListParams{Sort: "id", Cursor: list.NextCursor})
+	assert.Error(t, err, "expected error when cursor was issued for another sort")
+}
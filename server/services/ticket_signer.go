@@ -0,0 +1,128 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"gowitcase/errors"
+	"strings"
+)
+
+// signingKeyMinLength is the shortest secret accepted for a signing key, the output
+// size of SHA-256.
+const signingKeyMinLength = 32
+
+// TicketSigner signs the codes of ticket instances with HMAC-SHA256, so the tokens
+// scanned at the door can't be forged. Tokens are signed with the active key and
+// verified with any configured key. A key is rotated by adding a new one, making it
+// active, and dropping the old one once the tokens it signed no longer need to scan.
+type TicketSigner struct {
+	activeKeyID string
+	keys        map[string][]byte
+}
+
+// ParseSigningKeys reads signing keys in the "id:secret,id:secret" form of the
+// TICKET_SIGNING_KEYS environment variable. A JSON object of ids to secrets, the
+// form Secrets Manager stores key/value secrets in, is read as well.
+func ParseSigningKeys(value string) (map[string][]byte, error) {
+	if strings.HasPrefix(strings.TrimSpace(value), "{") {
+		return parseSigningKeysJSON(value)
+	}
+
+	keys := make(map[string][]byte)
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		id, secret, found := strings.Cut(entry, ":")
+		if !found || id == "" {
+			return nil, fmt.Errorf("signing key %q must be in the id:secret form", entry)
+		}
+		if strings.Contains(id, ".") {
+			return nil, fmt.Errorf("signing key id %q must not contain a dot", id)
+		}
+		if _, ok := keys[id]; ok {
+			return nil, fmt.Errorf("signing key %q is configured more than once", id)
+		}
+
+		keys[id] = []byte(secret)
+	}
+
+	return keys, nil
+}
+
+func parseSigningKeysJSON(value string) (map[string][]byte, error) {
+	var secrets map[string]string
+	if err := json.Unmarshal([]byte(value), &secrets); err != nil {
+		return nil, fmt.Errorf("signing keys must be a JSON object of key ids to secrets: %v", err)
+	}
+
+	keys := make(map[string][]byte, len(secrets))
+	for id, secret := range secrets {
+		if id == "" || strings.Contains(id, ".") {
+			return nil, fmt.Errorf("signing key id %q must not be empty or contain a dot", id)
+		}
+		keys[id] = []byte(secret)
+	}
+
+	return keys, nil
+}
+
+// NewTicketSigner signs with the key activeKeyID, which can be left empty when there
+// is a single key.
+func NewTicketSigner(keys map[string][]byte, activeKeyID string) (*TicketSigner, error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no signing keys configured")
+	}
+
+	for id, secret := range keys {
+		if len(secret) < signingKeyMinLength {
+			return nil, fmt.Errorf("signing key %q must be at least %d bytes", id, signingKeyMinLength)
+		}
+		if activeKeyID == "" && len(keys) == 1 {
+			activeKeyID = id
+		}
+	}
+
+	if _, ok := keys[activeKeyID]; !ok {
+		return nil, fmt.Errorf("active signing key %q is not configured", activeKeyID)
+	}
+
+	return &TicketSigner{activeKeyID: activeKeyID, keys: keys}, nil
+}
+
+// Sign returns the token of code: the code, the ID of the signing key and the
+// signature, separated by dots.
+func (s *TicketSigner) Sign(code string) string {
+	return code + "." + s.activeKeyID + "." + s.signature(s.activeKeyID, code)
+}
+
+// Verify checks the signature of token and returns the code it was signed for.
+func (s *TicketSigner) Verify(token string) (string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", errors.NewRestError("Ticket token is invalid", 400).WithCode(errors.CodeTicketNotValid)
+	}
+
+	code, keyID, signature := parts[0], parts[1], parts[2]
+	if _, ok := s.keys[keyID]; !ok {
+		return "", errors.NewRestError("Ticket token is invalid", 400).WithCode(errors.CodeTicketNotValid)
+	}
+
+	if !hmac.Equal([]byte(signature), []byte(s.signature(keyID, code))) {
+		return "", errors.NewRestError("Ticket token is invalid", 400).WithCode(errors.CodeTicketNotValid)
+	}
+
+	return code, nil
+}
+
+// signature covers the key ID too, so a token can't be moved to another key.
+func (s *TicketSigner) signature(keyID string, code string) string {
+	mac := hmac.New(sha256.New, s.keys[keyID])
+	mac.Write([]byte(keyID + "." + code))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
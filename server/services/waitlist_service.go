@@ -0,0 +1,284 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"gowitcase/db"
+	"gowitcase/errors"
+	"gowitcase/models"
+	"log"
+)
+
+type WaitlistService struct {
+	DB    db.DatabaseInterface
+	Cache db.RedisInterface
+}
+
+func NewWaitlistService(db db.DatabaseInterface, cache db.RedisInterface) *WaitlistService {
+	return &WaitlistService{DB: db, Cache: cache}
+}
+
+// JoinWaitlist queues the buyer for a sold out ticket, that is one with no tickets
+// left that aren't kept for the buyers already waiting. A buyer can only wait once
+// per ticket at a time, and the per-buyer limit applies as if the tickets were
+// bought right away.
+func (s *WaitlistService) JoinWaitlist(organizerID int, ticketID int, request models.WaitlistRequest) (*models.WaitlistEntry, error) {
+	_, err := validatePurchaseRequest(request.BuyerID, request.Quantity, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.DB.BeginTransaction()
+	if err != nil {
+		log.Printf("Failed to start transaction: %v", err)
+		return nil, fmt.Errorf("failed to start transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	// The ticket lock also serializes joins, so the duplicate check below can't race.
+	ticket, err := lockOrganizerTicket(tx, organizerID, ticketID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkSaleOpen(ticket)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkOrderQuantity(ticket, request.Quantity)
+	if err != nil {
+		return nil, err
+	}
+
+	if ticket.Seated {
+		return nil, errors.NewRestError("Seated tickets have no waitlist, free seats are on the seat map", 400)
+	}
+
+	available, err := availableAllocation(tx, ticket)
+	if err != nil {
+		return nil, err
+	}
+	if available > 0 {
+		return nil, errors.NewRestError(fmt.Sprintf("Ticket is not sold out, %d tickets are available", available), 400)
+	}
+
+	// Tickets only come back to the allocation when they're refunded or released, so
+	// an entry for more than the ticket has in total would wait forever.
+	if request.Quantity > fillableQuantity(ticket) {
+		return nil, errors.NewRestError(
+			fmt.Sprintf("Ticket %d has %d tickets in total, a waitlist entry can't ask for %d", ticketID, fillableQuantity(ticket), request.Quantity), 400,
+		).WithCode(errors.CodeInvalidQuantity).WithDetail("total_allocation", fillableQuantity(ticket))
+	}
+
+	err = checkBuyerLimit(tx, ticket, request.BuyerID, request.Quantity)
+	if err != nil {
+		return nil, err
+	}
+
+	var existingID int
+	err = tx.QueryRow(
+		"SELECT id FROM waitlist_entry WHERE ticket_id = $1 AND buyer_id = $2 AND status = $3",
+		ticketID, request.BuyerID, models.WaitlistStatusWaiting,
+	).Scan(&existingID)
+	if err == nil {
+		return nil, errors.NewRestError(fmt.Sprintf("Buyer is already on the waitlist of ticket %d", ticketID), 409)
+	}
+	if err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to get waitlist entry: %v", err)
+	}
+
+	entry := &models.WaitlistEntry{
+		TicketID: ticketID,
+		BuyerID:  request.BuyerID,
+		Quantity: request.Quantity,
+		Status:   models.WaitlistStatusWaiting,
+	}
+	err = tx.QueryRow(
+		"INSERT INTO waitlist_entry (ticket_id, buyer_id, quantity, status) VALUES ($1, $2, $3, $4) "+
+			"RETURNING id, created_at, updated_at",
+		entry.TicketID, entry.BuyerID, entry.Quantity, entry.Status,
+	).Scan(&entry.ID, &entry.CreatedAt, &entry.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create waitlist entry: %v", err)
+	}
+
+	entry.Position, err = waitlistPosition(tx, entry)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	return entry, nil
+}
+
+// GetWaitlistEntry returns the entry with its position in the queue while it's
+// waiting, or the state of its offer once it got one.
+func (s *WaitlistService) GetWaitlistEntry(organizerID int, id int) (*models.WaitlistEntry, error) {
+	entry := &models.WaitlistEntry{}
+	var holdStatus sql.NullString
+
+	err := s.DB.QueryRow(
+		"SELECT w.id, w.ticket_id, w.buyer_id, w.quantity, w.status, w.hold_id, h.status, h.expires_at, w.created_at, w.updated_at "+
+			"FROM waitlist_entry w LEFT JOIN ticket_hold h ON h.id = w.hold_id "+
+			"WHERE w.id = $1 AND w.ticket_id IN (SELECT id FROM ticket WHERE organizer_id = $2)",
+		id, organizerID,
+	).Scan(
+		&entry.ID, &entry.TicketID, &entry.BuyerID, &entry.Quantity, &entry.Status, &entry.HoldID, &holdStatus,
+		&entry.OfferExpiresAt, &entry.CreatedAt, &entry.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NewRestError(fmt.Sprintf("Waitlist entry %d not found", id), 404)
+		}
+		return nil, fmt.Errorf("failed to get waitlist entry: %v", err)
+	}
+
+	switch entry.Status {
+	case models.WaitlistStatusWaiting:
+		entry.Position, err = waitlistPosition(s.DB, entry)
+		if err != nil {
+			return nil, err
+		}
+	case models.WaitlistStatusOffered:
+		entry.Status = offerStatus(holdStatus.String)
+	}
+
+	return entry, nil
+}
+
+// LeaveWaitlist takes a waiting entry out of the queue. An offer is declined by
+// releasing its hold instead.
+func (s *WaitlistService) LeaveWaitlist(organizerID int, id int) error {
+	var status string
+	err := s.DB.QueryRow(
+		"SELECT status FROM waitlist_entry WHERE id = $1 AND ticket_id IN (SELECT id FROM ticket WHERE organizer_id = $2)",
+		id, organizerID,
+	).Scan(&status)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errors.NewRestError(fmt.Sprintf("Waitlist entry %d not found", id), 404)
+		}
+		return fmt.Errorf("failed to get waitlist entry: %v", err)
+	}
+
+	if status != models.WaitlistStatusWaiting {
+		return errors.NewRestError(fmt.Sprintf("Waitlist entry is already %s", status), 400)
+	}
+
+	_, err = s.DB.Exec(
+		"UPDATE waitlist_entry SET status = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2 AND status = $3",
+		models.WaitlistStatusLeft, id, models.WaitlistStatusWaiting,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update waitlist entry: %v", err)
+	}
+
+	return nil
+}
+
+// offerToWaitlist offers the available tickets of the locked ticket to waiting buyers
+// in the order they joined. Each offer is a hold that expires after
+// WaitlistOfferDuration and then comes back here for the next buyer. The queue is
+// strict: while the first buyer wants more tickets than are available, nobody
+// behind them gets an offer, and availableAllocation keeps the tickets from new
+// buyers until enough are free. Entries for more tickets than the ticket has in
+// total, after its allocation was lowered, can't be filled and are passed over.
+// Seated tickets have no waitlist.
+func offerToWaitlist(tx *sql.Tx, ticket *models.Ticket) error {
+	if ticket.Seated || ticket.Allocation == 0 || checkSaleOpen(ticket) != nil {
+		return nil
+	}
+
+	// The head of the queue is waited for when another transaction has it locked,
+	// skipping it would hand its tickets to a buyer who joined later.
+	offered := false
+	for ticket.Allocation > 0 {
+		entry := &models.WaitlistEntry{}
+		err := tx.QueryRow(
+			"SELECT id, buyer_id, quantity FROM waitlist_entry WHERE ticket_id = $1 AND status = $2 AND quantity <= $3 "+
+				"ORDER BY id LIMIT 1 FOR UPDATE",
+			ticket.ID, models.WaitlistStatusWaiting, fillableQuantity(ticket),
+		).Scan(&entry.ID, &entry.BuyerID, &entry.Quantity)
+		if err == sql.ErrNoRows {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get waitlist entry: %v", err)
+		}
+
+		if entry.Quantity > ticket.Allocation {
+			break
+		}
+
+		// A full pool leaves the entry waiting for tickets of the pool to free up.
+		err = takePoolCapacity(tx, ticket.CapacityPoolID, entry.Quantity)
+		if _, ok := err.(errors.RestError); ok {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		ticket.Allocation -= entry.Quantity
+		ticket.Held += entry.Quantity
+
+		hold, err := insertHold(tx, ticket, entry.BuyerID, entry.Quantity, nil, models.WaitlistOfferDuration)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(
+			"UPDATE waitlist_entry SET status = $1, hold_id = $2, updated_at = CURRENT_TIMESTAMP WHERE id = $3",
+			models.WaitlistStatusOffered, hold.ID, entry.ID,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to update waitlist entry: %v", err)
+		}
+		offered = true
+	}
+
+	if !offered {
+		return nil
+	}
+	return updateTicketInventory(tx, ticket)
+}
+
+// fillableQuantity is the most tickets a waitlist entry of the ticket can ever be
+// offered: every ticket that is left, held or sold, should all of them come back.
+func fillableQuantity(ticket *models.Ticket) int {
+	return ticket.Allocation + ticket.Held + ticket.Sold
+}
+
+type queryRower interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+func waitlistPosition(q queryRower, entry *models.WaitlistEntry) (int, error) {
+	var position int
+	err := q.QueryRow(
+		"SELECT COUNT(*) FROM waitlist_entry WHERE ticket_id = $1 AND status = $2 AND id <= $3",
+		entry.TicketID, models.WaitlistStatusWaiting, entry.ID,
+	).Scan(&position)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get waitlist position: %v", err)
+	}
+	return position, nil
+}
+
+func offerStatus(holdStatus string) string {
+	switch holdStatus {
+	case models.HoldStatusConfirmed:
+		return models.WaitlistStatusFulfilled
+	case models.HoldStatusReleased:
+		return models.WaitlistStatusDeclined
+	case models.HoldStatusExpired:
+		return models.WaitlistStatusExpired
+	default:
+		return models.WaitlistStatusOffered
+	}
+}
@@ -0,0 +1,383 @@
+package services_test
+
+import (
+	"database/sql"
+	"gowitcase/errors"
+	"gowitcase/mocks"
+	"gowitcase/models"
+	"gowitcase/services"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupWaitlistTest(t *testing.T) (*services.WaitlistService, sqlmock.Sqlmock) {
+	mockDB, mock, err := mocks.NewMockDatabase()
+	assert.NoError(t, err)
+
+	waitlistService := services.NewWaitlistService(mockDB, mocks.NewMockRedis())
+
+	return waitlistService, mock
+}
+
+// expectEmptyWaitlist expects the waitlist lookup that follows every change giving
+// tickets back to the allocation, with nobody waiting.
+func expectEmptyWaitlist(mock sqlmock.Sqlmock, ticketID int) {
+	mock.ExpectQuery("SELECT id, buyer_id, quantity FROM waitlist_entry").
+		WithArgs(ticketID, models.WaitlistStatusWaiting, sqlmock.AnyArg()).
+		WillReturnError(sql.ErrNoRows)
+}
+
+// expectWaitlistDemand expects the count of the tickets waiting buyers asked for,
+// which purchases and holds can't take.
+func expectWaitlistDemand(mock sqlmock.Sqlmock, ticketID int, quantity int) {
+	mock.ExpectQuery("SELECT COALESCE\\(SUM\\(quantity\\), 0\\) FROM waitlist_entry").
+		WithArgs(ticketID, models.WaitlistStatusWaiting, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(quantity))
+}
+
+func TestJoinWaitlist_Success(t *testing.T) {
+	waitlistService, mock := setupWaitlistTest(t)
+
+	mock.ExpectBegin()
+
+	mock.ExpectQuery("SELECT (.+) FROM ticket WHERE id = (.+) FOR UPDATE").
+		WithArgs(1, testOrganizerID).
+		WillReturnRows(newTicketRows(&models.Ticket{ID: 1, Name: "test", Allocation: 1, Sold: 99}))
+
+	expectWaitlistDemand(mock, 1, 3)
+
+	mock.ExpectQuery("SELECT id FROM waitlist_entry").
+		WithArgs(1, "buyer", models.WaitlistStatusWaiting).
+		WillReturnError(sql.ErrNoRows)
+
+	mock.ExpectQuery("INSERT INTO waitlist_entry").
+		WithArgs(1, "buyer", 2, models.WaitlistStatusWaiting).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow(4, time.Now(), time.Now()))
+
+	mock.ExpectQuery("SELECT COUNT(.+) FROM waitlist_entry").
+		WithArgs(1, models.WaitlistStatusWaiting, 4).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+
+	mock.ExpectCommit()
+
+	entry, err := waitlistService.JoinWaitlist(testOrganizerID, 1, models.WaitlistRequest{BuyerID: "buyer", Quantity: 2})
+	assert.NoError(t, err, "failed to join waitlist")
+	assert.Equal(t, 4, entry.ID, "expected waitlist entry ID 4")
+	assert.Equal(t, models.WaitlistStatusWaiting, entry.Status, "expected entry to be waiting")
+	assert.Equal(t, 3, entry.Position, "expected third position in the queue")
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
+
+func TestJoinWaitlist_NotSoldOut(t *testing.T) {
+	waitlistService, mock := setupWaitlistTest(t)
+
+	mock.ExpectBegin()
+
+	mock.ExpectQuery("SELECT (.+) FROM ticket WHERE id = (.+) FOR UPDATE").
+		WithArgs(1, testOrganizerID).
+		WillReturnRows(newTicketRows(&models.Ticket{ID: 1, Name: "test", Allocation: 5}))
+
+	expectWaitlistDemand(mock, 1, 0)
+
+	mock.ExpectRollback()
+
+	_, err := waitlistService.JoinWaitlist(testOrganizerID, 1, models.WaitlistRequest{BuyerID: "buyer", Quantity: 2})
+	assert.Equal(t, errors.NewRestError("Ticket is not sold out, 5 tickets are available", 400), err, "expected not sold out error")
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
+
+func TestJoinWaitlist_MoreThanAvailable(t *testing.T) {
+	waitlistService, mock := setupWaitlistTest(t)
+
+	mock.ExpectBegin()
+
+	// Asking for more than is left doesn't make the ticket sold out.
+	mock.ExpectQuery("SELECT (.+) FROM ticket WHERE id = (.+) FOR UPDATE").
+		WithArgs(1, testOrganizerID).
+		WillReturnRows(newTicketRows(&models.Ticket{ID: 1, Name: "test", Allocation: 1, Sold: 99}))
+
+	expectWaitlistDemand(mock, 1, 0)
+
+	mock.ExpectRollback()
+
+	_, err := waitlistService.JoinWaitlist(testOrganizerID, 1, models.WaitlistRequest{BuyerID: "buyer", Quantity: 1000})
+	assert.Equal(t, errors.NewRestError("Ticket is not sold out, 1 tickets are available", 400), err, "expected not sold out error")
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
+
+func TestJoinWaitlist_NeverFillable(t *testing.T) {
+	waitlistService, mock := setupWaitlistTest(t)
+
+	mock.ExpectBegin()
+
+	mock.ExpectQuery("SELECT (.+) FROM ticket WHERE id = (.+) FOR UPDATE").
+		WithArgs(1, testOrganizerID).
+		WillReturnRows(newTicketRows(&models.Ticket{ID: 1, Name: "test", Held: 2, Sold: 98}))
+
+	mock.ExpectRollback()
+
+	_, err := waitlistService.JoinWaitlist(testOrganizerID, 1, models.WaitlistRequest{BuyerID: "buyer", Quantity: 101})
+	restErr, ok := err.(errors.RestError)
+	assert.True(t, ok, "expected a RestError")
+	assert.Equal(t, errors.CodeInvalidQuantity, restErr.Code, "expected an entry for more than the ticket has to be rejected")
+	assert.Equal(t, 100, restErr.Details["total_allocation"])
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
+
+func TestPurchaseTicket_UnfillableEntriesKeepNothing(t *testing.T) {
+	ticketService, mock := setupTest(t)
+
+	mock.ExpectBegin()
+
+	// Only the entries for at most the 100 tickets of the ticket are counted.
+	mock.ExpectQuery("SELECT (.+) FROM ticket WHERE id = (.+) FOR UPDATE").
+		WithArgs(1, testOrganizerID).
+		WillReturnRows(newTicketRows(&models.Ticket{ID: 1, Name: "test", Allocation: 3, Sold: 97}))
+
+	mock.ExpectQuery("SELECT COALESCE\\(SUM\\(quantity\\), 0\\) FROM waitlist_entry WHERE (.+) AND quantity <= ").
+		WithArgs(1, models.WaitlistStatusWaiting, 100).
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(0))
+
+	expectNoPricingRule(mock, 1)
+
+	mock.ExpectExec("UPDATE ticket SET allocation").
+		WithArgs(1, 0, 99, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	mock.ExpectQuery("INSERT INTO purchase").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(7, time.Now()))
+
+	expectTicketInstances(mock, 7)
+
+	mock.ExpectCommit()
+
+	_, err := ticketService.PurchaseTicket(testOrganizerID, 1, models.PurchaseRequest{BuyerID: "buyer", Quantity: 2})
+	assert.NoError(t, err, "expected the tickets not to be kept for entries that can't be filled")
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
+
+func TestJoinWaitlist_TicketsKeptForWaitlist(t *testing.T) {
+	waitlistService, mock := setupWaitlistTest(t)
+
+	mock.ExpectBegin()
+
+	// Three tickets are free, but the buyer ahead waits for five of them.
+	mock.ExpectQuery("SELECT (.+) FROM ticket WHERE id = (.+) FOR UPDATE").
+		WithArgs(1, testOrganizerID).
+		WillReturnRows(newTicketRows(&models.Ticket{ID: 1, Name: "test", Allocation: 3, Sold: 97}))
+
+	expectWaitlistDemand(mock, 1, 5)
+
+	mock.ExpectQuery("SELECT id FROM waitlist_entry").
+		WithArgs(1, "buyer", models.WaitlistStatusWaiting).
+		WillReturnError(sql.ErrNoRows)
+
+	mock.ExpectQuery("INSERT INTO waitlist_entry").
+		WithArgs(1, "buyer", 2, models.WaitlistStatusWaiting).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow(5, time.Now(), time.Now()))
+
+	mock.ExpectQuery("SELECT COUNT(.+) FROM waitlist_entry").
+		WithArgs(1, models.WaitlistStatusWaiting, 5).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+
+	mock.ExpectCommit()
+
+	entry, err := waitlistService.JoinWaitlist(testOrganizerID, 1, models.WaitlistRequest{BuyerID: "buyer", Quantity: 2})
+	assert.NoError(t, err, "expected the buyer to queue behind the waiting buyer")
+	assert.Equal(t, 2, entry.Position, "expected second position in the queue")
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
+
+func TestPurchaseTicket_CantJumpWaitlist(t *testing.T) {
+	ticketService, mock := setupTest(t)
+
+	mock.ExpectBegin()
+
+	// The three freed tickets are kept for the buyer waiting for five.
+	mock.ExpectQuery("SELECT (.+) FROM ticket WHERE id = (.+) FOR UPDATE").
+		WithArgs(1, testOrganizerID).
+		WillReturnRows(newTicketRows(&models.Ticket{ID: 1, Name: "test", Allocation: 3, Sold: 97}))
+
+	expectWaitlistDemand(mock, 1, 5)
+
+	mock.ExpectRollback()
+
+	_, err := ticketService.PurchaseTicket(testOrganizerID, 1, models.PurchaseRequest{BuyerID: "buyer", Quantity: 2})
+	restErr, ok := err.(errors.RestError)
+	assert.True(t, ok, "expected a RestError")
+	assert.Equal(t, errors.CodeSoldOut, restErr.Code, "expected the tickets kept for the waitlist to be sold out")
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
+
+func TestCreateHold_LeavesWaitlistedTickets(t *testing.T) {
+	holdService, mock := setupHoldTest(t)
+
+	mock.ExpectBegin()
+
+	// Buyers wait for eight tickets while the pool was full, only the other two can be held.
+	mock.ExpectQuery("SELECT (.+) FROM ticket WHERE id = (.+) FOR UPDATE").
+		WithArgs(1, testOrganizerID).
+		WillReturnRows(newTicketRows(&models.Ticket{ID: 1, Name: "test", Allocation: 10}))
+
+	expectWaitlistDemand(mock, 1, 8)
+
+	mock.ExpectRollback()
+
+	_, err := holdService.CreateHold(testOrganizerID, 1, models.HoldRequest{BuyerID: "buyer", Quantity: 3})
+	restErr, ok := err.(errors.RestError)
+	assert.True(t, ok, "expected a RestError")
+	assert.Equal(t, errors.CodeNotEnoughTickets, restErr.Code)
+	assert.Equal(t, 2, restErr.Details["available"], "expected the waitlisted tickets to be left out")
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
+
+func TestJoinWaitlist_AlreadyWaiting(t *testing.T) {
+	waitlistService, mock := setupWaitlistTest(t)
+
+	mock.ExpectBegin()
+
+	mock.ExpectQuery("SELECT (.+) FROM ticket WHERE id = (.+) FOR UPDATE").
+		WithArgs(1, testOrganizerID).
+		WillReturnRows(newTicketRows(&models.Ticket{ID: 1, Name: "test", Sold: 100}))
+
+	mock.ExpectQuery("SELECT id FROM waitlist_entry").
+		WithArgs(1, "buyer", models.WaitlistStatusWaiting).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(4))
+
+	mock.ExpectRollback()
+
+	_, err := waitlistService.JoinWaitlist(testOrganizerID, 1, models.WaitlistRequest{BuyerID: "buyer", Quantity: 2})
+	assert.Equal(t, errors.NewRestError("Buyer is already on the waitlist of ticket 1", 409), err, "expected conflict error")
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
+
+func TestGetWaitlistEntry_Waiting(t *testing.T) {
+	waitlistService, mock := setupWaitlistTest(t)
+
+	mock.ExpectQuery("SELECT (.+) FROM waitlist_entry w LEFT JOIN ticket_hold h").
+		WithArgs(4, testOrganizerID).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id", "ticket_id", "buyer_id", "quantity", "status", "hold_id", "hold_status", "expires_at", "created_at", "updated_at",
+		}).AddRow(4, 1, "buyer", 2, models.WaitlistStatusWaiting, nil, nil, nil, time.Now(), time.Now()))
+
+	mock.ExpectQuery("SELECT COUNT(.+) FROM waitlist_entry").
+		WithArgs(1, models.WaitlistStatusWaiting, 4).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+
+	entry, err := waitlistService.GetWaitlistEntry(testOrganizerID, 4)
+	assert.NoError(t, err, "failed to get waitlist entry")
+	assert.Equal(t, 2, entry.Position, "expected second position in the queue")
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
+
+func TestGetWaitlistEntry_OfferExpired(t *testing.T) {
+	waitlistService, mock := setupWaitlistTest(t)
+
+	expiresAt := time.Now().Add(-time.Minute)
+	mock.ExpectQuery("SELECT (.+) FROM waitlist_entry w LEFT JOIN ticket_hold h").
+		WithArgs(4, testOrganizerID).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id", "ticket_id", "buyer_id", "quantity", "status", "hold_id", "hold_status", "expires_at", "created_at", "updated_at",
+		}).AddRow(4, 1, "buyer", 2, models.WaitlistStatusOffered, 7, models.HoldStatusExpired, expiresAt, time.Now(), time.Now()))
+
+	entry, err := waitlistService.GetWaitlistEntry(testOrganizerID, 4)
+	assert.NoError(t, err, "failed to get waitlist entry")
+	assert.Equal(t, models.WaitlistStatusExpired, entry.Status, "expected status of the expired hold")
+	assert.Equal(t, 0, entry.Position, "expected no position once offered")
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
+
+func TestLeaveWaitlist_AlreadyOffered(t *testing.T) {
+	waitlistService, mock := setupWaitlistTest(t)
+
+	mock.ExpectQuery("SELECT status FROM waitlist_entry").
+		WithArgs(4, testOrganizerID).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(models.WaitlistStatusOffered))
+
+	err := waitlistService.LeaveWaitlist(testOrganizerID, 4)
+	assert.Equal(t, errors.NewRestError("Waitlist entry is already offered", 400), err, "expected bad request error")
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}
+
+func TestReleaseHold_OffersToWaitlist(t *testing.T) {
+	holdService, mock := setupHoldTest(t)
+
+	hold := &models.Hold{ID: 5, TicketID: 1, BuyerID: "buyer", Quantity: 3, Status: models.HoldStatusActive, ExpiresAt: time.Now().Add(time.Minute)}
+
+	mock.ExpectBegin()
+
+	mock.ExpectQuery("SELECT (.+) FROM ticket_hold WHERE id = (.+) FOR UPDATE").
+		WithArgs(hold.ID, testOrganizerID).
+		WillReturnRows(newHoldRows(hold))
+
+	mock.ExpectQuery("SELECT (.+) FROM ticket WHERE id = (.+) FOR UPDATE").
+		WithArgs(1, testOrganizerID).
+		WillReturnRows(newTicketRows(&models.Ticket{ID: 1, Name: "test", Held: 3, Sold: 97}))
+
+	mock.ExpectExec("UPDATE ticket SET allocation").
+		WithArgs(3, 0, 97, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	// The head of the queue is locked without skipping it, so nobody passes it.
+	mock.ExpectQuery("SELECT id, buyer_id, quantity FROM waitlist_entry (.+) ORDER BY id LIMIT 1 FOR UPDATE$").
+		WithArgs(1, models.WaitlistStatusWaiting, 100).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "buyer_id", "quantity"}).AddRow(4, "waiting-buyer", 2))
+
+	expectNoPricingRule(mock, 1)
+
+	mock.ExpectQuery("INSERT INTO ticket_hold").
+		WithArgs(1, "waiting-buyer", 2, int64(0), "", models.HoldStatusActive, sqlmock.AnyArg(), nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(8, time.Now()))
+
+	mock.ExpectExec("UPDATE waitlist_entry SET status").
+		WithArgs(models.WaitlistStatusOffered, 8, 4).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	// the one ticket left isn't enough for the next buyer in line
+	mock.ExpectQuery("SELECT id, buyer_id, quantity FROM waitlist_entry").
+		WithArgs(1, models.WaitlistStatusWaiting, 100).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "buyer_id", "quantity"}).AddRow(6, "other-buyer", 2))
+
+	mock.ExpectExec("UPDATE ticket SET allocation").
+		WithArgs(1, 2, 97, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	mock.ExpectExec("UPDATE ticket_hold SET status").
+		WithArgs(models.HoldStatusReleased, hold.ID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	mock.ExpectCommit()
+
+	err := holdService.ReleaseHold(testOrganizerID, hold.ID)
+	assert.NoError(t, err, "failed to release hold")
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err, "unexpected error")
+}